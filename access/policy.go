@@ -0,0 +1,211 @@
+// Package access checks which browser users may call which P2P methods.
+package access
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	pb "github.com/scrape-vm/proto"
+)
+
+// Role is the access level of a user; each role includes the lower ones
+type Role int
+
+const (
+	RoleNone     Role = iota // no access
+	RoleViewer               // list jobs and read downloaded files
+	RoleOperator             // run and cancel scrapes
+	RoleAdmin                // update and configuration
+)
+
+func (r Role) String() string {
+	switch r {
+	case RoleViewer:
+		return "viewer"
+	case RoleOperator:
+		return "operator"
+	case RoleAdmin:
+		return "admin"
+	default:
+		return "none"
+	}
+}
+
+// ParseRole parses a role name ("none", "viewer", "operator" or "admin")
+func ParseRole(s string) (Role, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "none":
+		return RoleNone, nil
+	case "viewer":
+		return RoleViewer, nil
+	case "operator":
+		return RoleOperator, nil
+	case "admin":
+		return RoleAdmin, nil
+	}
+	return RoleNone, fmt.Errorf("unknown role %q", s)
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (r Role) MarshalText() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (r *Role) UnmarshalText(text []byte) error {
+	role, err := ParseRole(string(text))
+	if err != nil {
+		return err
+	}
+	*r = role
+	return nil
+}
+
+// Methods of the file transfer channel, checked like the gRPC methods
+const (
+	MethodFileList = "files/list"
+	MethodFileGet  = "files/get"
+)
+
+// defaultMethods is the role required per method; methods not listed need RoleAdmin
+var defaultMethods = map[string]Role{
+	pb.ETCScraper_Health_FullMethodName:             RoleViewer,
+	pb.ETCScraper_GetDownloadedFiles_FullMethodName: RoleViewer,
+	pb.ETCScraper_GetJob_FullMethodName:             RoleViewer,
+	pb.ETCScraper_ListJobs_FullMethodName:           RoleViewer,
+	pb.ETCScraper_GetArtifacts_FullMethodName:       RoleViewer,
+	pb.ETCScraper_ListSchedules_FullMethodName:      RoleViewer,
+	pb.ETCScraper_GetNewRecords_FullMethodName:      RoleViewer,
+	pb.ETCScraper_QueryRecords_FullMethodName:       RoleViewer,
+	pb.ETCScraper_ListRuns_FullMethodName:           RoleViewer,
+	pb.ETCScraper_ListSessions_FullMethodName:       RoleViewer,
+	pb.ETCScraper_GetSession_FullMethodName:         RoleViewer,
+	pb.ETCScraper_GetFile_FullMethodName:            RoleViewer,
+
+	MethodFileList: RoleViewer,
+	MethodFileGet:  RoleViewer,
+
+	pb.ETCScraper_Scrape_FullMethodName:         RoleOperator,
+	pb.ETCScraper_ScrapeMultiple_FullMethodName: RoleOperator,
+	pb.ETCScraper_ScrapeStream_FullMethodName:   RoleOperator,
+	pb.ETCScraper_CancelJob_FullMethodName:      RoleOperator,
+}
+
+// ErrPermissionDenied is returned when a user's role does not allow a method
+var ErrPermissionDenied = errors.New("permission denied")
+
+// User identifies a browser user, as reported by the signaling server
+type User struct {
+	ID    string
+	Email string
+}
+
+func (u User) String() string {
+	switch {
+	case u.ID == "" && u.Email == "":
+		return "anonymous"
+	case u.Email == "":
+		return u.ID
+	default:
+		return u.ID + " <" + u.Email + ">"
+	}
+}
+
+// Policy maps users to roles and methods to the role they require.
+// A nil *Policy allows everything (access control disabled).
+type Policy struct {
+	// DefaultRole applies to users not listed in Users, including users the
+	// signaling server did not identify
+	DefaultRole Role `json:"defaultRole"`
+	// Users maps a user ID or email address (case-insensitive) to a role
+	Users map[string]Role `json:"users"`
+	// Methods overrides the role required by a method ("/scraper.ETCScraper/Scrape", "files/get", ...)
+	Methods map[string]Role `json:"methods,omitempty"`
+}
+
+// AllowAll is the policy file name that turns access control off: every
+// browser user may call every method. It has to be given explicitly.
+const AllowAll = "allow-all"
+
+// Default is the policy without a policy file: every user is a viewer
+func Default() *Policy {
+	return &Policy{DefaultRole: RoleViewer}
+}
+
+// Load reads a policy file:
+//
+//	{
+//	  "defaultRole": "none",
+//	  "users": {"alice@example.com": "admin", "user-123": "operator"},
+//	  "methods": {"/scraper.ETCScraper/GetDownloadedFiles": "operator"}
+//	}
+func Load(path string) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read access policy: %w", err)
+	}
+	var p Policy
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("invalid access policy %s: %w", path, err)
+	}
+
+	// メールアドレスは大文字小文字を区別しない
+	users := make(map[string]Role, len(p.Users))
+	for key, role := range p.Users {
+		users[strings.ToLower(strings.TrimSpace(key))] = role
+	}
+	p.Users = users
+	return &p, nil
+}
+
+// Role returns the role of a user: the higher of the roles listed for its ID
+// and email, or DefaultRole if neither is listed
+func (p *Policy) Role(u User) Role {
+	if p == nil {
+		return RoleAdmin
+	}
+	role, listed := RoleNone, false
+	for _, key := range []string{u.ID, u.Email} {
+		if key == "" {
+			continue
+		}
+		if r, ok := p.Users[strings.ToLower(key)]; ok {
+			listed = true
+			if r > role {
+				role = r
+			}
+		}
+	}
+	if !listed {
+		return p.DefaultRole
+	}
+	return role
+}
+
+// Required returns the role required to call method
+func (p *Policy) Required(method string) Role {
+	if p != nil {
+		if r, ok := p.Methods[method]; ok {
+			return r
+		}
+	}
+	if r, ok := defaultMethods[method]; ok {
+		return r
+	}
+	return RoleAdmin
+}
+
+// Authorize returns ErrPermissionDenied unless the user may call method
+func (p *Policy) Authorize(u User, method string) error {
+	if p == nil {
+		return nil
+	}
+	role, required := p.Role(u), p.Required(method)
+	if role < required || role == RoleNone {
+		return fmt.Errorf("%w: %s is %s, %s requires %s", ErrPermissionDenied, u, role, method, required)
+	}
+	return nil
+}
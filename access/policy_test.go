@@ -0,0 +1,82 @@
+package access
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	pb "github.com/scrape-vm/proto"
+)
+
+func TestAuthorize(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.json")
+	data := `{
+		"defaultRole": "viewer",
+		"users": {"Alice@Example.com": "admin", "op-1": "operator", "blocked": "none"},
+		"methods": {"/scraper.ETCScraper/GetDownloadedFiles": "operator"}
+	}`
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	policy, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		user   User
+		method string
+		ok     bool
+	}{
+		{User{ID: "x", Email: "alice@example.com"}, "/scraper.ETCScraper/SomeFutureConfig", true},
+		{User{ID: "op-1"}, pb.ETCScraper_ScrapeMultiple_FullMethodName, true},
+		{User{ID: "op-1"}, "/scraper.ETCScraper/SomeFutureConfig", false},
+		{User{ID: "someone"}, pb.ETCScraper_ListJobs_FullMethodName, true},
+		{User{ID: "someone"}, MethodFileGet, true},
+		{User{ID: "someone"}, pb.ETCScraper_Scrape_FullMethodName, false},
+		{User{ID: "someone"}, pb.ETCScraper_GetDownloadedFiles_FullMethodName, false},
+		{User{}, pb.ETCScraper_Health_FullMethodName, true},
+		{User{ID: "blocked"}, pb.ETCScraper_Health_FullMethodName, false},
+	}
+	for _, tt := range tests {
+		err := policy.Authorize(tt.user, tt.method)
+		if tt.ok && err != nil {
+			t.Errorf("Authorize(%s, %s): %v", tt.user, tt.method, err)
+		}
+		if !tt.ok && !errors.Is(err, ErrPermissionDenied) {
+			t.Errorf("Authorize(%s, %s) = %v, want ErrPermissionDenied", tt.user, tt.method, err)
+		}
+	}
+
+	var disabled *Policy
+	if err := disabled.Authorize(User{}, pb.ETCScraper_Scrape_FullMethodName); err != nil {
+		t.Errorf("nil policy: %v", err)
+	}
+}
+
+func TestLoadInvalidRole(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.json")
+	if err := os.WriteFile(path, []byte(`{"users": {"a": "root"}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil {
+		t.Error("Load with an unknown role succeeded")
+	}
+}
+
+func TestDefaultIsReadOnly(t *testing.T) {
+	policy := Default()
+	for method, ok := range map[string]bool{
+		pb.ETCScraper_Health_FullMethodName:         true,
+		pb.ETCScraper_ListJobs_FullMethodName:       true,
+		MethodFileGet:                               true,
+		pb.ETCScraper_ScrapeMultiple_FullMethodName: false,
+		pb.ETCScraper_CancelJob_FullMethodName:      false,
+		"/scraper.ETCScraper/SomeFutureConfig":      false,
+	} {
+		if err := policy.Authorize(User{ID: "someone"}, method); (err == nil) != ok {
+			t.Errorf("Authorize(%s) = %v, want allowed %v", method, err, ok)
+		}
+	}
+}
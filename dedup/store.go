@@ -0,0 +1,251 @@
+// Package dedup remembers which usage records of each account have already
+// been downloaded. Every session folder holds the full overlapping period, so
+// the same toll transactions appear in many CSV files; the store fingerprints
+// each row and reports only the ones not seen in an earlier run.
+package dedup
+
+import (
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/scrape-vm/parser"
+)
+
+// StoreFile is the name of the record store inside the download directory
+const StoreFile = "records.json"
+
+// account is the stored state of one account
+type account struct {
+	LastJobID string            `json:"lastJobId"`
+	LastRun   time.Time         `json:"lastRun"`
+	Seen      map[string]string `json:"seen"` // fingerprint -> job that first downloaded it
+}
+
+// Batch is the new records of one account in one run
+type Batch struct {
+	UserID   string
+	JobID    string
+	FilePath string
+	Records  []parser.UsageRecord
+}
+
+// Store tracks the fingerprints of downloaded records per account and
+// persists them to a JSON file
+type Store struct {
+	path     string
+	logger   *log.Logger
+	mu       sync.Mutex
+	accounts map[string]*account
+}
+
+// NewStore creates a record store persisting to path (empty for in-memory only)
+func NewStore(path string, logger *log.Logger) *Store {
+	if logger == nil {
+		logger = log.Default()
+	}
+	s := &Store{
+		path:     path,
+		logger:   logger,
+		accounts: make(map[string]*account),
+	}
+	if err := s.load(); err != nil {
+		logger.Printf("Warning: could not load records from %s: %v", path, err)
+	}
+	return s
+}
+
+// Add records the rows downloaded for the account by a job and returns the
+// ones not seen before. The job becomes the account's last run.
+func (s *Store) Add(userID, jobID string, records []parser.UsageRecord) []parser.UsageRecord {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	acc, ok := s.accounts[userID]
+	if !ok {
+		acc = &account{Seen: make(map[string]string)}
+		s.accounts[userID] = acc
+	}
+
+	var fresh []parser.UsageRecord
+	for i, fp := range Fingerprints(records) {
+		if _, seen := acc.Seen[fp]; seen {
+			continue
+		}
+		acc.Seen[fp] = jobID
+		fresh = append(fresh, records[i])
+	}
+	acc.LastJobID = jobID
+	acc.LastRun = time.Now()
+	s.saveLocked()
+	return fresh
+}
+
+// Filter returns the records that the job was the first to download for the
+// account, e.g. after parsing the file of an earlier run again
+func (s *Store) Filter(userID, jobID string, records []parser.UsageRecord) []parser.UsageRecord {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	acc, ok := s.accounts[userID]
+	if !ok {
+		return nil
+	}
+	var fresh []parser.UsageRecord
+	for i, fp := range Fingerprints(records) {
+		if acc.Seen[fp] == jobID {
+			fresh = append(fresh, records[i])
+		}
+	}
+	return fresh
+}
+
+// LastJob returns the job of the account's last run, or "" if it never ran
+func (s *Store) LastJob(userID string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if acc, ok := s.accounts[userID]; ok {
+		return acc.LastJobID
+	}
+	return ""
+}
+
+// Accounts returns the user IDs with stored records, sorted
+func (s *Store) Accounts() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ids := make([]string, 0, len(s.accounts))
+	for id := range s.accounts {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// Fingerprint identifies a toll transaction across downloads: the card, the
+// entry and exit, and the toll. The remark and the discount columns are left
+// out so a row that is annotated later is not reported twice; a correction
+// row with a different toll is reported as new.
+func Fingerprint(r parser.UsageRecord) string {
+	const layout = "2006-01-02 15:04:05"
+	entry := ""
+	if !r.EntryTime.IsZero() {
+		entry = r.EntryTime.Format(layout)
+	}
+	key := r.CardNumber + "\x00" + entry + "\x00" + r.EntryIC + "\x00" +
+		r.ExitTime.Format(layout) + "\x00" + r.ExitIC + "\x00" + strconv.Itoa(r.Toll)
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:16])
+}
+
+// Fingerprints returns the fingerprint of each record of one download.
+// Identical rows are numbered so that each of them is counted once.
+func Fingerprints(records []parser.UsageRecord) []string {
+	counts := make(map[string]int)
+	result := make([]string, len(records))
+	for i, r := range records {
+		fp := Fingerprint(r)
+		if n := counts[fp]; n > 0 {
+			result[i] = fmt.Sprintf("%s#%d", fp, n)
+		} else {
+			result[i] = fp
+		}
+		counts[fp]++
+	}
+	return result
+}
+
+// csvHeader is the header of WriteCSV, the meisai columns after the account
+var csvHeader = []string{
+	"アカウント", "利用年月日(自)", "時刻(自)", "利用年月日(至)", "時刻(至)", "利用IC(自)", "利用IC(至)",
+	"割引前料金", "ETC割引額", "通行料金", "車種", "車両番号", "ETCカード番号", "備考",
+}
+
+// WriteCSV writes the records of the batches as one UTF-8 CSV with the account
+// in the first column. parser.ParseMeisai reads it back.
+func WriteCSV(w io.Writer, batches []Batch) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+	for _, b := range batches {
+		for _, r := range b.Records {
+			var entryDate, entryTime string
+			if !r.EntryTime.IsZero() {
+				entryDate, entryTime = r.EntryTime.Format("2006/01/02"), r.EntryTime.Format("15:04")
+			}
+			row := []string{
+				b.UserID, entryDate, entryTime, r.ExitTime.Format("2006/01/02"), r.ExitTime.Format("15:04"),
+				r.EntryIC, r.ExitIC, strconv.Itoa(r.OriginalToll), strconv.Itoa(r.Discount), strconv.Itoa(r.Toll),
+				r.VehicleClass, r.VehicleNumber, r.CardNumber, r.Note,
+			}
+			if err := cw.Write(row); err != nil {
+				return err
+			}
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// load reads the persisted store
+func (s *Store) load() error {
+	if s.path == "" {
+		return nil
+	}
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	if err := json.Unmarshal(data, &s.accounts); err != nil {
+		return err
+	}
+	for _, acc := range s.accounts {
+		if acc.Seen == nil {
+			acc.Seen = make(map[string]string)
+		}
+	}
+	return nil
+}
+
+// saveLocked writes the store file; s.mu must be held
+func (s *Store) saveLocked() {
+	if s.path == "" {
+		return
+	}
+
+	data, err := json.Marshal(s.accounts)
+	if err != nil {
+		s.logger.Printf("Warning: could not encode records: %v", err)
+		return
+	}
+
+	// 書き込み途中でのクラッシュに備えて一時ファイル経由で置き換える
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		s.logger.Printf("Warning: could not save records: %v", err)
+		return
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		s.logger.Printf("Warning: could not save records: %v", err)
+		return
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		s.logger.Printf("Warning: could not save records: %v", err)
+	}
+}
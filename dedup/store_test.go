@@ -0,0 +1,78 @@
+package dedup
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/scrape-vm/parser"
+)
+
+func record(day, toll int) parser.UsageRecord {
+	return parser.UsageRecord{
+		EntryTime:  time.Date(2025, 1, day, 8, 0, 0, 0, time.Local),
+		ExitTime:   time.Date(2025, 1, day, 9, 30, 0, 0, time.Local),
+		EntryIC:    "東京",
+		ExitIC:     "横浜",
+		CardNumber: "1234****5678",
+		Toll:       toll,
+	}
+}
+
+func TestStoreAdd(t *testing.T) {
+	path := filepath.Join(t.TempDir(), StoreFile)
+	s := NewStore(path, nil)
+
+	first := []parser.UsageRecord{record(1, 1000), record(2, 1000), record(2, 1000)}
+	if fresh := s.Add("user1", "job1", first); len(fresh) != 3 {
+		t.Fatalf("first run: %d new records, want 3", len(fresh))
+	}
+
+	// 期間が重なる2回目: 1日分と、同じ行の2件目は取得済み
+	second := []parser.UsageRecord{record(2, 1000), record(2, 1000), record(3, 1000), record(3, -1000)}
+	annotated := record(1, 1000)
+	annotated.Note = "後日追記"
+	second = append(second, annotated)
+
+	// 再起動後も取得済みの明細を覚えている
+	s = NewStore(path, nil)
+	fresh := s.Add("user1", "job2", second)
+	if len(fresh) != 2 || fresh[0].ExitTime.Day() != 3 || fresh[1].Toll != -1000 {
+		t.Fatalf("second run: new records %+v, want the two rows of day 3", fresh)
+	}
+	if got := s.LastJob("user1"); got != "job2" {
+		t.Errorf("LastJob = %q, want job2", got)
+	}
+	if got := s.Filter("user1", "job1", second); len(got) != 3 {
+		t.Errorf("Filter(job1) = %d records, want 3", len(got))
+	}
+
+	// アカウントごとに別管理
+	if fresh := s.Add("user2", "job2", first); len(fresh) != 3 {
+		t.Errorf("other account: %d new records, want 3", len(fresh))
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	records := []parser.UsageRecord{record(1, 1000), record(2, 1500)}
+	records[1].EntryTime = time.Time{}
+	records[1].EntryIC = ""
+
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, []Batch{{UserID: "user1", Records: records}}); err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := parser.ParseMeisai(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(parsed) != 2 {
+		t.Fatalf("parsed %d records, want 2", len(parsed))
+	}
+	for i := range records {
+		if Fingerprint(parsed[i]) != Fingerprint(records[i]) {
+			t.Errorf("record %d changed: %+v, want %+v", i, parsed[i], records[i])
+		}
+	}
+}
@@ -0,0 +1,303 @@
+// Package engine runs scrape requests: it validates the accounts, creates the
+// session folder and the job, runs each account through the registered scraper
+// and stores the downloaded file as <UserID>_<name> in UTF-8. The CLI, the gRPC
+// servers and the P2P transports all submit their requests here.
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/scrape-vm/dedup"
+	"github.com/scrape-vm/jobs"
+	"github.com/scrape-vm/parser"
+	"github.com/scrape-vm/scrapers"
+	"github.com/scrape-vm/vault"
+)
+
+// sessionLayout is the name of the session folders
+const sessionLayout = "20060102_150405"
+
+// maxSessionTries is how many seconds after the start of a job are tried for a
+// free session folder name
+const maxSessionTries = 60
+
+// ErrInvalidRequest is wrapped by errors for requests rejected before a job is created
+var ErrInvalidRequest = errors.New("invalid request")
+
+// Account is an account to scrape, as received from a transport
+type Account struct {
+	ScraperType string `json:"scraperType,omitempty"` // default "etc"
+	UserID      string `json:"userId"`
+	Password    string `json:"password"`
+	FromDate    string `json:"fromDate,omitempty"` // YYYY-MM-DD
+	ToDate      string `json:"toDate,omitempty"`   // YYYY-MM-DD
+	LastMonths  int    `json:"lastMonths,omitempty"`
+	AccountRef  string `json:"accountRef,omitempty"` // vault account ID, replaces UserID and Password
+}
+
+// Result is the outcome of one account of a run
+type Result struct {
+	Index    int
+	UserID   string
+	FilePath string // downloaded file (UTF-8), empty on failure
+	Err      error
+}
+
+// Hooks are optional callbacks invoked while a run progresses. They may be
+// called concurrently for different accounts.
+type Hooks struct {
+	// OnProgress is called as the scraper of the account at index moves through its stages
+	OnProgress func(index int, ev scrapers.ProgressEvent)
+	// OnAccountFinished is called when an account succeeded or failed; ctx is the
+	// context the account ran with, so ctx.Err() tells whether it was cancelled
+	OnAccountFinished func(ctx context.Context, r Result)
+}
+
+// Engine runs scrape requests as jobs
+type Engine struct {
+	Jobs         *jobs.Manager
+	Pool         *scrapers.BrowserPool // shared browsers; nil starts a Chrome per account
+	Vault        *vault.Vault          // resolves Account.AccountRef; nil rejects references
+	Records      *dedup.Store          // records the downloaded rows to report new ones; may be nil
+	DownloadPath string                // session folders are created here
+	Headless     bool
+	KeepOriginal bool          // keep the original Shift_JIS file next to the UTF-8 copy
+	Timeout      time.Duration // per phase, scrapers.DefaultTimeout if zero
+	Logger       *log.Logger
+}
+
+// Start validates the accounts and runs them as a job in the background.
+// The returned job is queued; follow it with Jobs.Get.
+func (e *Engine) Start(accounts []Account, hooks Hooks) (*jobs.Job, error) {
+	job, run, err := e.Prepare(accounts, hooks)
+	if err != nil {
+		return nil, err
+	}
+	e.Jobs.Start(job.ID, run)
+	return job, nil
+}
+
+// Run validates the accounts, runs them as a job and returns the finished job.
+// Cancelling ctx cancels the job.
+func (e *Engine) Run(ctx context.Context, accounts []Account, hooks Hooks) (*jobs.Job, error) {
+	job, run, err := e.Prepare(accounts, hooks)
+	if err != nil {
+		return nil, err
+	}
+	return e.Jobs.Run(ctx, job.ID, run), nil
+}
+
+// Prepare checks the request, creates the session folder and registers a queued
+// job, returning the function that runs its accounts with Jobs.Run or Jobs.Start.
+// Use it instead of Start or Run when the job ID is needed before the first hook.
+func (e *Engine) Prepare(accounts []Account, hooks Hooks) (*jobs.Job, jobs.RunFunc, error) {
+	if len(accounts) == 0 {
+		return nil, nil, fmt.Errorf("%w: no accounts", ErrInvalidRequest)
+	}
+	configs := make([]*scrapers.ScraperConfig, len(accounts))
+	userIDs := make([]string, len(accounts))
+	seen := make(map[string]bool)
+	for i, acc := range accounts {
+		acc, err := e.resolve(acc)
+		if err != nil {
+			return nil, nil, err
+		}
+		// ユーザーIDは作業フォルダ・CSVのファイル名になる
+		if err := checkUserID(acc.UserID); err != nil {
+			return nil, nil, fmt.Errorf("%w: %v", ErrInvalidRequest, err)
+		}
+		if seen[acc.UserID] {
+			return nil, nil, fmt.Errorf("%w: account %s is listed twice", ErrInvalidRequest, acc.UserID)
+		}
+		seen[acc.UserID] = true
+		config, err := e.config(acc)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: account %s: %v", ErrInvalidRequest, acc.UserID, err)
+		}
+		configs[i] = config
+		userIDs[i] = acc.UserID
+	}
+
+	sessionFolder, err := e.createSessionFolder()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create session folder: %w", err)
+	}
+	e.logger().Printf("Session folder: %s", sessionFolder)
+
+	job := e.Jobs.Create(userIDs, sessionFolder)
+
+	run := func(ctx context.Context, i int) (string, error) {
+		config := configs[i]
+		config.DownloadPath = sessionFolder
+		if hooks.OnProgress != nil {
+			config.OnProgress = func(ev scrapers.ProgressEvent) { hooks.OnProgress(i, ev) }
+		}
+
+		e.logger().Printf("=== Processing account %d/%d: %s ===", i+1, len(accounts), config.UserID)
+		path, err := e.ProcessAccount(ctx, config)
+		if err != nil {
+			e.logger().Printf("ERROR: Account %s failed: %v", config.UserID, err)
+		} else {
+			e.logger().Printf("SUCCESS: Account %s -> %s", config.UserID, path)
+			e.addRecords(config.UserID, job.ID, path)
+		}
+
+		if hooks.OnAccountFinished != nil {
+			hooks.OnAccountFinished(ctx, Result{Index: i, UserID: config.UserID, FilePath: path, Err: err})
+		}
+		return path, err
+	}
+	return job, run, nil
+}
+
+// createSessionFolder creates a new session folder named after the current
+// time. A job started in the same second as another gets the next free second,
+// so that jobs never share a folder and the name stays YYYYMMDD_HHMMSS.
+func (e *Engine) createSessionFolder() (string, error) {
+	if err := os.MkdirAll(e.DownloadPath, 0755); err != nil {
+		return "", err
+	}
+	now := time.Now()
+	for i := 0; i < maxSessionTries; i++ {
+		dir := filepath.Join(e.DownloadPath, now.Add(time.Duration(i)*time.Second).Format(sessionLayout))
+		err := os.Mkdir(dir, 0755)
+		if err == nil {
+			return dir, nil
+		}
+		if !errors.Is(err, fs.ErrExist) {
+			return "", err
+		}
+	}
+	return "", fmt.Errorf("no free session folder name after %s", now.Format(sessionLayout))
+}
+
+// checkUserID rejects user IDs that cannot be used in a file name
+func checkUserID(id string) error {
+	if id == "" {
+		return errors.New("missing user ID")
+	}
+	if strings.HasPrefix(id, ".") || strings.ContainsAny(id, `/\:*?"<>|`) || strings.IndexFunc(id, unicode.IsControl) >= 0 {
+		return fmt.Errorf("user ID %q contains characters not allowed in file names", id)
+	}
+	return nil
+}
+
+// addRecords adds the rows of a downloaded file to the record store
+func (e *Engine) addRecords(userID, jobID, path string) {
+	if e.Records == nil {
+		return
+	}
+	records, err := parser.ParseMeisaiFile(path)
+	if err != nil {
+		e.logger().Printf("Warning: could not check %s for new records: %v", filepath.Base(path), err)
+		return
+	}
+	fresh := e.Records.Add(userID, jobID, records)
+	e.logger().Printf("Account %s: %d new of %d records", userID, len(fresh), len(records))
+}
+
+// resolve fills in the user ID and password of an account given by AccountRef
+// from the vault. The scraper type of the request wins over the stored one.
+func (e *Engine) resolve(acc Account) (Account, error) {
+	if acc.AccountRef == "" {
+		return acc, nil
+	}
+	if e.Vault == nil {
+		return acc, fmt.Errorf("%w: account_ref %s: no account vault configured", ErrInvalidRequest, acc.AccountRef)
+	}
+	entry, err := e.Vault.Get(acc.AccountRef)
+	if errors.Is(err, vault.ErrNotFound) {
+		return acc, fmt.Errorf("%w: %v", ErrInvalidRequest, err)
+	}
+	if err != nil {
+		return acc, fmt.Errorf("account_ref %s: %w", acc.AccountRef, err)
+	}
+
+	acc.UserID, acc.Password = entry.UserID, entry.Password
+	if acc.ScraperType == "" {
+		acc.ScraperType = entry.ScraperType
+	}
+	return acc, nil
+}
+
+// config builds the scraper config of an account, without the download path
+func (e *Engine) config(acc Account) (*scrapers.ScraperConfig, error) {
+	if _, err := scrapers.Lookup(acc.ScraperType); err != nil {
+		return nil, err
+	}
+	config := &scrapers.ScraperConfig{
+		ScraperType:  acc.ScraperType,
+		UserID:       acc.UserID,
+		Password:     acc.Password,
+		Headless:     e.Headless,
+		Timeout:      e.Timeout,
+		KeepOriginal: e.KeepOriginal,
+		Pool:         e.Pool,
+	}
+	if err := config.SetPeriod(acc.FromDate, acc.ToDate, acc.LastMonths); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// ProcessAccount scrapes one account with the scraper selected by
+// config.ScraperType, prefixes the downloaded file with the user ID and
+// converts it to UTF-8. It returns the final file path.
+func (e *Engine) ProcessAccount(ctx context.Context, config *scrapers.ScraperConfig) (string, error) {
+	logger := e.logger()
+
+	scraper, err := scrapers.NewScraper(config, logger)
+	if err != nil {
+		return "", fmt.Errorf("failed to create scraper: %w", err)
+	}
+	defer scraper.Close()
+
+	if err := scraper.Initialize(ctx); err != nil {
+		return "", fmt.Errorf("failed to initialize: %w", err)
+	}
+
+	if err := scraper.Login(ctx); err != nil {
+		return "", fmt.Errorf("failed to login: %w", err)
+	}
+
+	csvPath, err := scraper.Download(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to download: %w", err)
+	}
+
+	// ファイル名にアカウント名を付与
+	newPath := filepath.Join(config.DownloadPath, config.UserID+"_"+filepath.Base(csvPath))
+	if csvPath != newPath {
+		if err := os.Rename(csvPath, newPath); err != nil {
+			logger.Printf("Warning: could not rename file: %v", err)
+		} else {
+			csvPath = newPath
+		}
+	}
+
+	// Shift_JIS → UTF-8 に変換
+	enc, err := parser.NormalizeFile(csvPath, config.KeepOriginal)
+	if err != nil {
+		logger.Printf("Warning: could not convert %s to UTF-8: %v", filepath.Base(csvPath), err)
+	} else if enc != parser.EncodingUTF8 {
+		logger.Printf("Converted %s from %s to UTF-8", filepath.Base(csvPath), enc)
+	}
+
+	return csvPath, nil
+}
+
+func (e *Engine) logger() *log.Logger {
+	if e.Logger == nil {
+		return log.Default()
+	}
+	return e.Logger
+}
@@ -0,0 +1,65 @@
+package engine
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/scrape-vm/jobs"
+)
+
+func newTestEngine(t *testing.T) *Engine {
+	t.Helper()
+	dir := t.TempDir()
+	return &Engine{
+		Jobs:         jobs.NewManager(filepath.Join(dir, jobs.StoreFile), nil),
+		DownloadPath: filepath.Join(dir, "downloads"),
+	}
+}
+
+func TestPrepareSessionFolders(t *testing.T) {
+	e := newTestEngine(t)
+
+	// 同じ秒に作成されたジョブもフォルダを共有しない
+	seen := make(map[string]bool)
+	for i := 0; i < 3; i++ {
+		job, _, err := e.Prepare([]Account{{UserID: "user1", Password: "pass"}}, Hooks{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		name := filepath.Base(job.SessionFolder)
+		if seen[name] || len(name) != len(sessionLayout) {
+			t.Errorf("job %d: session folder %s, already used: %v", i, name, seen[name])
+		}
+		seen[name] = true
+		if info, err := os.Stat(job.SessionFolder); err != nil || !info.IsDir() {
+			t.Errorf("session folder %s not created: %v", name, err)
+		}
+	}
+}
+
+func TestPrepareRejectsUserIDs(t *testing.T) {
+	e := newTestEngine(t)
+
+	for _, accounts := range [][]Account{
+		{{UserID: ""}},
+		{{UserID: "../user1"}},
+		{{UserID: "user/1"}},
+		{{UserID: `user\1`}},
+		{{UserID: "C:user1"}},
+		{{UserID: ".user1"}},
+		{{UserID: "user\n1"}},
+		{{UserID: "user1"}, {UserID: "user2"}, {UserID: "user1"}},
+	} {
+		if _, _, err := e.Prepare(accounts, Hooks{}); !errors.Is(err, ErrInvalidRequest) {
+			t.Errorf("Prepare(%q): %v, want ErrInvalidRequest", accounts[len(accounts)-1].UserID, err)
+		}
+	}
+	if entries, _ := os.ReadDir(e.DownloadPath); len(entries) != 0 {
+		t.Errorf("rejected requests created %d session folders", len(entries))
+	}
+	if list := e.Jobs.List(0); len(list) != 0 {
+		t.Errorf("rejected requests created %d jobs", len(list))
+	}
+}
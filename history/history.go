@@ -0,0 +1,426 @@
+// Package history keeps an embedded SQLite database of every finished run:
+// the job, the outcome of each account, the downloaded file and its parsed
+// usage rows. Unlike the session folders it can be queried by account, date,
+// card, vehicle and interchange.
+package history
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/scrape-vm/dedup"
+	"github.com/scrape-vm/jobs"
+	"github.com/scrape-vm/parser"
+
+	_ "modernc.org/sqlite" // pure-Go driver "sqlite"
+)
+
+// DBFile is the name of the database inside the download directory
+const DBFile = "history.db"
+
+// DefaultLimit is the number of rows returned by a query without a limit
+const DefaultLimit = 1000
+
+// ErrInvalidQuery is wrapped by errors for malformed query filters
+var ErrInvalidQuery = errors.New("invalid query")
+
+const (
+	timeLayout   = "2006-01-02T15:04:05.000Z07:00" // job times, sortable as text
+	recordLayout = "2006-01-02 15:04:05"           // usage times, local as in the CSV
+	dateLayout   = "2006-01-02"
+)
+
+const schema = `
+CREATE TABLE IF NOT EXISTS runs (
+	job_id         TEXT PRIMARY KEY,
+	state          TEXT NOT NULL,
+	session_folder TEXT NOT NULL,
+	created_at     TEXT NOT NULL,
+	started_at     TEXT NOT NULL DEFAULT '',
+	finished_at    TEXT NOT NULL DEFAULT ''
+);
+CREATE INDEX IF NOT EXISTS runs_created ON runs(created_at);
+
+CREATE TABLE IF NOT EXISTS run_accounts (
+	job_id      TEXT NOT NULL,
+	idx         INTEGER NOT NULL,
+	user_id     TEXT NOT NULL,
+	state       TEXT NOT NULL,
+	error       TEXT NOT NULL DEFAULT '',
+	error_kind  TEXT NOT NULL DEFAULT '',
+	artifacts   TEXT NOT NULL DEFAULT '',
+	file_path   TEXT NOT NULL DEFAULT '',
+	file_size   INTEGER NOT NULL DEFAULT 0,
+	started_at  TEXT NOT NULL DEFAULT '',
+	finished_at TEXT NOT NULL DEFAULT '',
+	PRIMARY KEY (job_id, idx)
+);
+CREATE INDEX IF NOT EXISTS run_accounts_user ON run_accounts(user_id);
+
+CREATE TABLE IF NOT EXISTS records (
+	id             INTEGER PRIMARY KEY,
+	job_id         TEXT NOT NULL,
+	user_id        TEXT NOT NULL,
+	file_path      TEXT NOT NULL,
+	fingerprint    TEXT NOT NULL,
+	entry_time     TEXT NOT NULL DEFAULT '',
+	exit_time      TEXT NOT NULL,
+	entry_ic       TEXT NOT NULL DEFAULT '',
+	exit_ic        TEXT NOT NULL DEFAULT '',
+	vehicle_class  TEXT NOT NULL DEFAULT '',
+	card_number    TEXT NOT NULL DEFAULT '',
+	original_toll  INTEGER NOT NULL DEFAULT 0,
+	discount       INTEGER NOT NULL DEFAULT 0,
+	toll           INTEGER NOT NULL DEFAULT 0,
+	vehicle_number TEXT NOT NULL DEFAULT '',
+	note           TEXT NOT NULL DEFAULT ''
+);
+CREATE INDEX IF NOT EXISTS records_user_exit ON records(user_id, exit_time);
+CREATE INDEX IF NOT EXISTS records_job ON records(job_id);
+CREATE INDEX IF NOT EXISTS records_fingerprint ON records(user_id, fingerprint);
+`
+
+// DB is the history database
+type DB struct {
+	db     *sql.DB
+	logger *log.Logger
+}
+
+// Record is a stored usage row with the run that downloaded it
+type Record struct {
+	parser.UsageRecord
+	UserID   string
+	JobID    string
+	FilePath string
+}
+
+// RecordQuery filters QueryRecords. Empty fields match everything.
+type RecordQuery struct {
+	UserID        string
+	FromDate      string // exit date, YYYY-MM-DD, inclusive
+	ToDate        string // exit date, YYYY-MM-DD, inclusive
+	CardNumber    string // end of the card number, e.g. the last four digits
+	VehicleNumber string // part of the vehicle number
+	Interchange   string // part of the entry or exit interchange
+	AllRuns       bool   // return a row once per run that downloaded it, not once overall
+	Limit         int    // DefaultLimit if zero
+	Offset        int
+}
+
+// RunQuery filters Runs. Empty fields match everything.
+type RunQuery struct {
+	UserID   string // runs that include the account
+	FromDate string // creation date, YYYY-MM-DD, inclusive
+	ToDate   string // creation date, YYYY-MM-DD, inclusive
+	Limit    int    // DefaultLimit if zero
+}
+
+// Open opens or creates the database at path
+func Open(path string, logger *log.Logger) (*DB, error) {
+	if logger == nil {
+		logger = log.Default()
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	db, err := sql.Open("sqlite", "file:"+filepath.ToSlash(path)+"?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)")
+	if err != nil {
+		return nil, err
+	}
+	// 書き込みは1接続に直列化する
+	db.SetMaxOpenConns(1)
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create schema: %w", err)
+	}
+	return &DB{db: db, logger: logger}, nil
+}
+
+// Close closes the database
+func (h *DB) Close() error {
+	return h.db.Close()
+}
+
+// Record stores a finished job, logging failures. It is meant for
+// jobs.Manager.OnFinished.
+func (h *DB) Record(job *jobs.Job) {
+	if err := h.AddJob(job); err != nil {
+		h.logger.Printf("Warning: could not record job %s in history: %v", job.ID, err)
+	}
+}
+
+// AddJob stores the job, its accounts and the rows of their downloaded files,
+// replacing an earlier copy of the same job
+func (h *DB) AddJob(job *jobs.Job) error {
+	tx, err := h.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, table := range []string{"runs", "run_accounts", "records"} {
+		if _, err := tx.Exec("DELETE FROM "+table+" WHERE job_id = ?", job.ID); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.Exec(`INSERT INTO runs (job_id, state, session_folder, created_at, started_at, finished_at)
+		VALUES (?, ?, ?, ?, ?, ?)`,
+		job.ID, string(job.State), job.SessionFolder,
+		formatTime(job.CreatedAt), formatTime(job.StartedAt), formatTime(job.FinishedAt)); err != nil {
+		return err
+	}
+
+	insertRecord, err := tx.Prepare(`INSERT INTO records (job_id, user_id, file_path, fingerprint,
+		entry_time, exit_time, entry_ic, exit_ic, vehicle_class, card_number,
+		original_toll, discount, toll, vehicle_number, note)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return err
+	}
+	defer insertRecord.Close()
+
+	for i, acc := range job.Accounts {
+		var size int64
+		if acc.FilePath != "" {
+			if info, err := os.Stat(acc.FilePath); err == nil {
+				size = info.Size()
+			}
+		}
+		if _, err := tx.Exec(`INSERT INTO run_accounts (job_id, idx, user_id, state, error, error_kind,
+			artifacts, file_path, file_size, started_at, finished_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			job.ID, i, acc.UserID, string(acc.State), acc.Error, acc.ErrorKind,
+			acc.Artifacts, acc.FilePath, size, formatTime(acc.StartedAt), formatTime(acc.FinishedAt)); err != nil {
+			return err
+		}
+
+		if acc.State != jobs.StateSucceeded || acc.FilePath == "" {
+			continue
+		}
+		records, err := parser.ParseMeisaiFile(acc.FilePath)
+		if err != nil {
+			h.logger.Printf("Warning: could not parse %s for history: %v", filepath.Base(acc.FilePath), err)
+			continue
+		}
+		for k, fp := range dedup.Fingerprints(records) {
+			r := records[k]
+			entry := ""
+			if !r.EntryTime.IsZero() {
+				entry = r.EntryTime.Format(recordLayout)
+			}
+			if _, err := insertRecord.Exec(job.ID, acc.UserID, acc.FilePath, fp,
+				entry, r.ExitTime.Format(recordLayout), r.EntryIC, r.ExitIC, r.VehicleClass, r.CardNumber,
+				r.OriginalToll, r.Discount, r.Toll, r.VehicleNumber, r.Note); err != nil {
+				return err
+			}
+		}
+	}
+	return tx.Commit()
+}
+
+// QueryRecords returns the usage rows matching q ordered by exit time, and the
+// number of matching rows without the limit. Unless q.AllRuns is set, a row
+// downloaded by several runs is returned once, from the latest run.
+func (h *DB) QueryRecords(q RecordQuery) ([]Record, int, error) {
+	var where []string
+	var args []any
+	if q.UserID != "" {
+		where = append(where, "user_id = ?")
+		args = append(args, q.UserID)
+	}
+	if q.FromDate != "" {
+		from, err := parseDate(q.FromDate)
+		if err != nil {
+			return nil, 0, err
+		}
+		where = append(where, "exit_time >= ?")
+		args = append(args, from.Format(dateLayout))
+	}
+	if q.ToDate != "" {
+		to, err := parseDate(q.ToDate)
+		if err != nil {
+			return nil, 0, err
+		}
+		where = append(where, "exit_time < ?")
+		args = append(args, to.AddDate(0, 0, 1).Format(dateLayout))
+	}
+	if q.CardNumber != "" {
+		where = append(where, `card_number LIKE ? ESCAPE '\'`)
+		args = append(args, "%"+escapeLike(q.CardNumber))
+	}
+	if q.VehicleNumber != "" {
+		where = append(where, `vehicle_number LIKE ? ESCAPE '\'`)
+		args = append(args, "%"+escapeLike(q.VehicleNumber)+"%")
+	}
+	if q.Interchange != "" {
+		where = append(where, `(entry_ic LIKE ? ESCAPE '\' OR exit_ic LIKE ? ESCAPE '\')`)
+		pattern := "%" + escapeLike(q.Interchange) + "%"
+		args = append(args, pattern, pattern)
+	}
+
+	filter := ""
+	if len(where) > 0 {
+		filter = " WHERE " + strings.Join(where, " AND ")
+	}
+	// 重複を除く場合は同じ明細のうち最新の実行（記録順ではなく作成日時順）の行のみ
+	matching := "SELECT id FROM records" + filter
+	if !q.AllRuns {
+		matching = `SELECT id FROM (SELECT records.id, ROW_NUMBER() OVER (
+			PARTITION BY records.user_id, records.fingerprint ORDER BY runs.created_at DESC, records.id DESC) AS n
+			FROM records JOIN runs USING (job_id)` + filter + `) WHERE n = 1`
+	}
+
+	var total int
+	if err := h.db.QueryRow("SELECT COUNT(*) FROM ("+matching+")", args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := h.db.Query(`SELECT user_id, job_id, file_path, entry_time, exit_time, entry_ic, exit_ic,
+		vehicle_class, card_number, original_toll, discount, toll, vehicle_number, note
+		FROM records WHERE id IN (`+matching+`)
+		ORDER BY exit_time, id LIMIT ? OFFSET ?`, append(args, limit(q.Limit), q.Offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var result []Record
+	for rows.Next() {
+		var r Record
+		var entry, exit string
+		if err := rows.Scan(&r.UserID, &r.JobID, &r.FilePath, &entry, &exit, &r.EntryIC, &r.ExitIC,
+			&r.VehicleClass, &r.CardNumber, &r.OriginalToll, &r.Discount, &r.Toll, &r.VehicleNumber, &r.Note); err != nil {
+			return nil, 0, err
+		}
+		if entry != "" {
+			r.EntryTime, _ = time.ParseInLocation(recordLayout, entry, time.Local)
+		}
+		r.ExitTime, _ = time.ParseInLocation(recordLayout, exit, time.Local)
+		result = append(result, r)
+	}
+	return result, total, rows.Err()
+}
+
+// Runs returns the stored runs matching q, newest first
+func (h *DB) Runs(q RunQuery) ([]*jobs.Job, error) {
+	var where []string
+	var args []any
+	if q.UserID != "" {
+		where = append(where, "job_id IN (SELECT job_id FROM run_accounts WHERE user_id = ?)")
+		args = append(args, q.UserID)
+	}
+	if q.FromDate != "" {
+		from, err := parseDate(q.FromDate)
+		if err != nil {
+			return nil, err
+		}
+		where = append(where, "created_at >= ?")
+		args = append(args, from.Format(dateLayout))
+	}
+	if q.ToDate != "" {
+		to, err := parseDate(q.ToDate)
+		if err != nil {
+			return nil, err
+		}
+		where = append(where, "created_at < ?")
+		args = append(args, to.AddDate(0, 0, 1).Format(dateLayout))
+	}
+	filter := ""
+	if len(where) > 0 {
+		filter = " WHERE " + strings.Join(where, " AND ")
+	}
+
+	rows, err := h.db.Query(`SELECT job_id, state, session_folder, created_at, started_at, finished_at
+		FROM runs`+filter+` ORDER BY created_at DESC LIMIT ?`, append(args, limit(q.Limit))...)
+	if err != nil {
+		return nil, err
+	}
+	var list []*jobs.Job
+	for rows.Next() {
+		job := &jobs.Job{}
+		var state, created, started, finished string
+		if err := rows.Scan(&job.ID, &state, &job.SessionFolder, &created, &started, &finished); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		job.State = jobs.State(state)
+		job.CreatedAt, job.StartedAt, job.FinishedAt = parseTime(created), parseTime(started), parseTime(finished)
+		list = append(list, job)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, job := range list {
+		if job.Accounts, err = h.accounts(job.ID); err != nil {
+			return nil, err
+		}
+	}
+	return list, nil
+}
+
+// accounts returns the account outcomes of a stored run
+func (h *DB) accounts(jobID string) ([]*jobs.AccountStatus, error) {
+	rows, err := h.db.Query(`SELECT user_id, state, error, error_kind, artifacts, file_path, started_at, finished_at
+		FROM run_accounts WHERE job_id = ? ORDER BY idx`, jobID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []*jobs.AccountStatus
+	for rows.Next() {
+		acc := &jobs.AccountStatus{}
+		var state, started, finished string
+		if err := rows.Scan(&acc.UserID, &state, &acc.Error, &acc.ErrorKind, &acc.Artifacts, &acc.FilePath, &started, &finished); err != nil {
+			return nil, err
+		}
+		acc.State = jobs.State(state)
+		acc.StartedAt, acc.FinishedAt = parseTime(started), parseTime(finished)
+		list = append(list, acc)
+	}
+	return list, rows.Err()
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Local().Format(timeLayout)
+}
+
+func parseTime(s string) time.Time {
+	t, err := time.Parse(timeLayout, s)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+func parseDate(s string) (time.Time, error) {
+	t, err := time.ParseInLocation(dateLayout, s, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: date %q (want YYYY-MM-DD)", ErrInvalidQuery, s)
+	}
+	return t, nil
+}
+
+func limit(n int) int {
+	if n <= 0 {
+		return DefaultLimit
+	}
+	return n
+}
+
+// escapeLike escapes the LIKE wildcards in s for ESCAPE '\'
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
@@ -0,0 +1,221 @@
+package history
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/scrape-vm/jobs"
+)
+
+const header = "利用年月日(自),時刻(自),利用年月日(至),時刻(至),利用IC(自),利用IC(至),割引前料金,ETC割引額,通行料金,車種,車両番号,ETCカード番号,備考\n"
+
+func writeCSV(t *testing.T, path string, rows ...string) string {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(header+strings.Join(rows, "\n")+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func job(id string, day int, accounts ...*jobs.AccountStatus) *jobs.Job {
+	created := time.Date(2025, 1, day, 9, 0, 0, 0, time.Local)
+	return &jobs.Job{
+		ID:            id,
+		State:         jobs.StateSucceeded,
+		SessionFolder: created.Format("20060102_150405"),
+		CreatedAt:     created,
+		StartedAt:     created,
+		FinishedAt:    created.Add(time.Minute),
+		Accounts:      accounts,
+	}
+}
+
+func openTestDB(t *testing.T) (*DB, string) {
+	t.Helper()
+	dir := t.TempDir()
+	db, err := Open(filepath.Join(dir, DBFile), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	row0105 := "25/01/05,08:00,25/01/05,09:00,東京,横浜,1200,200,1000,普通車,品川300あ12-34,****1234,"
+	row0106 := "25/01/06,08:00,25/01/06,09:00,横浜,厚木,800,0,800,普通車,品川300あ12-34,****1234,"
+	row0107 := "25/01/07,10:00,25/01/07,10:30,首都高_C1,川崎,500,0,500,普通車,品川300あ56-78,****1234,"
+	row0108 := "25/01/08,10:00,25/01/08,11:00,厚木,東京,1000,0,1000,普通車,品川300あ12-34,****1234,"
+	other := "25/01/06,08:00,25/01/06,09:00,横浜,厚木,800,0,800,普通車,練馬500え99-99,****9999,"
+
+	job1 := job("job1", 10,
+		&jobs.AccountStatus{UserID: "user1", State: jobs.StateSucceeded, FilePath: writeCSV(t, filepath.Join(dir, "job1_user1.csv"), row0105, row0106, row0107)},
+		&jobs.AccountStatus{UserID: "user2", State: jobs.StateFailed, Error: "login failed", ErrorKind: "login"},
+	)
+	// 2回目は期間が重なり、01/06 の明細を再取得する
+	job2 := job("job2", 12,
+		&jobs.AccountStatus{UserID: "user1", State: jobs.StateSucceeded, FilePath: writeCSV(t, filepath.Join(dir, "job2_user1.csv"), row0106, row0108)},
+		&jobs.AccountStatus{UserID: "user2", State: jobs.StateSucceeded, FilePath: writeCSV(t, filepath.Join(dir, "job2_user2.csv"), other)},
+	)
+	for _, j := range []*jobs.Job{job1, job2, job1} {
+		if err := db.AddJob(j); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return db, dir
+}
+
+func TestAddJobReplaces(t *testing.T) {
+	db, _ := openTestDB(t)
+
+	// job1 を2回追加しても行は増えない
+	all, total, err := db.QueryRecords(RecordQuery{AllRuns: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 6 || len(all) != 6 {
+		t.Fatalf("AllRuns: %d rows (total %d), want 6", len(all), total)
+	}
+
+	runs, err := db.Runs(RunQuery{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(runs) != 2 || runs[0].ID != "job2" || runs[1].ID != "job1" {
+		t.Fatalf("Runs = %v, want job2, job1", runs)
+	}
+	acc := runs[1].Accounts
+	if len(acc) != 2 || acc[1].UserID != "user2" || acc[1].State != jobs.StateFailed || acc[1].Error != "login failed" || acc[1].ErrorKind != "login" {
+		t.Errorf("job1 accounts = %+v", acc)
+	}
+	if !runs[1].CreatedAt.Equal(time.Date(2025, 1, 10, 9, 0, 0, 0, time.Local)) {
+		t.Errorf("job1 created at %v", runs[1].CreatedAt)
+	}
+
+	// 状態が変わった同じジョブで置き換える
+	replaced := *runs[1]
+	replaced.State = jobs.StateCancelled
+	replaced.Accounts = replaced.Accounts[1:]
+	if err := db.AddJob(&replaced); err != nil {
+		t.Fatal(err)
+	}
+	runs, _ = db.Runs(RunQuery{})
+	if runs[1].State != jobs.StateCancelled || len(runs[1].Accounts) != 1 {
+		t.Errorf("replaced job1 = %+v", runs[1])
+	}
+	if _, total, _ := db.QueryRecords(RecordQuery{AllRuns: true}); total != 3 {
+		t.Errorf("after replacing job1: total %d, want the 3 rows of job2", total)
+	}
+}
+
+func TestQueryRecords(t *testing.T) {
+	db, _ := openTestDB(t)
+
+	// 重複を除くと 01/06 の明細は最新の job2 の1行のみ
+	records, total, err := db.QueryRecords(RecordQuery{UserID: "user1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 4 || len(records) != 4 {
+		t.Fatalf("user1: %d rows (total %d), want 4", len(records), total)
+	}
+	for i, day := range []int{5, 6, 7, 8} {
+		if records[i].ExitTime.Day() != day {
+			t.Errorf("row %d: exit %v, want day %d", i, records[i].ExitTime, day)
+		}
+	}
+	if records[1].JobID != "job2" || records[0].JobID != "job1" {
+		t.Errorf("jobs = %s, %s, want job1, job2", records[0].JobID, records[1].JobID)
+	}
+	if r := records[0]; r.EntryIC != "東京" || r.Discount != 200 || r.Toll != 1000 || r.UserID != "user1" ||
+		!r.EntryTime.Equal(time.Date(2025, 1, 5, 8, 0, 0, 0, time.Local)) || filepath.Base(r.FilePath) != "job1_user1.csv" {
+		t.Errorf("first row = %+v", r)
+	}
+
+	for _, tt := range []struct {
+		name  string
+		query RecordQuery
+		want  int
+	}{
+		{"all", RecordQuery{}, 5},
+		{"all runs", RecordQuery{AllRuns: true}, 6},
+		{"account", RecordQuery{UserID: "user2"}, 1},
+		{"unknown account", RecordQuery{UserID: "user3"}, 0},
+		{"dates inclusive", RecordQuery{FromDate: "2025-01-06", ToDate: "2025-01-07"}, 3},
+		{"from", RecordQuery{FromDate: "2025-01-08"}, 1},
+		{"to", RecordQuery{ToDate: "2025-01-05"}, 1},
+		{"card suffix", RecordQuery{CardNumber: "1234"}, 4},
+		{"card not suffix", RecordQuery{CardNumber: "12"}, 0},
+		{"vehicle", RecordQuery{VehicleNumber: "12-34"}, 3},
+		{"entry or exit interchange", RecordQuery{Interchange: "横浜"}, 3},
+		{"escaped underscore", RecordQuery{Interchange: "_"}, 1},
+		{"escaped percent", RecordQuery{Interchange: "%"}, 0},
+		{"combined", RecordQuery{UserID: "user1", Interchange: "厚木", FromDate: "2025-01-07"}, 1},
+	} {
+		got, total, err := db.QueryRecords(tt.query)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if total != tt.want || len(got) != tt.want {
+			t.Errorf("%s: %d rows (total %d), want %d", tt.name, len(got), total, tt.want)
+		}
+	}
+}
+
+func TestQueryRecordsLimit(t *testing.T) {
+	db, _ := openTestDB(t)
+
+	page, total, err := db.QueryRecords(RecordQuery{Limit: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 5 || len(page) != 2 {
+		t.Fatalf("limit 2: %d rows (total %d), want 2 of 5", len(page), total)
+	}
+	last, total, _ := db.QueryRecords(RecordQuery{Limit: 2, Offset: 4})
+	if total != 5 || len(last) != 1 || last[0].ExitTime.Day() != 8 {
+		t.Errorf("offset 4: %+v (total %d), want the row of day 8", last, total)
+	}
+}
+
+func TestInvalidQuery(t *testing.T) {
+	db, _ := openTestDB(t)
+
+	for _, q := range []RecordQuery{{FromDate: "2025/01/01"}, {ToDate: "yesterday"}} {
+		if _, _, err := db.QueryRecords(q); !errors.Is(err, ErrInvalidQuery) {
+			t.Errorf("QueryRecords(%+v) = %v, want ErrInvalidQuery", q, err)
+		}
+	}
+	if _, err := db.Runs(RunQuery{FromDate: "20250101"}); !errors.Is(err, ErrInvalidQuery) {
+		t.Errorf("Runs: %v, want ErrInvalidQuery", err)
+	}
+}
+
+func TestRunsFilter(t *testing.T) {
+	db, _ := openTestDB(t)
+
+	for _, tt := range []struct {
+		query RunQuery
+		want  []string
+	}{
+		{RunQuery{UserID: "user2"}, []string{"job2", "job1"}},
+		{RunQuery{FromDate: "2025-01-11"}, []string{"job2"}},
+		{RunQuery{ToDate: "2025-01-10"}, []string{"job1"}},
+		{RunQuery{Limit: 1}, []string{"job2"}},
+		{RunQuery{UserID: "user3"}, nil},
+	} {
+		runs, err := db.Runs(tt.query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, r := range runs {
+			got = append(got, r.ID)
+		}
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("Runs(%+v) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
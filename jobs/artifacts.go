@@ -0,0 +1,38 @@
+package jobs
+
+import (
+	"os"
+
+	"github.com/scrape-vm/scrapers"
+)
+
+// AccountArtifacts are the failure artifacts captured for an account of a job
+type AccountArtifacts struct {
+	UserID    string                  `json:"userId"`
+	Error     string                  `json:"error"`
+	Directory string                  `json:"directory"`
+	Files     []scrapers.ArtifactFile `json:"files"`
+}
+
+// Artifacts reads the failure artifacts of the accounts of the job (all accounts
+// if userID is empty). Accounts without artifacts are skipped; a folder removed
+// since the failure is reported without files.
+func (j *Job) Artifacts(userID string, withData bool) ([]*AccountArtifacts, error) {
+	var list []*AccountArtifacts
+	for _, acc := range j.Accounts {
+		if acc.Artifacts == "" || (userID != "" && acc.UserID != userID) {
+			continue
+		}
+		files, err := scrapers.ReadArtifacts(acc.Artifacts, withData)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		list = append(list, &AccountArtifacts{
+			UserID:    acc.UserID,
+			Error:     acc.Error,
+			Directory: acc.Artifacts,
+			Files:     files,
+		})
+	}
+	return list, nil
+}
@@ -0,0 +1,64 @@
+package jobs
+
+import "time"
+
+// State is the state of a job or of a single account within a job
+type State string
+
+const (
+	StateQueued    State = "queued"
+	StateRunning   State = "running"
+	StateSucceeded State = "succeeded"
+	StateFailed    State = "failed"
+	StateCancelled State = "cancelled"
+)
+
+// Finished reports whether the state is terminal
+func (s State) Finished() bool {
+	return s == StateSucceeded || s == StateFailed || s == StateCancelled
+}
+
+// AccountStatus tracks one account of a job
+type AccountStatus struct {
+	UserID     string    `json:"userId"`
+	State      State     `json:"state"`
+	Error      string    `json:"error,omitempty"`
+	ErrorKind  string    `json:"errorKind,omitempty"` // see scrapers.ErrorKind
+	FilePath   string    `json:"filePath,omitempty"`
+	Artifacts  string    `json:"artifacts,omitempty"` // failure screenshot, HTML and logs, see scrapers.ArtifactsDir
+	StartedAt  time.Time `json:"startedAt,omitempty"`
+	FinishedAt time.Time `json:"finishedAt,omitempty"`
+}
+
+// Job is a Scrape or ScrapeMultiple run over one or more accounts
+type Job struct {
+	ID            string           `json:"id"`
+	State         State            `json:"state"`
+	SessionFolder string           `json:"sessionFolder"`
+	CreatedAt     time.Time        `json:"createdAt"`
+	StartedAt     time.Time        `json:"startedAt,omitempty"`
+	FinishedAt    time.Time        `json:"finishedAt,omitempty"`
+	Accounts      []*AccountStatus `json:"accounts"`
+}
+
+// SuccessCount returns the number of accounts that succeeded
+func (j *Job) SuccessCount() int {
+	n := 0
+	for _, a := range j.Accounts {
+		if a.State == StateSucceeded {
+			n++
+		}
+	}
+	return n
+}
+
+// clone returns a deep copy safe to hand out to callers
+func (j *Job) clone() *Job {
+	c := *j
+	c.Accounts = make([]*AccountStatus, len(j.Accounts))
+	for i, a := range j.Accounts {
+		ac := *a
+		c.Accounts[i] = &ac
+	}
+	return &c
+}
@@ -0,0 +1,448 @@
+package jobs
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/scrape-vm/scrapers"
+)
+
+// StoreFile is the name of the job store inside the download directory
+const StoreFile = "jobs.json"
+
+// DefaultMaxJobs is the number of finished jobs kept in the store
+const DefaultMaxJobs = 200
+
+var (
+	ErrNotFound = errors.New("job not found")
+	ErrFinished = errors.New("job already finished")
+)
+
+// RunFunc processes the account at index and returns the downloaded file path
+type RunFunc func(ctx context.Context, index int) (string, error)
+
+// Manager tracks jobs and persists their state to a JSON file
+type Manager struct {
+	path         string
+	logger       *log.Logger
+	mu           sync.Mutex
+	jobs         map[string]*Job
+	cancels      map[string]context.CancelFunc
+	ctx          context.Context
+	cancel       context.CancelFunc
+	MaxJobs      int
+	AccountDelay time.Duration // wait between accounts processed by the same worker
+	Parallel     int           // accounts processed concurrently (default 1)
+	OnFinished   func(*Job)    // called with a snapshot when a job reaches a final state
+}
+
+// NewManager creates a job manager persisting to path (empty for in-memory only)
+func NewManager(path string, logger *log.Logger) *Manager {
+	if logger == nil {
+		logger = log.Default()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	m := &Manager{
+		path:         path,
+		logger:       logger,
+		jobs:         make(map[string]*Job),
+		cancels:      make(map[string]context.CancelFunc),
+		ctx:          ctx,
+		cancel:       cancel,
+		MaxJobs:      DefaultMaxJobs,
+		AccountDelay: 2 * time.Second,
+	}
+	if err := m.load(); err != nil {
+		logger.Printf("Warning: could not load jobs from %s: %v", path, err)
+	}
+	return m
+}
+
+// Create registers a new queued job for the given accounts
+func (m *Manager) Create(userIDs []string, sessionFolder string) *Job {
+	job := &Job{
+		ID:            newID(),
+		State:         StateQueued,
+		SessionFolder: sessionFolder,
+		CreatedAt:     time.Now(),
+	}
+	for _, id := range userIDs {
+		job.Accounts = append(job.Accounts, &AccountStatus{UserID: id, State: StateQueued})
+	}
+
+	m.mu.Lock()
+	m.jobs[job.ID] = job
+	m.saveLocked()
+	snapshot := job.clone()
+	m.mu.Unlock()
+
+	return snapshot
+}
+
+// Start runs the job in the background
+func (m *Manager) Start(id string, run RunFunc) {
+	go m.Run(m.ctx, id, run)
+}
+
+// Run processes the accounts of the job with up to Parallel workers and returns
+// its final state. Cancelling ctx, calling Cancel or Shutdown cancels the context
+// passed to run and stops the job before the next account.
+func (m *Manager) Run(ctx context.Context, id string, run RunFunc) *Job {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stop := context.AfterFunc(m.ctx, cancel)
+	defer stop()
+
+	m.mu.Lock()
+	job, ok := m.jobs[id]
+	if !ok {
+		m.mu.Unlock()
+		return nil
+	}
+	if job.State.Finished() {
+		// 開始前にキャンセルされた
+		snapshot := job.clone()
+		m.mu.Unlock()
+		return snapshot
+	}
+	m.cancels[id] = cancel
+	job.State = StateRunning
+	job.StartedAt = time.Now()
+	m.saveLocked()
+	m.mu.Unlock()
+
+	workers := m.Parallel
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > len(job.Accounts) {
+		workers = len(job.Accounts)
+	}
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			first := true
+			for i := range indexes {
+				if !first && m.AccountDelay > 0 {
+					select {
+					case <-time.After(m.AccountDelay):
+					case <-ctx.Done():
+					}
+				}
+				first = false
+				if ctx.Err() != nil {
+					continue
+				}
+				m.runAccount(ctx, id, i, run)
+			}
+		}()
+	}
+
+feed:
+	for i := range job.Accounts {
+		select {
+		case indexes <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(indexes)
+	wg.Wait()
+
+	m.mu.Lock()
+	delete(m.cancels, id)
+
+	cancelled := ctx.Err() != nil
+	for _, acc := range job.Accounts {
+		if acc.State == StateQueued {
+			acc.State = StateCancelled
+		}
+	}
+	switch {
+	case job.SuccessCount() == len(job.Accounts):
+		job.State = StateSucceeded
+	case cancelled:
+		job.State = StateCancelled
+	default:
+		job.State = StateFailed
+	}
+	job.FinishedAt = time.Now()
+	m.pruneLocked()
+	m.saveLocked()
+
+	m.logger.Printf("Job %s %s: %d/%d accounts succeeded", id, job.State, job.SuccessCount(), len(job.Accounts))
+	snapshot := job.clone()
+	m.mu.Unlock()
+
+	m.finished(snapshot)
+	return snapshot
+}
+
+// runAccount runs the account at index and records its result
+func (m *Manager) runAccount(ctx context.Context, id string, i int, run RunFunc) {
+	m.update(id, func(j *Job) {
+		j.Accounts[i].State = StateRunning
+		j.Accounts[i].StartedAt = time.Now()
+	})
+
+	path, err := run(ctx, i)
+
+	m.update(id, func(j *Job) {
+		acc := j.Accounts[i]
+		acc.FinishedAt = time.Now()
+		switch {
+		case err == nil:
+			acc.State = StateSucceeded
+			acc.FilePath = path
+		case ctx.Err() != nil:
+			acc.State = StateCancelled
+			acc.Error = err.Error()
+		default:
+			acc.State = StateFailed
+			acc.Error = err.Error()
+			acc.ErrorKind = scrapers.ErrorKind(err)
+			acc.Artifacts = scrapers.ArtifactsDir(err)
+		}
+	})
+}
+
+// Get returns a snapshot of the job
+func (m *Manager) Get(id string) (*Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	job, ok := m.jobs[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return job.clone(), nil
+}
+
+// List returns snapshots of the newest jobs first (limit <= 0 for all)
+func (m *Manager) List(limit int) []*Job {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	list := make([]*Job, 0, len(m.jobs))
+	for _, job := range m.jobs {
+		list = append(list, job.clone())
+	}
+	sort.Slice(list, func(i, k int) bool {
+		return list[i].CreatedAt.After(list[k].CreatedAt)
+	})
+	if limit > 0 && len(list) > limit {
+		list = list[:limit]
+	}
+	return list
+}
+
+// ActiveSessions returns the session folders of the queued and running jobs
+func (m *Manager) ActiveSessions() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return activeSessions(m.jobs)
+}
+
+// LoadActiveSessions reads the session folders of the queued and running jobs
+// from the store file at path without changing it, for use outside the
+// process running the jobs
+func LoadActiveSessions(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var list []*Job
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, err
+	}
+	jobs := make(map[string]*Job, len(list))
+	for _, job := range list {
+		jobs[job.ID] = job
+	}
+	return activeSessions(jobs), nil
+}
+
+func activeSessions(jobs map[string]*Job) []string {
+	var folders []string
+	for _, job := range jobs {
+		if !job.State.Finished() && job.SessionFolder != "" {
+			folders = append(folders, job.SessionFolder)
+		}
+	}
+	sort.Strings(folders)
+	return folders
+}
+
+// Cancel requests cancellation of a queued or running job
+func (m *Manager) Cancel(id string) error {
+	m.mu.Lock()
+
+	job, ok := m.jobs[id]
+	if !ok {
+		m.mu.Unlock()
+		return ErrNotFound
+	}
+	if job.State.Finished() {
+		m.mu.Unlock()
+		return ErrFinished
+	}
+
+	if cancel, ok := m.cancels[id]; ok {
+		cancel()
+		m.mu.Unlock()
+		return nil
+	}
+
+	// まだ開始していないジョブはその場でキャンセル
+	for _, acc := range job.Accounts {
+		acc.State = StateCancelled
+	}
+	job.State = StateCancelled
+	job.FinishedAt = time.Now()
+	m.saveLocked()
+	snapshot := job.clone()
+	m.mu.Unlock()
+
+	m.finished(snapshot)
+	return nil
+}
+
+// finished calls OnFinished; m.mu must not be held
+func (m *Manager) finished(job *Job) {
+	if m.OnFinished != nil {
+		m.OnFinished(job)
+	}
+}
+
+// Shutdown cancels all running jobs
+func (m *Manager) Shutdown() {
+	m.cancel()
+}
+
+// update applies fn to the job and persists the result
+func (m *Manager) update(id string, fn func(*Job)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if job, ok := m.jobs[id]; ok {
+		fn(job)
+		m.saveLocked()
+	}
+}
+
+// load reads persisted jobs; jobs left running by a previous process are marked failed
+func (m *Manager) load() error {
+	if m.path == "" {
+		return nil
+	}
+	data, err := os.ReadFile(m.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	var list []*Job
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+
+	for _, job := range list {
+		if !job.State.Finished() {
+			for _, acc := range job.Accounts {
+				if !acc.State.Finished() {
+					acc.State = StateFailed
+					acc.Error = "interrupted by service restart"
+				}
+			}
+			job.State = StateFailed
+		}
+		m.jobs[job.ID] = job
+	}
+	return nil
+}
+
+// saveLocked writes all jobs to the store file; m.mu must be held
+func (m *Manager) saveLocked() {
+	if m.path == "" {
+		return
+	}
+
+	list := make([]*Job, 0, len(m.jobs))
+	for _, job := range m.jobs {
+		list = append(list, job)
+	}
+	sort.Slice(list, func(i, k int) bool {
+		return list[i].CreatedAt.Before(list[k].CreatedAt)
+	})
+
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		m.logger.Printf("Warning: could not encode jobs: %v", err)
+		return
+	}
+
+	// 書き込み途中でのクラッシュに備えて一時ファイル経由で置き換える
+	if err := os.MkdirAll(filepath.Dir(m.path), 0755); err != nil {
+		m.logger.Printf("Warning: could not save jobs: %v", err)
+		return
+	}
+	tmp := m.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		m.logger.Printf("Warning: could not save jobs: %v", err)
+		return
+	}
+	if err := os.Rename(tmp, m.path); err != nil {
+		m.logger.Printf("Warning: could not save jobs: %v", err)
+	}
+}
+
+// pruneLocked drops the oldest finished jobs beyond MaxJobs; m.mu must be held
+func (m *Manager) pruneLocked() {
+	if m.MaxJobs <= 0 || len(m.jobs) <= m.MaxJobs {
+		return
+	}
+
+	var finished []*Job
+	for _, job := range m.jobs {
+		if job.State.Finished() {
+			finished = append(finished, job)
+		}
+	}
+	sort.Slice(finished, func(i, k int) bool {
+		return finished[i].CreatedAt.Before(finished[k].CreatedAt)
+	})
+	for _, job := range finished {
+		if len(m.jobs) <= m.MaxJobs {
+			break
+		}
+		delete(m.jobs, job.ID)
+	}
+}
+
+// newID returns a sortable, unique job ID such as "20250115-093000-1a2b3c4d"
+func newID() string {
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%s-%08x", time.Now().Format("20060102-150405"), time.Now().UnixNano()&0xffffffff)
+	}
+	return time.Now().Format("20060102-150405") + "-" + hex.EncodeToString(b)
+}
@@ -0,0 +1,199 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newTestManager(t *testing.T) (*Manager, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), StoreFile)
+	m := NewManager(path, log.New(io.Discard, "", 0))
+	m.AccountDelay = 0
+	return m, path
+}
+
+// readStore returns the jobs in the store file
+func readStore(t *testing.T, path string) []*Job {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var list []*Job
+	if err := json.Unmarshal(data, &list); err != nil {
+		t.Fatalf("%s: %v", path, err)
+	}
+	return list
+}
+
+func TestPersistence(t *testing.T) {
+	m, path := newTestManager(t)
+	job := m.Create([]string{"user1", "user2"}, "/downloads/20250115_093000")
+	final := m.Run(context.Background(), job.ID, func(ctx context.Context, i int) (string, error) {
+		if i == 1 {
+			return "", errors.New("login failed")
+		}
+		return "/downloads/20250115_093000/user1_meisai.csv", nil
+	})
+	if final.State != StateFailed || final.SuccessCount() != 1 {
+		t.Fatalf("job = %+v", final)
+	}
+	if _, err := os.Stat(path + ".tmp"); !os.IsNotExist(err) {
+		t.Errorf("temporary file left: %v", err)
+	}
+
+	// 別のプロセスから読み込んでも同じ状態
+	reloaded, err := NewManager(path, nil).Get(job.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reloaded.State != StateFailed || reloaded.SessionFolder != job.SessionFolder ||
+		reloaded.Accounts[0].FilePath != "/downloads/20250115_093000/user1_meisai.csv" || reloaded.Accounts[1].Error != "login failed" {
+		t.Errorf("reloaded job = %+v", reloaded)
+	}
+
+	// 一時ファイルに書き込めなくても既存のファイルは壊れない
+	if err := os.Mkdir(path+".tmp", 0755); err != nil {
+		t.Fatal(err)
+	}
+	m.Create([]string{"user3"}, "")
+	if list := readStore(t, path); len(list) != 1 || list[0].ID != job.ID {
+		t.Errorf("store = %v, want only %s", list, job.ID)
+	}
+}
+
+func TestLoadMarksInterrupted(t *testing.T) {
+	path := filepath.Join(t.TempDir(), StoreFile)
+	created := time.Date(2025, 1, 15, 9, 30, 0, 0, time.UTC)
+	data, _ := json.Marshal([]*Job{
+		{ID: "running", State: StateRunning, SessionFolder: "/downloads/20250115_093000", CreatedAt: created, Accounts: []*AccountStatus{
+			{UserID: "user1", State: StateSucceeded, FilePath: "user1_meisai.csv"},
+			{UserID: "user2", State: StateRunning},
+			{UserID: "user3", State: StateQueued},
+		}},
+		{ID: "done", State: StateSucceeded, SessionFolder: "/downloads/20250114_093000", CreatedAt: created.Add(-24 * time.Hour), Accounts: []*AccountStatus{
+			{UserID: "user1", State: StateSucceeded},
+		}},
+	})
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// サービス以外のプロセスからは実行中のジョブとして見える
+	if active, err := LoadActiveSessions(path); err != nil || len(active) != 1 || active[0] != "/downloads/20250115_093000" {
+		t.Errorf("LoadActiveSessions = %v, %v", active, err)
+	}
+
+	m := NewManager(path, log.New(io.Discard, "", 0))
+	job, err := m.Get("running")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job.State != StateFailed {
+		t.Errorf("job state = %s, want failed", job.State)
+	}
+	for i, want := range []State{StateSucceeded, StateFailed, StateFailed} {
+		if acc := job.Accounts[i]; acc.State != want || (want == StateFailed) != (acc.Error != "") {
+			t.Errorf("account %s = %s %q, want %s", acc.UserID, acc.State, acc.Error, want)
+		}
+	}
+	if done, _ := m.Get("done"); done.State != StateSucceeded {
+		t.Errorf("finished job state = %s", done.State)
+	}
+	if active := m.ActiveSessions(); len(active) != 0 {
+		t.Errorf("ActiveSessions = %v after restart", active)
+	}
+}
+
+func TestCancel(t *testing.T) {
+	m, _ := newTestManager(t)
+	var finished []*Job
+	m.OnFinished = func(j *Job) { finished = append(finished, j) }
+
+	// 開始前のジョブはその場でキャンセル
+	queued := m.Create([]string{"user1"}, "/downloads/a")
+	if active := m.ActiveSessions(); len(active) != 1 || active[0] != "/downloads/a" {
+		t.Errorf("ActiveSessions = %v", active)
+	}
+	if err := m.Cancel(queued.ID); err != nil {
+		t.Fatal(err)
+	}
+	if job, _ := m.Get(queued.ID); job.State != StateCancelled || job.Accounts[0].State != StateCancelled {
+		t.Errorf("queued job = %+v", job)
+	}
+	if err := m.Cancel(queued.ID); !errors.Is(err, ErrFinished) {
+		t.Errorf("second Cancel: %v, want ErrFinished", err)
+	}
+	if err := m.Cancel("missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Cancel(missing): %v, want ErrNotFound", err)
+	}
+	if job := m.Run(context.Background(), queued.ID, nil); job.State != StateCancelled {
+		t.Errorf("Run of cancelled job = %s", job.State)
+	}
+
+	// 実行中のジョブは残りのアカウントも含めてキャンセル
+	running := m.Create([]string{"user1", "user2"}, "/downloads/b")
+	started := make(chan struct{})
+	done := make(chan *Job)
+	go func() {
+		done <- m.Run(context.Background(), running.ID, func(ctx context.Context, i int) (string, error) {
+			close(started)
+			<-ctx.Done()
+			return "", ctx.Err()
+		})
+	}()
+	<-started
+	if err := m.Cancel(running.ID); err != nil {
+		t.Fatal(err)
+	}
+	job := <-done
+	if job.State != StateCancelled || job.Accounts[0].State != StateCancelled || job.Accounts[1].State != StateCancelled {
+		t.Errorf("running job = %+v", job)
+	}
+
+	if len(finished) != 2 || finished[0].ID != queued.ID || finished[1].ID != running.ID {
+		t.Errorf("OnFinished called with %v", finished)
+	}
+}
+
+func TestListAndPrune(t *testing.T) {
+	m, path := newTestManager(t)
+	m.MaxJobs = 3
+	base := time.Now()
+	var ids []string
+	for i := 0; i < 4; i++ {
+		job := m.Create([]string{"user1"}, "")
+		// 作成日時の順序を確定させる
+		m.jobs[job.ID].CreatedAt = base.Add(time.Duration(i) * time.Minute)
+		ids = append(ids, job.ID)
+	}
+
+	list := m.List(2)
+	if len(list) != 2 || list[0].ID != ids[3] || list[1].ID != ids[2] {
+		t.Errorf("List(2) = %v", list)
+	}
+	if all := m.List(0); len(all) != 4 {
+		t.Errorf("List(0) returned %d jobs, want 4", len(all))
+	}
+
+	// 上限を超えた分は終了済みの古いジョブから削除
+	m.Cancel(ids[1])
+	m.Run(context.Background(), ids[3], func(ctx context.Context, i int) (string, error) { return "x.csv", nil })
+	if _, err := m.Get(ids[1]); !errors.Is(err, ErrNotFound) {
+		t.Errorf("oldest finished job kept: %v", err)
+	}
+	if _, err := m.Get(ids[0]); err != nil {
+		t.Errorf("queued job pruned: %v", err)
+	}
+	if stored := readStore(t, path); len(stored) != 3 {
+		t.Errorf("store has %d jobs, want 3", len(stored))
+	}
+}
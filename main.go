@@ -16,11 +16,18 @@ import (
 	"github.com/anthropics/cf-wbrtc-auth/go/grpcweb"
 	svc "github.com/kardianos/service"
 	"github.com/pion/webrtc/v4"
+	"github.com/scrape-vm/access"
+	"github.com/scrape-vm/dedup"
+	"github.com/scrape-vm/engine"
+	"github.com/scrape-vm/history"
+	"github.com/scrape-vm/jobs"
 	"github.com/scrape-vm/p2p"
+	"github.com/scrape-vm/retention"
 	"github.com/scrape-vm/scrapers"
 	"github.com/scrape-vm/server"
 	myservice "github.com/scrape-vm/service"
 	"github.com/scrape-vm/updater"
+	"github.com/scrape-vm/vault"
 )
 
 func main() {
@@ -28,8 +35,22 @@ func main() {
 	accountsFlag := flag.String("accounts", "", "Accounts in format: user1:pass1,user2:pass2")
 	headless := flag.Bool("headless", true, "Run in headless mode")
 	downloadPath := flag.String("download", "./downloads", "Download directory")
+	keepOriginal := flag.Bool("keep-original", false, "Keep the original Shift_JIS CSV next to the UTF-8 copy")
+	parallel := flag.Int("parallel", 1, "Number of accounts scraped concurrently (each with its own browser)")
+	newRecords := flag.String("new-records", "", "CLI mode: write only the records not downloaded by an earlier run to this CSV file")
+	scraperType := flag.String("scraper", scrapers.DefaultType, "Scraper type for -accounts (available: "+strings.Join(scrapers.Types(), ", ")+")")
 	grpcMode := flag.Bool("grpc", false, "Run as gRPC server")
 	grpcPort := flag.String("port", "50051", "gRPC server port")
+	grpcListen := flag.String("listen", "", "gRPC listen address (host or host:port, e.g. 127.0.0.1; default: all interfaces)")
+	tlsCert := flag.String("tls-cert", "", "TLS certificate file (PEM) for the gRPC server")
+	tlsKey := flag.String("tls-key", "", "TLS private key file (PEM) for the gRPC server")
+	tlsClientCA := flag.String("tls-client-ca", "", "CA certificates (PEM) for verifying gRPC client certificates (mTLS)")
+	authTokens := flag.String("auth-tokens", "", "File with accepted gRPC bearer tokens / API keys, one per line")
+
+	// 利用期間の指定
+	fromDate := flag.String("from", "", "Usage period start date (YYYY-MM-DD)")
+	toDate := flag.String("to", "", "Usage period end date (YYYY-MM-DD, default: today)")
+	lastMonths := flag.Int("months", 0, "Search the previous N months plus the current month (instead of -from/-to)")
 
 	// P2Pモード用フラグ
 	p2pMode := flag.Bool("p2p", false, "Run as P2P client")
@@ -39,6 +60,27 @@ func main() {
 	p2pAPIKey := flag.String("p2p-apikey", "", "P2P API key (or set P2P_API_KEY env)")
 	p2pAppName := flag.String("p2p-name", "etc-scraper", "P2P app name")
 	p2pCredsFile := flag.String("p2p-creds", "p2p_credentials.env", "P2P credentials file path")
+	p2pMaxPeers := flag.Int("p2p-max-peers", p2p.DefaultMaxPeers, "Maximum number of browsers connected at the same time")
+	p2pPolicy := flag.String("p2p-policy", "", "P2P access policy file (JSON, roles per browser user; empty: read-only for every user, \""+access.AllowAll+"\": full access for every user)")
+
+	// 定期実行（サービスモード）
+	schedulesFile := flag.String("schedules", "", "Schedule file for recurring scrapes in service mode (JSON, editable with the schedule RPCs; default schedules.json next to the executable)")
+
+	// ダウンロードフォルダの整理（サービスでは定期実行、-pruneで1回実行）
+	keepSessions := flag.Int("keep-sessions", 0, "Keep at most this many sessions in the download directory (0: no limit)")
+	keepDays := flag.Int("keep-days", 0, "Remove sessions older than this many days (0: no limit)")
+	maxDownloadMB := flag.Int64("max-download-mb", 0, "Remove the oldest sessions while the download directory is larger than this many MB (0: no limit)")
+	compressDays := flag.Int("compress-after-days", 0, "Compress session folders older than this many days into zip archives (0: never)")
+	pruneMode := flag.Bool("prune", false, "Apply the retention flags to the download directory once and exit")
+	dryRun := flag.Bool("dry-run", false, "With -prune, only list what would be removed or compressed")
+
+	// アカウント保管庫
+	vaultPath := flag.String("vault", "accounts.vault", "Encrypted account vault file (accounts referenced by account_ref / -account-refs)")
+	vaultKey := flag.String("vault-key", "", "Vault key file (default: "+vault.DefaultKeyFile+" next to -p2p-creds; not used when "+vault.PassphraseEnv+" is set)")
+	vaultCmd := flag.String("vault-cmd", "", "Vault command: add|remove|list")
+	accountID := flag.String("account-id", "", "Vault account ID for -vault-cmd=add/remove")
+	accountUser := flag.String("account-user", "", "ETC user ID for -vault-cmd=add (password from "+accountPasswordEnv+" or stdin)")
+	accountRefs := flag.String("account-refs", "", "Vault account IDs to scrape in CLI mode, comma separated")
 
 	// サービス管理フラグ
 	serviceCmd := flag.String("service", "", "Service command: install|uninstall|start|stop|restart|status")
@@ -67,22 +109,61 @@ func main() {
 		return
 	}
 
+	retentionPolicy := retention.Policy{
+		KeepSessions:  *keepSessions,
+		MaxAge:        time.Duration(*keepDays) * 24 * time.Hour,
+		MaxBytes:      *maxDownloadMB << 20,
+		CompressAfter: time.Duration(*compressDays) * 24 * time.Hour,
+	}
+
+	// 古いセッションの削除・圧縮
+	if *pruneMode {
+		runPrune(logger, *downloadPath, retentionPolicy, *dryRun)
+		return
+	}
+
+	vaultConfig := vault.DefaultConfig(*vaultPath, *vaultKey, *p2pCredsFile)
+
+	// アカウント保管庫の操作
+	if *vaultCmd != "" {
+		runVaultCommand(vaultConfig, *vaultCmd, *accountID, *accountUser, *scraperType)
+		return
+	}
+
+	// TCPのgRPCサーバーの待ち受けアドレス・TLS・認証
+	listen := server.ListenConfig{
+		Listen:       *grpcListen,
+		CertFile:     *tlsCert,
+		KeyFile:      *tlsKey,
+		ClientCAFile: *tlsClientCA,
+		TokenFile:    *authTokens,
+	}
+
 	// サービスコマンド
 	if *serviceCmd != "" {
 		prg := &myservice.Program{
 			Logger:         logger,
 			GRPCPort:       *grpcPort,
+			GRPCListen:     listen,
 			DownloadPath:   *downloadPath,
 			Headless:       *headless,
+			KeepOriginal:   *keepOriginal,
+			Parallel:       *parallel,
 			Version:        Version,
 			AutoUpdate:     *autoUpdate,
 			UpdateInterval: *updateInterval,
 			// P2P settings - service runs in P2P mode by default
-			P2PMode:      true,
-			P2PURL:       *p2pURL,
-			P2PAPIKey:    *p2pAPIKey,
-			P2PAppName:   *p2pAppName,
-			P2PCredsFile: *p2pCredsFile,
+			P2PMode:       true,
+			P2PURL:        *p2pURL,
+			P2PAPIKey:     *p2pAPIKey,
+			P2PAppName:    *p2pAppName,
+			P2PCredsFile:  *p2pCredsFile,
+			P2PMaxPeers:   *p2pMaxPeers,
+			P2PPolicy:     *p2pPolicy,
+			VaultPath:     *vaultPath,
+			VaultKeyFile:  *vaultKey,
+			SchedulesFile: *schedulesFile,
+			Retention:     retentionPolicy,
 		}
 
 		if err := myservice.RunServiceCommand(*serviceCmd, prg, logger); err != nil {
@@ -93,8 +174,8 @@ func main() {
 
 	// サービスとして起動されているか確認
 	if isRunningAsService() {
-		runAsService(logger, *grpcPort, *downloadPath, *headless, *autoUpdate, *updateInterval,
-			*p2pURL, *p2pAPIKey, *p2pAppName, *p2pCredsFile)
+		runAsService(logger, *grpcPort, listen, *downloadPath, *headless, *keepOriginal, *parallel, *autoUpdate, *updateInterval,
+			*p2pURL, *p2pAPIKey, *p2pAppName, *p2pCredsFile, *p2pMaxPeers, *p2pPolicy, *vaultPath, *vaultKey, *schedulesFile, retentionPolicy)
 		return
 	}
 
@@ -104,6 +185,9 @@ func main() {
 		return
 	}
 
+	// account_ref / -account-refs は保管庫から解決する
+	accountVault := openVault(vaultConfig, logger)
+
 	// P2Pモード
 	if *p2pMode {
 		apiKey := *p2pAPIKey
@@ -125,18 +209,18 @@ func main() {
 				log.Fatal("Failed to obtain API key")
 			}
 		}
-		runP2PMode(logger, *p2pURL, apiKey, *p2pAppName, *downloadPath, *headless)
+		runP2PMode(logger, *p2pURL, apiKey, *p2pAppName, *p2pMaxPeers, *p2pPolicy, accountVault, *downloadPath, *headless, *keepOriginal, *parallel)
 		return
 	}
 
 	// gRPCモード
 	if *grpcMode {
-		runGRPCServerWithAutoUpdate(logger, *grpcPort, *downloadPath, *headless, *autoUpdate, *updateInterval)
+		runGRPCServerWithAutoUpdate(logger, *grpcPort, listen, accountVault, *downloadPath, *headless, *keepOriginal, *parallel, *autoUpdate, *updateInterval)
 		return
 	}
 
 	// CLIモード（従来の動作）
-	runCLIMode(logger, *accountsFlag, *downloadPath, *headless)
+	runCLIMode(logger, *accountsFlag, *accountRefs, accountVault, *scraperType, *downloadPath, *newRecords, *headless, *keepOriginal, *parallel, *fromDate, *toDate, *lastMonths)
 }
 
 // printVersion prints version information
@@ -152,22 +236,31 @@ func isRunningAsService() bool {
 }
 
 // runAsService runs the application as a Windows service
-func runAsService(logger *log.Logger, port, downloadPath string, headless, autoUpdate bool, updateInterval string,
-	p2pURL, p2pAPIKey, p2pAppName, p2pCredsFile string) {
+func runAsService(logger *log.Logger, port string, listen server.ListenConfig, downloadPath string, headless, keepOriginal bool, parallel int, autoUpdate bool, updateInterval string,
+	p2pURL, p2pAPIKey, p2pAppName, p2pCredsFile string, p2pMaxPeers int, p2pPolicy, vaultPath, vaultKey, schedulesFile string, retentionPolicy retention.Policy) {
 	prg := &myservice.Program{
 		Logger:         logger,
 		GRPCPort:       port,
+		GRPCListen:     listen,
 		DownloadPath:   downloadPath,
 		Headless:       headless,
+		KeepOriginal:   keepOriginal,
+		Parallel:       parallel,
 		Version:        Version,
 		AutoUpdate:     autoUpdate,
 		UpdateInterval: updateInterval,
 		// P2P settings - service runs in P2P mode by default
-		P2PMode:      true,
-		P2PURL:       p2pURL,
-		P2PAPIKey:    p2pAPIKey,
-		P2PAppName:   p2pAppName,
-		P2PCredsFile: p2pCredsFile,
+		P2PMode:       true,
+		P2PURL:        p2pURL,
+		P2PAPIKey:     p2pAPIKey,
+		P2PAppName:    p2pAppName,
+		P2PCredsFile:  p2pCredsFile,
+		P2PMaxPeers:   p2pMaxPeers,
+		P2PPolicy:     p2pPolicy,
+		VaultPath:     vaultPath,
+		VaultKeyFile:  vaultKey,
+		SchedulesFile: schedulesFile,
+		Retention:     retentionPolicy,
 	}
 
 	if err := myservice.RunServiceCommand("run", prg, logger); err != nil {
@@ -176,7 +269,7 @@ func runAsService(logger *log.Logger, port, downloadPath string, headless, autoU
 }
 
 // runGRPCServerWithAutoUpdate runs gRPC server with auto-update support
-func runGRPCServerWithAutoUpdate(logger *log.Logger, port, downloadPath string, headless, autoUpdate bool, updateInterval string) {
+func runGRPCServerWithAutoUpdate(logger *log.Logger, port string, listen server.ListenConfig, accountVault *vault.Vault, downloadPath string, headless, keepOriginal bool, parallel int, autoUpdate bool, updateInterval string) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
@@ -210,7 +303,7 @@ func runGRPCServerWithAutoUpdate(logger *log.Logger, port, downloadPath string,
 	}
 
 	// Start gRPC server
-	server.RunGRPCServer(logger, port, downloadPath, headless)
+	server.RunGRPCServer(logger, port, listen, accountVault, downloadPath, headless, keepOriginal, parallel)
 }
 
 // runUpdateCheck checks for updates and prints the result
@@ -232,52 +325,110 @@ func runUpdateCheck(logger *log.Logger) {
 }
 
 // runCLIMode runs the scraper in CLI mode
-func runCLIMode(logger *log.Logger, accountsFlag, downloadPath string, headless bool) {
+func runCLIMode(logger *log.Logger, accountsFlag, accountRefs string, accountVault *vault.Vault, scraperType, downloadPath, newRecordsFile string, headless, keepOriginal bool, parallel int, fromDate, toDate string, lastMonths int) {
 	accounts := parseAccounts(accountsFlag)
+	refs := parseAccountRefs(accountRefs)
 
-	if len(accounts) == 0 {
+	if len(accounts) == 0 && len(refs) == 0 {
 		log.Fatal("Usage: etc-scraper -accounts=user1:pass1,user2:pass2\n" +
 			"Or set ETC_CORP_ACCOUNTS=user1:pass1,user2:pass2\n" +
 			"Or set ETC_CORP_ACCOUNTS=[\"user1:pass1\",\"user2:pass2\"]\n" +
+			"Or use vault accounts: etc-scraper -account-refs=id1,id2 (see -vault-cmd)\n" +
 			"Or run as gRPC server: etc-scraper -grpc -port=50051")
 	}
 
-	logger.Printf("Found %d account(s) to process", len(accounts))
+	// 利用期間とスクレイパーは全アカウント共通
+	list := make([]engine.Account, 0, len(accounts)+len(refs))
+	for _, acc := range accounts {
+		list = append(list, engine.Account{
+			ScraperType: scraperType,
+			UserID:      acc.UserID,
+			Password:    acc.Password,
+			FromDate:    fromDate,
+			ToDate:      toDate,
+			LastMonths:  lastMonths,
+		})
+	}
+	// 保管庫のアカウントは登録時のスクレイパーを使う
+	for _, ref := range refs {
+		list = append(list, engine.Account{
+			AccountRef: ref,
+			FromDate:   fromDate,
+			ToDate:     toDate,
+			LastMonths: lastMonths,
+		})
+	}
+
+	logger.Printf("Found %d account(s) to process (parallel: %d)", len(list), parallel)
+
+	// Ctrl+Cで実行中のスクレイピングも中断する
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
 
-	sessionFolder := filepath.Join(downloadPath, time.Now().Format("20060102_150405"))
-	if err := os.MkdirAll(sessionFolder, 0755); err != nil {
-		log.Fatalf("Failed to create session folder: %v", err)
+	pool := scrapers.NewBrowserPool(parallel, headless, logger)
+	defer pool.Close()
+
+	// 前回までに取得済みの明細はダウンロードフォルダに記録する
+	records := dedup.NewStore(filepath.Join(downloadPath, dedup.StoreFile), logger)
+
+	// CLIの実行も履歴データベースに記録する
+	jobManager := jobs.NewManager("", logger)
+	if hist := server.OpenHistory(downloadPath, jobManager, logger); hist != nil {
+		defer hist.Close()
 	}
-	logger.Printf("Session folder: %s", sessionFolder)
 
-	successCount := 0
-	for i, acc := range accounts {
-		logger.Printf("=== Processing account %d/%d: %s ===", i+1, len(accounts), acc.UserID)
+	eng := &engine.Engine{
+		Jobs:         jobManager,
+		Pool:         pool,
+		Vault:        accountVault,
+		Records:      records,
+		DownloadPath: downloadPath,
+		Headless:     headless,
+		KeepOriginal: keepOriginal,
+		Logger:       logger,
+	}
+	eng.Jobs.Parallel = parallel
 
-		config := &scrapers.ScraperConfig{
-			UserID:       acc.UserID,
-			Password:     acc.Password,
-			DownloadPath: sessionFolder,
-			Headless:     headless,
-			Timeout:      60 * time.Second,
-		}
+	job, err := eng.Run(ctx, list, engine.Hooks{})
+	if err != nil {
+		log.Fatalf("Failed to start: %v", err)
+	}
 
-		if err := processETCAccount(config, logger); err != nil {
-			logger.Printf("ERROR: Failed to process account %s: %v", acc.UserID, err)
-			continue
+	logger.Printf("=== Complete: %d/%d accounts succeeded ===", job.SuccessCount(), len(job.Accounts))
+	for _, acc := range job.Accounts {
+		if acc.FilePath != "" {
+			logger.Printf("  %s: %s", acc.UserID, acc.FilePath)
 		}
+	}
+	logger.Printf("CSV files saved to: %s", job.SessionFolder)
 
-		successCount++
-		logger.Printf("SUCCESS: Account %s completed", acc.UserID)
+	if newRecordsFile != "" {
+		writeNewRecords(logger, records, job, newRecordsFile)
+	}
+}
 
-		if i < len(accounts)-1 {
-			logger.Println("Waiting before next account...")
-			time.Sleep(2 * time.Second)
+// writeNewRecords writes the records first downloaded by the job to a CSV file
+func writeNewRecords(logger *log.Logger, records *dedup.Store, job *jobs.Job, path string) {
+	var batches []dedup.Batch
+	total := 0
+	for _, acc := range job.Accounts {
+		if acc.FilePath == "" {
+			continue
 		}
+		fresh := records.Filter(acc.UserID, job.ID, server.ParseRecords(acc.FilePath, logger))
+		batches = append(batches, dedup.Batch{UserID: acc.UserID, JobID: job.ID, FilePath: acc.FilePath, Records: fresh})
+		total += len(fresh)
 	}
 
-	logger.Printf("=== Complete: %d/%d accounts succeeded ===", successCount, len(accounts))
-	logger.Printf("CSV files saved to: %s", sessionFolder)
+	f, err := os.Create(path)
+	if err != nil {
+		log.Fatalf("Failed to write new records: %v", err)
+	}
+	defer f.Close()
+	if err := dedup.WriteCSV(f, batches); err != nil {
+		log.Fatalf("Failed to write new records: %v", err)
+	}
+	logger.Printf("%d new record(s) written to: %s", total, path)
 }
 
 // parseAccounts parses account information from flag or environment variable
@@ -319,6 +470,17 @@ func parseAccounts(flagValue string) []scrapers.Account {
 	return accounts
 }
 
+// parseAccountRefs parses comma separated vault account IDs
+func parseAccountRefs(s string) []string {
+	var refs []string
+	for _, ref := range strings.Split(s, ",") {
+		if ref = strings.TrimSpace(ref); ref != "" {
+			refs = append(refs, ref)
+		}
+	}
+	return refs
+}
+
 // parseAccountString parses a single account string "user:pass"
 func parseAccountString(s string) *scrapers.Account {
 	s = strings.TrimSpace(s)
@@ -337,52 +499,18 @@ func parseAccountString(s string) *scrapers.Account {
 	}
 }
 
-// processETCAccount processes a single ETC account
-func processETCAccount(config *scrapers.ScraperConfig, logger *log.Logger) error {
-	scraper, err := scrapers.NewETCScraper(config, logger)
-	if err != nil {
-		return err
-	}
-	defer scraper.Close()
-
-	if err := scraper.Initialize(); err != nil {
-		return err
-	}
-
-	if err := scraper.Login(); err != nil {
-		return err
-	}
-
-	csvPath, err := scraper.Download()
-	if err != nil {
-		return err
-	}
-
-	// ファイル名にアカウント名を付与
-	newPath := filepath.Join(config.DownloadPath, config.UserID+"_"+filepath.Base(csvPath))
-	if csvPath != newPath {
-		if err := os.Rename(csvPath, newPath); err != nil {
-			logger.Printf("Warning: could not rename file: %v", err)
-		}
-	}
-
-	return nil
-}
-
 // p2pEventHandler implements p2p.ClientEventHandler
 type p2pEventHandler struct {
-	client       *p2p.Client
-	logger       *log.Logger
-	downloadPath string
-	headless     bool
+	client *p2p.Client
+	logger *log.Logger
 }
 
-func (h *p2pEventHandler) OnP2PConnected() {
-	h.logger.Println("Browser connected via WebRTC!")
+func (h *p2pEventHandler) OnP2PConnected(peerID string) {
+	h.logger.Printf("Browser connected via WebRTC! (peer %s, %d active)", peerID, len(h.client.Peers()))
 }
 
-func (h *p2pEventHandler) OnP2PDisconnected() {
-	h.logger.Println("Browser disconnected")
+func (h *p2pEventHandler) OnP2PDisconnected(peerID string) {
+	h.logger.Printf("Browser disconnected (peer %s)", peerID)
 }
 
 func (h *p2pEventHandler) OnP2PMessage(data []byte) {
@@ -395,16 +523,44 @@ func (h *p2pEventHandler) OnP2PError(err error) {
 }
 
 // runP2PMode runs as P2P client connected to signaling server
-func runP2PMode(logger *log.Logger, wsURL, apiKey, appName, downloadPath string, headless bool) {
+func runP2PMode(logger *log.Logger, wsURL, apiKey, appName string, maxPeers int, policyFile string, accountVault *vault.Vault, downloadPath string, headless, keepOriginal bool, parallel int) {
 	logger.Printf("Starting P2P mode...")
 	logger.Printf("Signaling URL: %s", wsURL)
 	logger.Printf("App name: %s", appName)
 
+	policy, err := loadP2PPolicy(policyFile, logger)
+	if err != nil {
+		log.Fatalf("Failed to load P2P access policy: %v", err)
+	}
+
 	// イベントハンドラを作成（clientは後で設定）
-	handler := &p2pEventHandler{
-		logger:       logger,
-		downloadPath: downloadPath,
-		headless:     headless,
+	handler := &p2pEventHandler{logger: logger}
+
+	// ジョブの状態はダウンロードフォルダに保存
+	jobManager := jobs.NewManager(filepath.Join(downloadPath, jobs.StoreFile), logger)
+	jobManager.Parallel = parallel
+	hist := server.OpenHistory(downloadPath, jobManager, logger)
+	// 実行中のジョブをキャンセルしてから履歴データベースを閉じる
+	defer func() {
+		jobManager.Shutdown()
+		if hist != nil {
+			hist.Close()
+		}
+	}()
+
+	// ブラウザは全リクエストで共有し、同時に起動する数をparallelに制限する
+	pool := scrapers.NewBrowserPool(parallel, headless, logger)
+	defer pool.Close()
+
+	eng := &engine.Engine{
+		Jobs:         jobManager,
+		Pool:         pool,
+		Vault:        accountVault,
+		Records:      dedup.NewStore(filepath.Join(downloadPath, dedup.StoreFile), logger),
+		DownloadPath: downloadPath,
+		Headless:     headless,
+		KeepOriginal: keepOriginal,
+		Logger:       logger,
 	}
 
 	client := p2p.NewClient(&p2p.ClientConfig{
@@ -414,16 +570,20 @@ func runP2PMode(logger *log.Logger, wsURL, apiKey, appName, downloadPath string,
 		Capabilities: []string{"scrape", "etc"},
 		Logger:       logger,
 		Handler:      handler,
-		OnDataChannelReady: func(dc *webrtc.DataChannel) {
+		MaxPeers:     maxPeers,
+		Channels: map[string]p2p.ChannelHandler{
+			p2p.FileChannelLabel: server.FileChannel(downloadPath, policy, logger),
+		},
+		OnDataChannelReady: func(dc *webrtc.DataChannel, user p2p.BrowserIdentity) {
 			logger.Println("DataChannel ready, setting up gRPC-Web transport...")
-			setupGRPCWebTransport(dc, logger, downloadPath, headless)
+			setupGRPCWebTransport(dc, logger, eng, hist, policy, user)
 		},
 	})
 
 	// ハンドラにclientを設定
 	handler.client = client
 
-	// シグナリングサーバーに接続
+	// シグナリングサーバーに接続（以降の切断はクライアントが自動で再接続する）
 	ctx := context.Background()
 	if err := client.Connect(ctx); err != nil {
 		log.Fatalf("Failed to connect: %v", err)
@@ -441,133 +601,47 @@ func runP2PMode(logger *log.Logger, wsURL, apiKey, appName, downloadPath string,
 	logger.Println("Shutting down...")
 }
 
-// setupGRPCWebTransport sets up gRPC-Web handlers on the DataChannel
-func setupGRPCWebTransport(dc *webrtc.DataChannel, logger *log.Logger, downloadPath string, headless bool) {
+// loadP2PPolicy loads the P2P access policy; without a file every browser user
+// is a viewer, and access.AllowAll gives every user full access
+func loadP2PPolicy(path string, logger *log.Logger) (*access.Policy, error) {
+	switch path {
+	case "":
+		logger.Printf("No P2P access policy (-p2p-policy), browser users are limited to %s", access.RoleViewer)
+		return access.Default(), nil
+	case access.AllowAll:
+		logger.Println("WARNING: P2P access control disabled (-p2p-policy=" + access.AllowAll + "), every browser user has full access")
+		return nil, nil
+	}
+	policy, err := access.Load(path)
+	if err != nil {
+		return nil, err
+	}
+	logger.Printf("Loaded P2P access policy from %s (%d users, default role: %s)", path, len(policy.Users), policy.DefaultRole)
+	return policy, nil
+}
+
+// setupGRPCWebTransport serves the gRPC API on the DataChannel to the browser of user
+func setupGRPCWebTransport(dc *webrtc.DataChannel, logger *log.Logger, eng *engine.Engine, hist *history.DB, policy *access.Policy, user p2p.BrowserIdentity) {
 	transport := grpcweb.NewTransport(dc, nil)
 
 	// Register Server Reflection
 	grpcweb.RegisterReflection(transport)
 
-	// Register scraper.ETCScraper/Health handler
-	transport.RegisterHandler("/scraper.ETCScraper/Health", grpcweb.MakeHandler(
-		func(data []byte) (json.RawMessage, error) {
-			return data, nil
-		},
-		func(resp map[string]interface{}) ([]byte, error) {
-			return json.Marshal(resp)
-		},
-		func(ctx context.Context, req json.RawMessage) (map[string]interface{}, error) {
-			return map[string]interface{}{
-				"status":  "ok",
-				"version": server.Version,
-			}, nil
-		},
-	))
-
-	// Register scraper.ETCScraper/ScrapeMultiple handler
-	transport.RegisterHandler("/scraper.ETCScraper/ScrapeMultiple", grpcweb.MakeHandler(
-		func(data []byte) (*ScrapeRequest, error) {
-			var req ScrapeRequest
-			if err := json.Unmarshal(data, &req); err != nil {
-				return nil, err
-			}
-			return &req, nil
-		},
-		func(resp *ScrapeResponse) ([]byte, error) {
-			return json.Marshal(resp)
-		},
-		func(ctx context.Context, req *ScrapeRequest) (*ScrapeResponse, error) {
-			logger.Printf("Received ScrapeMultiple request with %d accounts", len(req.Accounts))
-
-			// Run scraping in background
-			go runScrapeJob(logger, req.Accounts, downloadPath, headless)
-
-			return &ScrapeResponse{
-				Message:      "Scraping started",
-				AccountCount: len(req.Accounts),
-			}, nil
-		},
-	))
-
-	// Register scraper.ETCScraper/GetDownloadedFiles handler
-	transport.RegisterHandler("/scraper.ETCScraper/GetDownloadedFiles", grpcweb.MakeHandler(
-		func(data []byte) (json.RawMessage, error) {
-			return data, nil
-		},
-		func(resp *FilesResponse) ([]byte, error) {
-			return json.Marshal(resp)
-		},
-		func(ctx context.Context, req json.RawMessage) (*FilesResponse, error) {
-			files, sessionFolder := getDownloadedFiles(downloadPath, logger)
-			return &FilesResponse{
-				SessionFolder: sessionFolder,
-				Files:         files,
-			}, nil
-		},
-	))
+	// TCPのgRPCサーバーと同じ実装をそのまま公開する
+	server.RegisterGRPCWeb(transport, &server.GRPCServer{
+		Logger:       logger,
+		DownloadPath: eng.DownloadPath,
+		Version:      Version,
+		Jobs:         eng.Jobs,
+		Engine:       eng,
+		History:      hist,
+	}, policy, user, logger)
 
 	// Start the transport
 	transport.Start()
 	logger.Println("gRPC-Web transport started")
 }
 
-// ScrapeRequest for gRPC-Web
-type ScrapeRequest struct {
-	Accounts []struct {
-		UserID   string `json:"userId"`
-		Password string `json:"password"`
-	} `json:"accounts"`
-}
-
-// ScrapeResponse for gRPC-Web
-type ScrapeResponse struct {
-	Message      string `json:"message"`
-	AccountCount int    `json:"accountCount"`
-}
-
-// FilesResponse for gRPC-Web
-type FilesResponse struct {
-	SessionFolder string                   `json:"sessionFolder"`
-	Files         []map[string]interface{} `json:"files"`
-}
-
-// runScrapeJob runs scraping in background
-func runScrapeJob(logger *log.Logger, accounts []struct {
-	UserID   string `json:"userId"`
-	Password string `json:"password"`
-}, downloadPath string, headless bool) {
-	sessionFolder := filepath.Join(downloadPath, time.Now().Format("20060102_150405"))
-	if err := os.MkdirAll(sessionFolder, 0755); err != nil {
-		logger.Printf("Failed to create session folder: %v", err)
-		return
-	}
-
-	successCount := 0
-	for i, acc := range accounts {
-		logger.Printf("Processing account %d/%d: %s", i+1, len(accounts), acc.UserID)
-
-		config := &scrapers.ScraperConfig{
-			UserID:       acc.UserID,
-			Password:     acc.Password,
-			DownloadPath: sessionFolder,
-			Headless:     headless,
-			Timeout:      60 * time.Second,
-		}
-
-		if err := processETCAccount(config, logger); err != nil {
-			logger.Printf("ERROR: %s: %v", acc.UserID, err)
-			continue
-		}
-		successCount++
-
-		if i < len(accounts)-1 {
-			time.Sleep(2 * time.Second)
-		}
-	}
-
-	logger.Printf("Scraping completed: %d/%d accounts succeeded", successCount, len(accounts))
-}
-
 // runAutoSetup performs OAuth setup and returns API key (for automatic setup during -p2p mode)
 func runAutoSetup(logger *log.Logger, serverURL, credsFile string) string {
 	logger.Printf("Starting automatic OAuth setup...")
@@ -623,47 +697,3 @@ func runP2PSetup(logger *log.Logger, serverURL, credsFile string) {
 	logger.Println("Now you can run P2P mode:")
 	logger.Printf("  ./etc-scraper.exe -p2p")
 }
-
-func getDownloadedFiles(downloadPath string, logger *log.Logger) ([]map[string]interface{}, string) {
-	entries, err := os.ReadDir(downloadPath)
-	if err != nil {
-		return nil, ""
-	}
-
-	var latestFolder string
-	for i := len(entries) - 1; i >= 0; i-- {
-		if entries[i].IsDir() {
-			latestFolder = entries[i].Name()
-			break
-		}
-	}
-
-	if latestFolder == "" {
-		return nil, ""
-	}
-
-	sessionPath := filepath.Join(downloadPath, latestFolder)
-	files, err := os.ReadDir(sessionPath)
-	if err != nil {
-		return nil, latestFolder
-	}
-
-	var result []map[string]interface{}
-	for _, f := range files {
-		if f.IsDir() {
-			continue
-		}
-		filePath := filepath.Join(sessionPath, f.Name())
-		content, err := os.ReadFile(filePath)
-		if err != nil {
-			continue
-		}
-		result = append(result, map[string]interface{}{
-			"filename": f.Name(),
-			"content":  string(content),
-			"size":     len(content),
-		})
-	}
-
-	return result, latestFolder
-}
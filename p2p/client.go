@@ -3,8 +3,10 @@ package p2p
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"math/rand"
 	"sync"
 	"time"
 
@@ -13,48 +15,92 @@ import (
 
 // ClientEventHandler handles P2P client events
 type ClientEventHandler interface {
-	OnP2PConnected()
-	OnP2PDisconnected()
+	OnP2PConnected(peerID string)
+	OnP2PDisconnected(peerID string)
 	OnP2PMessage(data []byte)
 	OnP2PError(err error)
 }
 
-// DataChannelReadyCallback is called when DataChannel is ready (for grpcweb transport setup)
-type DataChannelReadyCallback func(dc *webrtc.DataChannel)
+// DataChannelReadyCallback is called when DataChannel is ready (for grpcweb transport setup),
+// with the user of the browser that opened it
+type DataChannelReadyCallback func(dc *webrtc.DataChannel, user BrowserIdentity)
 
-// Client integrates SignalingClient and PeerConnection for P2P communication
+// ChannelHandler serves an extra data channel opened by the browser of user
+type ChannelHandler func(dc *webrtc.DataChannel, user BrowserIdentity)
+
+// Client integrates SignalingClient and PeerConnections for P2P communication.
+// Each browser offer gets its own peer connection, keyed by the signaling
+// requestId, with its own data channel. Once connected it stays connected:
+// when the signaling connection drops it reconnects with exponential backoff,
+// authenticates and registers again, reclaiming the previous app ID.
+// Established WebRTC connections are kept.
 type Client struct {
-	config            *ClientConfig
-	signaling         *SignalingClient
-	peer              *PeerConnection
-	logger            *log.Logger
-	handler           ClientEventHandler
-	dcReadyCallback   DataChannelReadyCallback
-	mu                sync.RWMutex
-	connected         bool
-	registered        bool
-	ctx               context.Context
-	cancel            context.CancelFunc
+	config          *ClientConfig
+	signaling       *SignalingClient
+	peers           map[string]*PeerConnection // by requestId
+	lastOffer       string                     // requestId of the latest offer
+	logger          *log.Logger
+	handler         ClientEventHandler
+	dcReadyCallback DataChannelReadyCallback
+	mu              sync.RWMutex
+	registered      bool
+	appID           string // last registered app ID, kept across reconnects
+	states          stateHub
+	ctx             context.Context
+	cancel          context.CancelFunc
 }
 
 // ClientConfig holds configuration for P2P Client
 type ClientConfig struct {
-	SignalingURL          string   // WebSocket URL (e.g., wss://example.com/ws/app)
-	APIKey                string   // API key for authentication
-	AppName               string   // Application name
-	Capabilities          []string // App capabilities
-	ICEServers            []webrtc.ICEServer
-	Logger                *log.Logger
-	Handler               ClientEventHandler       // Optional event handler
-	OnDataChannelReady    DataChannelReadyCallback // Called when DataChannel is ready
+	SignalingURL       string   // WebSocket URL (e.g., wss://example.com/ws/app)
+	APIKey             string   // API key for authentication
+	AppName            string   // Application name
+	Capabilities       []string // App capabilities
+	ICEServers         []webrtc.ICEServer
+	Logger             *log.Logger
+	Handler            ClientEventHandler       // Optional event handler
+	OnDataChannelReady DataChannelReadyCallback // Called when the DataChannel of a peer is ready
+	MaxPeers           int                      // browsers connected at the same time (default: 4)
+	// Channels handles extra data channels opened by a browser, by label
+	// (e.g. FileChannelLabel with ServeFiles)
+	Channels map[string]ChannelHandler
+
+	ReconnectMin    time.Duration // first reconnect delay (default: 1s)
+	ReconnectMax    time.Duration // maximum reconnect delay (default: 60s)
+	RegisterTimeout time.Duration // time allowed to authenticate and register (default: 30s)
 }
 
+// Defaults for ClientConfig
+const (
+	DefaultReconnectMin    = time.Second
+	DefaultReconnectMax    = 60 * time.Second
+	DefaultRegisterTimeout = 30 * time.Second
+)
+
+// errSignalingClosed is reported when the signaling connection closes
+var errSignalingClosed = errors.New("signaling connection closed")
+
 // NewClient creates a new P2P Client
 func NewClient(config *ClientConfig) *Client {
 	logger := config.Logger
 	if logger == nil {
 		logger = log.Default()
 	}
+	if config.ReconnectMin <= 0 {
+		config.ReconnectMin = DefaultReconnectMin
+	}
+	if config.ReconnectMax < config.ReconnectMin {
+		config.ReconnectMax = DefaultReconnectMax
+		if config.ReconnectMax < config.ReconnectMin {
+			config.ReconnectMax = config.ReconnectMin
+		}
+	}
+	if config.RegisterTimeout <= 0 {
+		config.RegisterTimeout = DefaultRegisterTimeout
+	}
+	if config.MaxPeers <= 0 {
+		config.MaxPeers = DefaultMaxPeers
+	}
 
 	return &Client{
 		config:          config,
@@ -64,10 +110,10 @@ func NewClient(config *ClientConfig) *Client {
 	}
 }
 
-// Connect connects to signaling server and waits for WebRTC connection
+// Connect connects and registers to the signaling server, then keeps the
+// connection alive in the background until ctx is cancelled or Close is called.
+// It returns an error if the first attempt fails.
 func (c *Client) Connect(ctx context.Context) (err error) {
-	c.logger.Printf("[DEBUG-CLI] Connect() ENTRY")
-
 	// Recover from panic
 	defer func() {
 		if r := recover(); r != nil {
@@ -76,44 +122,186 @@ func (c *Client) Connect(ctx context.Context) (err error) {
 		}
 	}()
 
-	c.logger.Printf("[DEBUG-CLI] Client.Connect() started, about to lock mutex")
+	c.mu.Lock()
+	c.ctx, c.cancel = context.WithCancel(ctx)
+	c.mu.Unlock()
 
+	lost, err := c.connectOnce(c.ctx, 1)
+	if err != nil {
+		c.states.publish(StateEvent{State: StateDisconnected, Err: err, Time: time.Now()})
+		return fmt.Errorf("failed to connect to signaling server: %w", err)
+	}
+
+	go c.keepConnected(lost)
+	return nil
+}
+
+// Run connects to the signaling server and keeps the connection alive until ctx
+// is cancelled, retrying failed attempts with backoff. It closes the client and
+// returns ctx.Err() when done.
+func (c *Client) Run(ctx context.Context) error {
 	c.mu.Lock()
-	c.logger.Printf("[DEBUG-CLI] Mutex locked, creating context")
 	c.ctx, c.cancel = context.WithCancel(ctx)
 	c.mu.Unlock()
-	c.logger.Printf("[DEBUG-CLI] Mutex unlocked")
 
-	c.logger.Printf("[DEBUG-CLI] Context created, creating SignalingClient...")
-	c.logger.Printf("[DEBUG-CLI] SignalingURL=%s", c.config.SignalingURL)
-	c.logger.Printf("[DEBUG-CLI] APIKey length=%d", len(c.config.APIKey))
+	c.keepConnected(nil)
+	c.Close()
+	return ctx.Err()
+}
+
+// State returns the current signaling connection state
+func (c *Client) State() StateEvent {
+	return c.states.state()
+}
 
-	// Create signaling client with clientEventAdapter as the event handler
-	c.signaling = NewSignalingClient(SignalingConfig{
+// Subscribe returns a channel receiving the current connection state and every
+// later change, and a function to stop receiving. Slow subscribers lose their
+// oldest buffered events, never the latest one.
+func (c *Client) Subscribe() (<-chan StateEvent, func()) {
+	return c.states.subscribe()
+}
+
+// keepConnected waits for the connection to be lost and reconnects with
+// exponential backoff until the client context is cancelled. A nil lost
+// channel means not connected yet: the first attempt is made immediately.
+func (c *Client) keepConnected(lost <-chan error) {
+	ctx := c.ctx
+	var delay time.Duration
+	var lastErr error
+	attempt := 0
+
+	for {
+		if lost != nil {
+			select {
+			case lastErr = <-lost:
+			case <-ctx.Done():
+				c.states.publish(StateEvent{State: StateDisconnected, Time: time.Now()})
+				return
+			}
+			c.logger.Printf("Signaling connection lost: %v", lastErr)
+			c.mu.Lock()
+			c.registered = false
+			c.mu.Unlock()
+			attempt, delay = 0, c.config.ReconnectMin
+		}
+
+		attempt++
+		if delay > 0 {
+			c.logger.Printf("Reconnecting to signaling server in %v (attempt %d)...", delay.Round(time.Millisecond), attempt)
+			c.states.publish(StateEvent{State: StateReconnecting, Attempt: attempt, Err: lastErr, Retry: delay, Time: time.Now()})
+			select {
+			case <-time.After(delay):
+			case <-ctx.Done():
+				c.states.publish(StateEvent{State: StateDisconnected, Err: lastErr, Time: time.Now()})
+				return
+			}
+		}
+
+		var err error
+		lost, err = c.connectOnce(ctx, attempt)
+		if err != nil {
+			if ctx.Err() != nil {
+				c.states.publish(StateEvent{State: StateDisconnected, Time: time.Now()})
+				return
+			}
+			c.logger.Printf("Signaling connection failed: %v", err)
+			lastErr = err
+			delay = c.nextDelay(delay)
+		}
+	}
+}
+
+// nextDelay doubles the reconnect delay up to ReconnectMax, with up to 20% jitter
+// so that many apps do not reconnect at the same moment after an outage
+func (c *Client) nextDelay(delay time.Duration) time.Duration {
+	if delay <= 0 {
+		return c.config.ReconnectMin
+	}
+	delay *= 2
+	if delay > c.config.ReconnectMax {
+		delay = c.config.ReconnectMax
+	}
+	return delay - time.Duration(rand.Int63n(int64(delay)/5+1))
+}
+
+// connectOnce dials the signaling server and waits until the app is registered.
+// It returns a channel receiving the error that ends the connection.
+func (c *Client) connectOnce(ctx context.Context, attempt int) (<-chan error, error) {
+	c.states.publish(StateEvent{State: StateConnecting, Attempt: attempt, Time: time.Now()})
+
+	c.mu.Lock()
+	appID := c.appID
+	c.mu.Unlock()
+
+	events := &signalingEventAdapter{
+		client:     c,
+		registered: make(chan string, 1),
+		failed:     make(chan error, 1),
+		lost:       make(chan error, 1),
+	}
+	sig := NewSignalingClient(SignalingConfig{
 		ServerURL:    c.config.SignalingURL,
 		APIKey:       c.config.APIKey,
 		AppName:      c.config.AppName,
 		Capabilities: c.config.Capabilities,
-		Handler:      &signalingEventAdapter{client: c},
+		AppID:        appID,
+		Handler:      events,
 	})
+	events.signaling = sig
 
-	c.logger.Printf("[DEBUG-CLI] SignalingClient created successfully")
-	c.logger.Printf("[DEBUG-CLI] About to call signaling.Connect()...")
+	// 登録直後に届くofferに応答できるよう、接続前に差し替える
+	c.mu.Lock()
+	old := c.signaling
+	c.signaling = sig
+	c.mu.Unlock()
+	if old != nil {
+		old.Close()
+	}
 
-	// Connect to signaling server
-	if err := c.signaling.Connect(c.ctx); err != nil {
-		c.logger.Printf("[DEBUG-CLI] signaling.Connect() failed: %v", err)
-		return fmt.Errorf("failed to connect to signaling server: %w", err)
+	if err := sig.Connect(ctx); err != nil {
+		return nil, err
 	}
 
-	c.logger.Printf("[DEBUG-CLI] signaling.Connect() succeeded")
-	c.logger.Printf("Connected to signaling server, waiting for browser connection...")
-	return nil
+	timer := time.NewTimer(c.config.RegisterTimeout)
+	defer timer.Stop()
+
+	select {
+	case id := <-events.registered:
+		if appID != "" && id != appID {
+			c.logger.Printf("App ID changed on re-registration: %s -> %s", appID, id)
+		}
+		c.states.publish(StateEvent{State: StateConnected, Attempt: attempt, AppID: id, Time: time.Now()})
+		return events.lost, nil
+	case err := <-events.failed:
+		sig.Close()
+		return nil, err
+	case err := <-events.lost:
+		return nil, err
+	case <-timer.C:
+		sig.Close()
+		return nil, fmt.Errorf("not registered within %v", c.config.RegisterTimeout)
+	case <-ctx.Done():
+		sig.Close()
+		return nil, ctx.Err()
+	}
 }
 
-// signalingEventAdapter adapts Client to EventHandler for SignalingClient
+// signalingEventAdapter adapts Client to EventHandler for one SignalingClient
+// connection and reports the outcome of that connection to connectOnce
 type signalingEventAdapter struct {
-	client *Client
+	client     *Client
+	signaling  *SignalingClient
+	registered chan string // app ID, once registered
+	failed     chan error  // authentication rejected
+	lost       chan error  // connection closed
+}
+
+// report sends err without blocking; only the first error of a channel matters
+func report(ch chan error, err error) {
+	select {
+	case ch <- err:
+	default:
+	}
 }
 
 func (a *signalingEventAdapter) OnAuthenticated(payload AuthOKPayload) {
@@ -122,8 +310,10 @@ func (a *signalingEventAdapter) OnAuthenticated(payload AuthOKPayload) {
 
 func (a *signalingEventAdapter) OnAuthError(payload AuthErrorPayload) {
 	a.client.logger.Printf("Auth error: %s", payload.Error)
+	err := fmt.Errorf("auth error: %s", payload.Error)
+	report(a.failed, err)
 	if a.client.handler != nil {
-		a.client.handler.OnP2PError(fmt.Errorf("auth error: %s", payload.Error))
+		a.client.handler.OnP2PError(err)
 	}
 }
 
@@ -131,41 +321,33 @@ func (a *signalingEventAdapter) OnAppRegistered(payload AppRegisteredPayload) {
 	a.client.logger.Printf("App registered: appId=%s", payload.AppID)
 	a.client.mu.Lock()
 	a.client.registered = true
+	a.client.appID = payload.AppID
 	a.client.mu.Unlock()
 
-	// Create WebRTC peer connection after registration
-	a.client.createPeerConnection()
-}
-
-func (a *signalingEventAdapter) OnOffer(sdp string, requestID string) {
-	a.client.logger.Printf("Received offer from browser (requestID: %s)", requestID)
-
-	a.client.mu.Lock()
-	peer := a.client.peer
-	a.client.mu.Unlock()
+	// 再登録時は既存のP2P接続を維持し、以後のシグナリングを新しい接続に向ける
+	a.client.setPeersSignaling(a.signaling)
 
-	// 既存の接続が閉じている場合は新しいPeerConnectionを作成
-	if peer == nil || peer.ConnectionState() == webrtc.PeerConnectionStateClosed ||
-		peer.ConnectionState() == webrtc.PeerConnectionStateFailed {
-		a.client.logger.Printf("Creating new peer connection...")
-		// 古い接続があればクリーンアップ
-		if peer != nil {
-			peer.Close()
-		}
-		a.client.createPeerConnection()
+	select {
+	case a.registered <- payload.AppID:
+	default:
 	}
+}
 
-	a.client.mu.RLock()
-	peer = a.client.peer
-	a.client.mu.RUnlock()
+func (a *signalingEventAdapter) OnOffer(sdp string, requestID string, from BrowserIdentity) {
+	a.client.logger.Printf("Received offer from browser (requestID: %s, userId: %s)", requestID, from.UserID)
 
-	if peer == nil {
-		a.client.logger.Printf("Failed to create peer connection")
+	peer, err := a.client.addPeer(requestID, from)
+	if err != nil {
+		a.client.logger.Printf("Refused offer %s: %v", requestID, err)
+		if a.client.handler != nil {
+			a.client.handler.OnP2PError(fmt.Errorf("refused offer %s: %w", requestID, err))
+		}
 		return
 	}
 
 	if err := peer.HandleOffer(sdp, requestID); err != nil {
 		a.client.logger.Printf("Failed to handle offer: %v", err)
+		a.client.removePeer(peer)
 		if a.client.handler != nil {
 			a.client.handler.OnP2PError(fmt.Errorf("failed to handle offer: %w", err))
 		}
@@ -177,13 +359,14 @@ func (a *signalingEventAdapter) OnAnswer(sdp string, appID string) {
 	a.client.logger.Printf("Received unexpected answer from appId=%s", appID)
 }
 
-func (a *signalingEventAdapter) OnICE(candidate json.RawMessage) {
-	if a.client.peer == nil {
-		a.client.logger.Printf("Received ICE candidate but peer not initialized")
+func (a *signalingEventAdapter) OnICE(candidate json.RawMessage, requestID string) {
+	peer := a.client.peerFor(requestID)
+	if peer == nil {
+		a.client.logger.Printf("Received ICE candidate for unknown peer (requestID: %s)", requestID)
 		return
 	}
 
-	if err := a.client.peer.AddICECandidate(candidate); err != nil {
+	if err := peer.AddICECandidate(candidate); err != nil {
 		a.client.logger.Printf("Failed to add ICE candidate: %v", err)
 	}
 }
@@ -199,114 +382,25 @@ func (a *signalingEventAdapter) OnConnected() {
 	a.client.logger.Printf("Signaling connected")
 }
 
+// OnDisconnected reports the lost connection to the reconnect loop. The browser
+// connection does not depend on signaling and stays open; its closing is
+// reported by the data channel.
 func (a *signalingEventAdapter) OnDisconnected() {
 	a.client.logger.Printf("Signaling disconnected")
-	a.client.mu.Lock()
-	a.client.connected = false
-	a.client.registered = false
-	a.client.mu.Unlock()
-
-	if a.client.handler != nil {
-		a.client.handler.OnP2PDisconnected()
-	}
-}
-
-// dataChannelEventAdapter adapts Client to DataChannelHandler
-type dataChannelEventAdapter struct {
-	client *Client
-}
-
-func (a *dataChannelEventAdapter) OnMessage(data []byte) {
-	if a.client.handler != nil {
-		a.client.handler.OnP2PMessage(data)
-	}
-}
-
-func (a *dataChannelEventAdapter) OnOpen() {
-	a.client.logger.Printf("P2P connection established!")
-	a.client.mu.Lock()
-	a.client.connected = true
-	a.client.mu.Unlock()
-
-	// Call DataChannelReady callback for grpcweb transport setup
-	if a.client.dcReadyCallback != nil {
-		a.client.mu.RLock()
-		peer := a.client.peer
-		a.client.mu.RUnlock()
-		if peer != nil {
-			if dc := peer.DataChannel(); dc != nil {
-				a.client.dcReadyCallback(dc)
-			}
-		}
-	}
-
-	if a.client.handler != nil {
-		a.client.handler.OnP2PConnected()
-	}
-}
-
-func (a *dataChannelEventAdapter) OnClose() {
-	a.client.logger.Printf("Data channel closed")
-	a.client.mu.Lock()
-	a.client.connected = false
-	a.client.mu.Unlock()
-
-	if a.client.handler != nil {
-		a.client.handler.OnP2PDisconnected()
-	}
-}
-
-func (c *Client) createPeerConnection() {
-	iceServers := c.config.ICEServers
-	if len(iceServers) == 0 {
-		iceServers = []webrtc.ICEServer{
-			{URLs: []string{"stun:stun.l.google.com:19302"}},
-		}
-	}
-
-	peer, err := NewPeerConnection(PeerConfig{
-		ICEServers:      iceServers,
-		SignalingClient: c.signaling,
-		Handler:         &dataChannelEventAdapter{client: c},
-	})
-	if err != nil {
-		c.logger.Printf("Failed to create peer connection: %v", err)
-		if c.handler != nil {
-			c.handler.OnP2PError(fmt.Errorf("failed to create peer connection: %w", err))
-		}
-		return
-	}
-
-	c.mu.Lock()
-	c.peer = peer
-	c.mu.Unlock()
+	report(a.lost, errSignalingClosed)
 }
 
-// SendMessage sends data through WebRTC data channel
+// SendMessage sends data to every connected browser
 func (c *Client) SendMessage(data []byte) error {
-	c.mu.RLock()
-	peer := c.peer
-	c.mu.RUnlock()
-
-	if peer == nil {
-		return fmt.Errorf("peer connection not initialized")
-	}
-	return peer.Send(data)
+	return c.broadcast(func(p *PeerConnection) error { return p.Send(data) })
 }
 
-// SendText sends text through WebRTC data channel
+// SendText sends text to every connected browser
 func (c *Client) SendText(text string) error {
-	c.mu.RLock()
-	peer := c.peer
-	c.mu.RUnlock()
-
-	if peer == nil {
-		return fmt.Errorf("peer connection not initialized")
-	}
-	return peer.SendText(text)
+	return c.broadcast(func(p *PeerConnection) error { return p.SendText(text) })
 }
 
-// SendJSON sends JSON data through WebRTC data channel
+// SendJSON sends JSON data to every connected browser
 func (c *Client) SendJSON(v interface{}) error {
 	data, err := json.Marshal(v)
 	if err != nil {
@@ -315,11 +409,24 @@ func (c *Client) SendJSON(v interface{}) error {
 	return c.SendMessage(data)
 }
 
-// IsConnected returns whether P2P connection is established
+// broadcast calls send for every open peer and returns the first error
+func (c *Client) broadcast(send func(*PeerConnection) error) error {
+	peers := c.openPeers()
+	if len(peers) == 0 {
+		return fmt.Errorf("no browser connected")
+	}
+	var first error
+	for _, peer := range peers {
+		if err := send(peer); err != nil && first == nil {
+			first = fmt.Errorf("peer %s: %w", peer.ID(), err)
+		}
+	}
+	return first
+}
+
+// IsConnected returns whether at least one browser is connected
 func (c *Client) IsConnected() bool {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
-	return c.connected
+	return len(c.openPeers()) > 0
 }
 
 // WaitForConnection waits for P2P connection with timeout
@@ -342,56 +449,42 @@ func (c *Client) WaitForConnection(timeout time.Duration) error {
 	return fmt.Errorf("connection timeout after %v", timeout)
 }
 
-// Close closes P2P connection
+// Close closes all P2P connections and the signaling connection
 func (c *Client) Close() error {
 	c.mu.Lock()
-	defer c.mu.Unlock()
-
 	if c.cancel != nil {
 		c.cancel()
 	}
+	peers := c.peers
+	c.peers = nil
+	signaling := c.signaling
+	c.signaling = nil
+	c.registered = false
+	c.mu.Unlock()
 
 	var errs []error
 
-	if c.peer != nil {
-		if err := c.peer.Close(); err != nil {
-			errs = append(errs, fmt.Errorf("peer close: %w", err))
+	for id, peer := range peers {
+		if err := peer.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("peer %s close: %w", id, err))
 		}
-		c.peer = nil
 	}
 
-	if c.signaling != nil {
-		if err := c.signaling.Close(); err != nil {
+	if signaling != nil {
+		if err := signaling.Close(); err != nil {
 			errs = append(errs, fmt.Errorf("signaling close: %w", err))
 		}
-		c.signaling = nil
 	}
 
-	c.connected = false
-	c.registered = false
-
 	if len(errs) > 0 {
 		return errs[0]
 	}
 	return nil
 }
 
-// GetAppID returns the registered app ID from signaling server
+// GetAppID returns the app ID of the last registration with the signaling server
 func (c *Client) GetAppID() string {
-	if c.signaling == nil {
-		return ""
-	}
-	return c.signaling.GetAppID()
-}
-
-// GetConnectionState returns WebRTC connection state
-func (c *Client) GetConnectionState() string {
 	c.mu.RLock()
-	peer := c.peer
-	c.mu.RUnlock()
-
-	if peer == nil {
-		return "not initialized"
-	}
-	return peer.ConnectionState().String()
+	defer c.mu.RUnlock()
+	return c.appID
 }
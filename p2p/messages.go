@@ -30,6 +30,7 @@ type AuthErrorPayload struct {
 type AppRegisterPayload struct {
 	Name         string   `json:"name"`
 	Capabilities []string `json:"capabilities"`
+	AppID        string   `json:"appId,omitempty"` // app ID to reclaim when re-registering
 }
 
 // AppRegisteredPayload response from successful registration
@@ -47,8 +48,16 @@ type AppStatusPayload struct {
 
 // OfferPayload for WebRTC offer
 type OfferPayload struct {
-	SDP         string `json:"sdp"`
-	TargetAppID string `json:"targetAppId,omitempty"` // Used when browser sends to app
+	SDP         string           `json:"sdp"`
+	TargetAppID string           `json:"targetAppId,omitempty"` // Used when browser sends to app
+	From        *BrowserIdentity `json:"from,omitempty"`        // Set by the signaling server when forwarding to the app
+}
+
+// BrowserIdentity is the authenticated user of a browser, as seen by the
+// signaling server (the same userId the browser got in auth_ok)
+type BrowserIdentity struct {
+	UserID string `json:"userId"`
+	Email  string `json:"email,omitempty"`
 }
 
 // AnswerPayload for WebRTC answer
@@ -0,0 +1,219 @@
+package p2p
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pion/webrtc/v4"
+)
+
+// DefaultMaxPeers is the number of browsers that can be connected at the same time
+const DefaultMaxPeers = 4
+
+// ErrTooManyPeers is returned for an offer when MaxPeers browsers are connected
+var ErrTooManyPeers = errors.New("too many browser connections")
+
+// PeerInfo describes a connected or connecting browser
+type PeerInfo struct {
+	ID        string          // signaling requestId of the browser's offer
+	User      BrowserIdentity // browser user reported by the signaling server
+	State     string          // WebRTC connection state
+	Open      bool            // data channel open
+	CreatedAt time.Time       // when the offer was received
+}
+
+// addPeer creates the peer connection answering the offer of requestID from the
+// browser of user. A new offer with the same requestID replaces the previous
+// peer of that browser. Closed and failed peers are dropped first; if MaxPeers
+// remain, the offer is refused.
+func (c *Client) addPeer(requestID string, user BrowserIdentity) (*PeerConnection, error) {
+	iceServers := c.config.ICEServers
+	if len(iceServers) == 0 {
+		iceServers = []webrtc.ICEServer{
+			{URLs: []string{"stun:stun.l.google.com:19302"}},
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var stale []*PeerConnection
+	if old, ok := c.peers[requestID]; ok {
+		delete(c.peers, requestID)
+		stale = append(stale, old)
+	}
+	for id, peer := range c.peers {
+		if peerDone(peer) {
+			delete(c.peers, id)
+			stale = append(stale, peer)
+		}
+	}
+	// pionのコールバックと競合しないよう、ロック外で閉じる
+	go closePeers(stale)
+
+	if len(c.peers) >= c.config.MaxPeers {
+		return nil, fmt.Errorf("%w (%d)", ErrTooManyPeers, c.config.MaxPeers)
+	}
+
+	// 追加のDataChannelにもブラウザのユーザーを渡す
+	channels := make(map[string]func(*webrtc.DataChannel), len(c.config.Channels))
+	for label, h := range c.config.Channels {
+		h := h
+		channels[label] = func(dc *webrtc.DataChannel) { h(dc, user) }
+	}
+
+	events := &dataChannelEventAdapter{client: c, id: requestID, user: user}
+	peer, err := NewPeerConnection(PeerConfig{
+		ID:              requestID,
+		User:            user,
+		ICEServers:      iceServers,
+		SignalingClient: c.signaling,
+		Handler:         events,
+		Channels:        channels,
+	})
+	if err != nil {
+		return nil, err
+	}
+	events.peer = peer
+
+	if c.peers == nil {
+		c.peers = make(map[string]*PeerConnection)
+	}
+	c.peers[requestID] = peer
+	c.lastOffer = requestID
+	return peer, nil
+}
+
+// peerFor returns the peer of requestID. Candidates sent without a requestId
+// go to the peer of the latest offer.
+func (c *Client) peerFor(requestID string) *PeerConnection {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if requestID == "" {
+		requestID = c.lastOffer
+	}
+	return c.peers[requestID]
+}
+
+// removePeer forgets peer unless it was already replaced, and closes it
+func (c *Client) removePeer(peer *PeerConnection) {
+	c.mu.Lock()
+	if c.peers[peer.ID()] == peer {
+		delete(c.peers, peer.ID())
+	}
+	c.mu.Unlock()
+
+	go peer.Close()
+}
+
+// openPeers returns the peers with an open data channel
+func (c *Client) openPeers() []*PeerConnection {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var open []*PeerConnection
+	for _, peer := range c.peers {
+		if peer.IsConnected() {
+			open = append(open, peer)
+		}
+	}
+	return open
+}
+
+// Peers returns the browsers currently connected or connecting, oldest first
+func (c *Client) Peers() []PeerInfo {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	list := make([]PeerInfo, 0, len(c.peers))
+	for id, peer := range c.peers {
+		list = append(list, PeerInfo{
+			ID:        id,
+			User:      peer.User(),
+			State:     peer.ConnectionState().String(),
+			Open:      peer.IsConnected(),
+			CreatedAt: peer.CreatedAt(),
+		})
+	}
+	sort.Slice(list, func(i, k int) bool { return list[i].CreatedAt.Before(list[k].CreatedAt) })
+	return list
+}
+
+// setPeersSignaling points all peers at the signaling client after a reconnect
+func (c *Client) setPeersSignaling(sc *SignalingClient) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for _, peer := range c.peers {
+		peer.SetSignalingClient(sc)
+	}
+}
+
+// peerDone reports whether the peer connection is closed or failed
+func peerDone(peer *PeerConnection) bool {
+	state := peer.ConnectionState()
+	return state == webrtc.PeerConnectionStateClosed || state == webrtc.PeerConnectionStateFailed
+}
+
+func closePeers(peers []*PeerConnection) {
+	for _, peer := range peers {
+		peer.Close()
+	}
+}
+
+// dataChannelEventAdapter adapts Client to DataChannelHandler for one peer
+type dataChannelEventAdapter struct {
+	client *Client
+	id     string
+	user   BrowserIdentity
+	peer   *PeerConnection
+
+	mu     sync.Mutex
+	opened bool
+	closed bool
+}
+
+func (a *dataChannelEventAdapter) OnMessage(data []byte) {
+	if a.client.handler != nil {
+		a.client.handler.OnP2PMessage(data)
+	}
+}
+
+func (a *dataChannelEventAdapter) OnOpen() {
+	a.client.logger.Printf("P2P connection established! (peer %s, userId: %s)", a.id, a.user.UserID)
+	a.mu.Lock()
+	a.opened = true
+	a.mu.Unlock()
+
+	// ピアごとに独立したDataChannelでgrpcweb transportを立ち上げる
+	if a.client.dcReadyCallback != nil {
+		if dc := a.peer.DataChannel(); dc != nil {
+			a.client.dcReadyCallback(dc, a.user)
+		}
+	}
+
+	if a.client.handler != nil {
+		a.client.handler.OnP2PConnected(a.id)
+	}
+}
+
+// OnClose is called when the data channel closes or the connection fails or
+// closes; the peer is removed and closed on the first call
+func (a *dataChannelEventAdapter) OnClose() {
+	a.mu.Lock()
+	if a.closed {
+		a.mu.Unlock()
+		return
+	}
+	a.closed = true
+	opened := a.opened
+	a.mu.Unlock()
+
+	a.client.logger.Printf("P2P connection closed (peer %s)", a.id)
+	a.client.removePeer(a.peer)
+	if opened && a.client.handler != nil {
+		a.client.handler.OnP2PDisconnected(a.id)
+	}
+}
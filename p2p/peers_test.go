@@ -0,0 +1,103 @@
+package p2p
+
+import (
+	"errors"
+	"io"
+	"log"
+	"testing"
+	"time"
+)
+
+func newTestClient(t *testing.T, maxPeers int) *Client {
+	t.Helper()
+	c := NewClient(&ClientConfig{MaxPeers: maxPeers, Logger: log.New(io.Discard, "", 0)})
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+// peerIDs returns the requestIds of the client's peers, oldest first
+func peerIDs(c *Client) []string {
+	var ids []string
+	for _, p := range c.Peers() {
+		ids = append(ids, p.ID)
+	}
+	return ids
+}
+
+func TestAddPeer(t *testing.T) {
+	c := newTestClient(t, 2)
+	alice := BrowserIdentity{UserID: "u1", Email: "alice@example.com"}
+	bob := BrowserIdentity{UserID: "u2"}
+
+	first, err := c.addPeer("r1", alice)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.addPeer("r2", bob); err != nil {
+		t.Fatal(err)
+	}
+	peers := c.Peers()
+	if len(peers) != 2 || peers[0].ID != "r1" || peers[0].User != alice || peers[1].User != bob || peers[0].Open {
+		t.Fatalf("peers = %+v", peers)
+	}
+	// requestIdのない候補は最後のofferのピアへ
+	if c.peerFor("r1") != first || c.peerFor("") != c.peerFor("r2") {
+		t.Error("peerFor returned the wrong peer")
+	}
+
+	// 上限に達したら拒否する
+	if _, err := c.addPeer("r3", bob); !errors.Is(err, ErrTooManyPeers) {
+		t.Fatalf("addPeer over MaxPeers: %v, want ErrTooManyPeers", err)
+	}
+
+	// 同じrequestIdのofferは置き換え（上限に数えない）
+	replaced, err := c.addPeer("r1", alice)
+	if err != nil {
+		t.Fatalf("replacing r1: %v", err)
+	}
+	if replaced == first || c.peerFor("r1") != replaced || len(c.Peers()) != 2 {
+		t.Errorf("r1 not replaced: %v", peerIDs(c))
+	}
+
+	// 置き換え済みのピアを削除しても新しいピアは残る
+	c.removePeer(first)
+	if c.peerFor("r1") != replaced {
+		t.Error("removing the replaced peer dropped its successor")
+	}
+
+	c.removePeer(c.peerFor("r2"))
+	if ids := peerIDs(c); len(ids) != 1 || ids[0] != "r1" {
+		t.Fatalf("peers after removal = %v", ids)
+	}
+	if _, err := c.addPeer("r3", bob); err != nil {
+		t.Errorf("addPeer after removal: %v", err)
+	}
+}
+
+func TestAddPeerDropsClosedPeers(t *testing.T) {
+	c := newTestClient(t, 1)
+	peer, err := c.addPeer("r1", BrowserIdentity{UserID: "u1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.addPeer("r2", BrowserIdentity{UserID: "u2"}); !errors.Is(err, ErrTooManyPeers) {
+		t.Fatalf("addPeer over MaxPeers: %v, want ErrTooManyPeers", err)
+	}
+
+	// 閉じたピアは上限に数えない
+	peer.Close()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		_, err := c.addPeer("r2", BrowserIdentity{UserID: "u2"})
+		if err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("closed peer still counted: %v", err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if ids := peerIDs(c); len(ids) != 1 || ids[0] != "r2" {
+		t.Errorf("peers = %v, want [r2]", ids)
+	}
+}
@@ -17,9 +17,9 @@ type EventHandler interface {
 	OnAuthenticated(payload AuthOKPayload)
 	OnAuthError(payload AuthErrorPayload)
 	OnAppRegistered(payload AppRegisteredPayload)
-	OnOffer(sdp string, requestID string)
+	OnOffer(sdp string, requestID string, from BrowserIdentity)
 	OnAnswer(sdp string, appID string)
-	OnICE(candidate json.RawMessage)
+	OnICE(candidate json.RawMessage, requestID string)
 	OnError(message string)
 	OnConnected()
 	OnDisconnected()
@@ -31,6 +31,7 @@ type SignalingConfig struct {
 	APIKey       string        // API key for authentication
 	AppName      string        // Application name
 	Capabilities []string      // App capabilities (e.g., ["print", "scrape"])
+	AppID        string        // App ID assigned by a previous registration, reclaimed if possible
 	Handler      EventHandler  // Event handler
 	PingInterval time.Duration // Ping interval (default: 30s)
 }
@@ -182,10 +183,10 @@ func (c *SignalingClient) SendAnswer(sdp string, requestID string) error {
 	return c.sendMessage(MsgTypeAnswer, payload, requestID)
 }
 
-// SendICE sends ICE candidate
-func (c *SignalingClient) SendICE(candidate json.RawMessage) error {
+// SendICE sends ICE candidate to the browser of requestID
+func (c *SignalingClient) SendICE(candidate json.RawMessage, requestID string) error {
 	payload := ICEPayload{Candidate: candidate}
-	return c.sendMessage(MsgTypeICE, payload, "")
+	return c.sendMessage(MsgTypeICE, payload, requestID)
 }
 
 func (c *SignalingClient) sendAuth() error {
@@ -198,6 +199,7 @@ func (c *SignalingClient) RegisterApp() error {
 	payload := AppRegisterPayload{
 		Name:         c.config.AppName,
 		Capabilities: c.config.Capabilities,
+		AppID:        c.config.AppID,
 	}
 	return c.sendMessage(MsgTypeAppRegister, payload, "")
 }
@@ -350,8 +352,12 @@ func (c *SignalingClient) handleMessage(data []byte) {
 	case MsgTypeOffer:
 		var payload OfferPayload
 		if err := json.Unmarshal(msg.Payload, &payload); err == nil {
+			var from BrowserIdentity
+			if payload.From != nil {
+				from = *payload.From
+			}
 			if c.config.Handler != nil {
-				c.config.Handler.OnOffer(payload.SDP, msg.RequestID)
+				c.config.Handler.OnOffer(payload.SDP, msg.RequestID, from)
 			}
 		}
 
@@ -367,7 +373,7 @@ func (c *SignalingClient) handleMessage(data []byte) {
 		var payload ICEPayload
 		if err := json.Unmarshal(msg.Payload, &payload); err == nil {
 			if c.config.Handler != nil {
-				c.config.Handler.OnICE(payload.Candidate)
+				c.config.Handler.OnICE(payload.Candidate, msg.RequestID)
 			}
 		}
 
@@ -0,0 +1,100 @@
+package p2p
+
+import (
+	"sync"
+	"time"
+)
+
+// ConnectionState is the state of the connection to the signaling server
+type ConnectionState int
+
+const (
+	StateDisconnected ConnectionState = iota // not connected (before Connect or after Close)
+	StateConnecting                          // dialing, authenticating and registering
+	StateConnected                           // registered, browsers can connect
+	StateReconnecting                        // connection lost or attempt failed, waiting to retry
+)
+
+func (s ConnectionState) String() string {
+	switch s {
+	case StateConnecting:
+		return "connecting"
+	case StateConnected:
+		return "connected"
+	case StateReconnecting:
+		return "reconnecting"
+	default:
+		return "disconnected"
+	}
+}
+
+// StateEvent is a change of the signaling connection state
+type StateEvent struct {
+	State   ConnectionState
+	Attempt int           // connection attempt since the last successful connection, from 1
+	AppID   string        // registered app ID (StateConnected)
+	Err     error         // why the connection was lost or the last attempt failed
+	Retry   time.Duration // wait before the next attempt (StateReconnecting)
+	Time    time.Time
+}
+
+// stateSubscriberBuffer is the number of events buffered per subscriber
+const stateSubscriberBuffer = 16
+
+// stateHub keeps the current state and fans state events out to subscribers
+type stateHub struct {
+	mu      sync.Mutex
+	current StateEvent
+	subs    map[chan StateEvent]struct{}
+}
+
+// publish records ev as the current state and sends it to all subscribers.
+// A subscriber that does not keep up loses its oldest buffered event.
+func (h *stateHub) publish(ev StateEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.current = ev
+	for ch := range h.subs {
+		select {
+		case ch <- ev:
+		default:
+			select {
+			case <-ch:
+			default:
+			}
+			ch <- ev
+		}
+	}
+}
+
+// subscribe returns a channel receiving the current state followed by every change,
+// and a function that unsubscribes and closes the channel
+func (h *stateHub) subscribe() (<-chan StateEvent, func()) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	ch := make(chan StateEvent, stateSubscriberBuffer)
+	ch <- h.current
+	if h.subs == nil {
+		h.subs = make(map[chan StateEvent]struct{})
+	}
+	h.subs[ch] = struct{}{}
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			h.mu.Lock()
+			defer h.mu.Unlock()
+			delete(h.subs, ch)
+			close(ch)
+		})
+	}
+}
+
+// state returns the current state
+func (h *stateHub) state() StateEvent {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.current
+}
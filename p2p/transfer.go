@@ -0,0 +1,357 @@
+package p2p
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+
+	"github.com/pion/webrtc/v4"
+)
+
+// FileChannelLabel is the label of the DataChannel a browser opens for file
+// transfers, next to the gRPC-Web channel.
+//
+// Protocol: the browser sends JSON text messages
+//
+//	{"type":"list","session":""}                    list the files of a session (latest if empty)
+//	{"type":"get","transferId":"t1","path":"...","encoding":"utf-8","offset":0,"prefixSha256":""}
+//	{"type":"cancel","transferId":"t1"}
+//
+// and receives JSON text messages ("list", "start", "end", "error") and binary
+// chunk frames: 1 byte transferId length, the transferId, the 8 byte big-endian
+// offset of the chunk, then the chunk data. "start" carries the size and the
+// SHA-256 of the whole file; "end" repeats them once the last chunk was sent.
+// To resume, send "get" again with the number of bytes received as offset and
+// the SHA-256 of those bytes as prefixSha256; if the prefix does not match,
+// the transfer restarts at offset 0 (see "start").
+const FileChannelLabel = "files"
+
+const (
+	// TransferChunkSize is the size of the data in a chunk frame
+	TransferChunkSize = 16 * 1024
+	// transferHighWater pauses sending while more bytes are queued on the channel
+	transferHighWater = 1024 * 1024
+	// transferLowWater resumes sending once the queue drained below it
+	transferLowWater = 256 * 1024
+	// transferProgressInterval is the number of chunks between progress reports
+	transferProgressInterval = 64
+)
+
+// FileInfo describes a file available for transfer
+type FileInfo struct {
+	Path string `json:"path"` // relative to the source, with forward slashes
+	Size int64  `json:"size"`
+}
+
+// FileSource provides the files served on a file transfer channel
+type FileSource interface {
+	// List returns the name of a session and its files; the latest session if session is empty
+	List(session string) (string, []FileInfo, error)
+	// ReadFile returns the contents of a listed file in the given encoding ("" for as stored)
+	ReadFile(path, encoding string) ([]byte, error)
+}
+
+// TransferProgress reports the progress of a file transfer
+type TransferProgress struct {
+	TransferID string
+	Path       string
+	Sent       int64 // bytes queued on the channel, including the resumed prefix
+	Size       int64
+	Done       bool
+	Err        error
+}
+
+// FileServerConfig configures ServeFiles
+type FileServerConfig struct {
+	Source     FileSource
+	Logger     *log.Logger
+	OnProgress func(TransferProgress) // optional
+	// Authorize is called before each "list" and "get" request with the
+	// request type; an error is sent to the browser instead (optional)
+	Authorize func(requestType string) error
+}
+
+// transferRequest is a message from the browser
+type transferRequest struct {
+	Type         string `json:"type"` // "list", "get" or "cancel"
+	TransferID   string `json:"transferId,omitempty"`
+	Session      string `json:"session,omitempty"`
+	Path         string `json:"path,omitempty"`
+	Encoding     string `json:"encoding,omitempty"`
+	Offset       int64  `json:"offset,omitempty"`
+	PrefixSHA256 string `json:"prefixSha256,omitempty"`
+}
+
+// transferMessage is a control message to the browser
+type transferMessage struct {
+	Type       string     `json:"type"` // "list", "start", "end" or "error"
+	TransferID string     `json:"transferId,omitempty"`
+	Session    string     `json:"session,omitempty"`
+	Files      []FileInfo `json:"files,omitempty"`
+	Path       string     `json:"path,omitempty"`
+	Size       int64      `json:"size"`
+	Offset     int64      `json:"offset"`
+	ChunkSize  int        `json:"chunkSize,omitempty"`
+	SHA256     string     `json:"sha256,omitempty"`
+	Message    string     `json:"message,omitempty"`
+}
+
+// fileServer serves one file transfer channel
+type fileServer struct {
+	dc     *webrtc.DataChannel
+	config FileServerConfig
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu        sync.Mutex
+	transfers map[string]*activeTransfer
+	low       chan struct{} // closed and replaced when the send queue drains
+}
+
+// activeTransfer is a running transfer; a new "get" with the same ID replaces it
+type activeTransfer struct {
+	cancel context.CancelFunc
+}
+
+// ServeFiles serves the files of config.Source on a DataChannel opened by the
+// browser with FileChannelLabel. Transfers stop when the channel closes.
+func ServeFiles(dc *webrtc.DataChannel, config FileServerConfig) {
+	if config.Logger == nil {
+		config.Logger = log.Default()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &fileServer{
+		dc:        dc,
+		config:    config,
+		ctx:       ctx,
+		cancel:    cancel,
+		transfers: make(map[string]*activeTransfer),
+		low:       make(chan struct{}),
+	}
+
+	dc.SetBufferedAmountLowThreshold(transferLowWater)
+	dc.OnBufferedAmountLow(func() {
+		s.mu.Lock()
+		close(s.low)
+		s.low = make(chan struct{})
+		s.mu.Unlock()
+	})
+	dc.OnMessage(s.handle)
+	dc.OnClose(cancel)
+}
+
+func (s *fileServer) handle(msg webrtc.DataChannelMessage) {
+	var req transferRequest
+	if !msg.IsString || json.Unmarshal(msg.Data, &req) != nil {
+		s.send(transferMessage{Type: "error", Message: "invalid request"})
+		return
+	}
+
+	if req.Type == "list" || req.Type == "get" {
+		if err := s.authorize(req.Type); err != nil {
+			s.config.Logger.Printf("File transfer %s refused: %v", req.Type, err)
+			s.send(transferMessage{Type: "error", TransferID: req.TransferID, Path: req.Path, Message: err.Error()})
+			return
+		}
+	}
+
+	switch req.Type {
+	case "list":
+		go s.list(req)
+	case "get":
+		if req.TransferID == "" || len(req.TransferID) > 255 {
+			s.send(transferMessage{Type: "error", TransferID: req.TransferID, Message: "transferId must be 1-255 bytes"})
+			return
+		}
+		ctx, cancel := context.WithCancel(s.ctx)
+		t := &activeTransfer{cancel: cancel}
+		s.mu.Lock()
+		if prev, ok := s.transfers[req.TransferID]; ok {
+			prev.cancel()
+		}
+		s.transfers[req.TransferID] = t
+		s.mu.Unlock()
+		go func() {
+			defer s.finish(req.TransferID, t)
+			s.transfer(ctx, req)
+		}()
+	case "cancel":
+		s.mu.Lock()
+		if t, ok := s.transfers[req.TransferID]; ok {
+			t.cancel()
+		}
+		s.mu.Unlock()
+	default:
+		s.send(transferMessage{Type: "error", TransferID: req.TransferID, Message: "unknown request type: " + req.Type})
+	}
+}
+
+func (s *fileServer) authorize(requestType string) error {
+	if s.config.Authorize == nil {
+		return nil
+	}
+	return s.config.Authorize(requestType)
+}
+
+func (s *fileServer) list(req transferRequest) {
+	session, files, err := s.config.Source.List(req.Session)
+	if err != nil {
+		s.send(transferMessage{Type: "error", Message: err.Error()})
+		return
+	}
+	s.send(transferMessage{Type: "list", Session: session, Files: files})
+}
+
+// transfer sends a file in chunks, pausing while the channel's send queue is
+// full. The whole file is held in memory for the transfer, as FileSource
+// returns it converted to the requested encoding; sources serve files of a few
+// MB (downloaded CSVs, screenshots), not arbitrarily large ones.
+func (s *fileServer) transfer(ctx context.Context, req transferRequest) {
+	id := req.TransferID
+
+	progress := TransferProgress{TransferID: id, Path: req.Path}
+	fail := func(err error) {
+		s.config.Logger.Printf("File transfer %s (%s) failed: %v", id, req.Path, err)
+		// キャンセル・置き換え時はブラウザ側が把握しているので通知しない
+		if ctx.Err() == nil {
+			s.send(transferMessage{Type: "error", TransferID: id, Path: req.Path, Message: err.Error()})
+		}
+		progress.Err = err
+		s.report(progress)
+	}
+
+	data, err := s.config.Source.ReadFile(req.Path, req.Encoding)
+	if err != nil {
+		fail(err)
+		return
+	}
+	size := int64(len(data))
+	sum := sha256.Sum256(data)
+	digest := hex.EncodeToString(sum[:])
+
+	offset, err := resumeOffset(data, req.Offset, req.PrefixSHA256)
+	if err != nil {
+		fail(err)
+		return
+	}
+	if offset != req.Offset {
+		s.config.Logger.Printf("File transfer %s: resumed prefix does not match, restarting", id)
+	}
+
+	s.config.Logger.Printf("File transfer %s: %s (%d bytes from offset %d)", id, req.Path, size, offset)
+	progress.Size = size
+	if err := s.send(transferMessage{
+		Type:       "start",
+		TransferID: id,
+		Path:       req.Path,
+		Size:       size,
+		Offset:     offset,
+		ChunkSize:  TransferChunkSize,
+		SHA256:     digest,
+	}); err != nil {
+		fail(err)
+		return
+	}
+
+	for chunks := 1; offset < size; chunks++ {
+		if err := s.waitQueue(ctx); err != nil {
+			fail(err)
+			return
+		}
+		end := offset + TransferChunkSize
+		if end > size {
+			end = size
+		}
+		if err := s.dc.Send(chunkFrame(id, offset, data[offset:end])); err != nil {
+			fail(err)
+			return
+		}
+		offset = end
+
+		if chunks%transferProgressInterval == 0 {
+			progress.Sent = offset
+			s.report(progress)
+		}
+	}
+
+	if err := s.send(transferMessage{Type: "end", TransferID: id, Path: req.Path, Size: size, Offset: size, SHA256: digest}); err != nil {
+		fail(err)
+		return
+	}
+	progress.Sent, progress.Done = size, true
+	s.report(progress)
+	s.config.Logger.Printf("File transfer %s completed", id)
+}
+
+// resumeOffset returns the offset to send data from for a request resuming at
+// offset: offset itself, or 0 if the SHA-256 of the bytes the browser already
+// received does not match data (the file changed since)
+func resumeOffset(data []byte, offset int64, prefixSHA256 string) (int64, error) {
+	if offset < 0 || offset > int64(len(data)) {
+		return 0, fmt.Errorf("offset %d out of range (size %d)", offset, len(data))
+	}
+	if offset > 0 && prefixSHA256 != "" {
+		prefix := sha256.Sum256(data[:offset])
+		if hex.EncodeToString(prefix[:]) != prefixSHA256 {
+			// 受信済み部分が一致しないので最初から送り直す
+			return 0, nil
+		}
+	}
+	return offset, nil
+}
+
+// waitQueue blocks while more than transferHighWater bytes are queued on the channel
+func (s *fileServer) waitQueue(ctx context.Context) error {
+	for {
+		s.mu.Lock()
+		low := s.low
+		s.mu.Unlock()
+
+		if s.dc.BufferedAmount() <= transferHighWater {
+			return nil
+		}
+		select {
+		case <-low:
+		case <-ctx.Done():
+			return fmt.Errorf("transfer cancelled")
+		}
+	}
+}
+
+// finish releases the transfer and forgets it unless it was replaced
+func (s *fileServer) finish(id string, t *activeTransfer) {
+	t.cancel()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.transfers[id] == t {
+		delete(s.transfers, id)
+	}
+}
+
+func (s *fileServer) send(msg transferMessage) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	return s.dc.SendText(string(data))
+}
+
+func (s *fileServer) report(p TransferProgress) {
+	if s.config.OnProgress != nil {
+		s.config.OnProgress(p)
+	}
+}
+
+// chunkFrame builds a binary chunk frame: id length, id, offset, data
+func chunkFrame(id string, offset int64, data []byte) []byte {
+	frame := make([]byte, 0, 1+len(id)+8+len(data))
+	frame = append(frame, byte(len(id)))
+	frame = append(frame, id...)
+	frame = binary.BigEndian.AppendUint64(frame, uint64(offset))
+	return append(frame, data...)
+}
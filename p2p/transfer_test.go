@@ -0,0 +1,58 @@
+package p2p
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"testing"
+)
+
+func TestChunkFrame(t *testing.T) {
+	frame := chunkFrame("t1", 1<<40+5, []byte("data"))
+	want := append([]byte{2, 't', '1'}, binary.BigEndian.AppendUint64(nil, 1<<40+5)...)
+	want = append(want, "data"...)
+	if !bytes.Equal(frame, want) {
+		t.Errorf("chunkFrame = %v, want %v", frame, want)
+	}
+
+	// 255バイトのIDと空のチャンク
+	id := string(bytes.Repeat([]byte("x"), 255))
+	frame = chunkFrame(id, 0, nil)
+	if len(frame) != 1+255+8 || frame[0] != 255 || string(frame[1:256]) != id {
+		t.Errorf("chunkFrame(255 byte id) = %v", frame)
+	}
+}
+
+func TestResumeOffset(t *testing.T) {
+	data := []byte("0123456789")
+	sum := func(b []byte) string {
+		h := sha256.Sum256(b)
+		return hex.EncodeToString(h[:])
+	}
+
+	for _, tt := range []struct {
+		name    string
+		offset  int64
+		prefix  string
+		want    int64
+		wantErr bool
+	}{
+		{"start", 0, "", 0, false},
+		{"start with prefix", 0, sum(nil), 0, false},
+		{"resume without prefix", 4, "", 4, false},
+		{"resume", 4, sum(data[:4]), 4, false},
+		{"resume at end", 10, sum(data), 10, false},
+		{"prefix mismatch", 4, sum([]byte("abcd")), 0, false},
+		{"invalid prefix", 4, "not-hex", 0, false},
+		{"negative offset", -1, "", 0, true},
+		{"offset past end", 11, "", 0, true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resumeOffset(data, tt.offset, tt.prefix)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("resumeOffset(%d) = %d, %v, want %d", tt.offset, got, err, tt.want)
+			}
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/pion/webrtc/v4"
 )
@@ -17,6 +18,9 @@ type DataChannelHandler interface {
 
 // PeerConnection wraps pion/webrtc peer connection
 type PeerConnection struct {
+	id              string
+	user            BrowserIdentity
+	createdAt       time.Time
 	pc              *webrtc.PeerConnection
 	dataChannel     *webrtc.DataChannel
 	signalingClient *SignalingClient
@@ -28,9 +32,14 @@ type PeerConnection struct {
 
 // PeerConfig configuration for peer connection
 type PeerConfig struct {
+	ID              string          // signaling requestId of the offer answered by this peer
+	User            BrowserIdentity // user of the browser that sent the offer
 	ICEServers      []webrtc.ICEServer
 	SignalingClient *SignalingClient
 	Handler         DataChannelHandler
+	// Channels handles the browser's extra data channels by label (e.g.
+	// FileChannelLabel); any other channel is the main channel passed to Handler
+	Channels map[string]func(*webrtc.DataChannel)
 }
 
 // NewPeerConnection creates a new WebRTC peer connection
@@ -53,6 +62,9 @@ func NewPeerConnection(config PeerConfig) (*PeerConnection, error) {
 	}
 
 	peer := &PeerConnection{
+		id:              config.ID,
+		user:            config.User,
+		createdAt:       time.Now(),
 		pc:              pc,
 		signalingClient: config.SignalingClient,
 		handler:         config.Handler,
@@ -70,8 +82,8 @@ func NewPeerConnection(config PeerConfig) (*PeerConnection, error) {
 			return
 		}
 
-		if peer.signalingClient != nil {
-			peer.signalingClient.SendICE(candidateJSON)
+		if sc := peer.signaling(); sc != nil {
+			sc.SendICE(candidateJSON, peer.RequestID())
 		}
 	})
 
@@ -89,6 +101,10 @@ func NewPeerConnection(config PeerConfig) (*PeerConnection, error) {
 
 	// Handle incoming data channels (for browser-initiated connections)
 	pc.OnDataChannel(func(dc *webrtc.DataChannel) {
+		if h, ok := config.Channels[dc.Label()]; ok {
+			h(dc)
+			return
+		}
 		peer.setupDataChannel(dc)
 	})
 
@@ -129,8 +145,8 @@ func (p *PeerConnection) HandleOffer(sdp string, requestID string) error {
 	}
 
 	// Send answer via signaling
-	if p.signalingClient != nil {
-		if err := p.signalingClient.SendAnswer(answer.SDP, requestID); err != nil {
+	if sc := p.signaling(); sc != nil {
+		if err := sc.SendAnswer(answer.SDP, requestID); err != nil {
 			return fmt.Errorf("failed to send answer: %w", err)
 		}
 	}
@@ -138,6 +154,42 @@ func (p *PeerConnection) HandleOffer(sdp string, requestID string) error {
 	return nil
 }
 
+// ID returns the peer ID given in PeerConfig
+func (p *PeerConnection) ID() string {
+	return p.id
+}
+
+// User returns the user of the browser given in PeerConfig
+func (p *PeerConnection) User() BrowserIdentity {
+	return p.user
+}
+
+// CreatedAt returns when the peer connection was created
+func (p *PeerConnection) CreatedAt() time.Time {
+	return p.createdAt
+}
+
+// RequestID returns the signaling requestId of the last offer handled
+func (p *PeerConnection) RequestID() string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.requestID
+}
+
+// SetSignalingClient replaces the signaling client used for answers and ICE
+// candidates, after the client reconnected to the signaling server
+func (p *PeerConnection) SetSignalingClient(sc *SignalingClient) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.signalingClient = sc
+}
+
+func (p *PeerConnection) signaling() *SignalingClient {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.signalingClient
+}
+
 // AddICECandidate adds an ICE candidate
 func (p *PeerConnection) AddICECandidate(candidateJSON json.RawMessage) error {
 	var candidate webrtc.ICECandidateInit
@@ -0,0 +1,132 @@
+package parser
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/japanese"
+)
+
+// Encoding identifies the character encoding of a downloaded file
+type Encoding string
+
+const (
+	EncodingUTF8     Encoding = "utf-8"
+	EncodingShiftJIS Encoding = "shift_jis"
+)
+
+// OriginalDir is the folder (inside the session folder) where original files are kept
+const OriginalDir = "original"
+
+var utf8BOM = []byte("\xef\xbb\xbf")
+
+// ParseEncoding parses an encoding name from a request ("" defaults to UTF-8)
+func ParseEncoding(name string) (Encoding, error) {
+	switch name {
+	case "", "utf-8", "utf8", "UTF-8":
+		return EncodingUTF8, nil
+	case "shift_jis", "sjis", "Shift_JIS", "cp932":
+		return EncodingShiftJIS, nil
+	}
+	return "", fmt.Errorf("unsupported encoding: %s", name)
+}
+
+// DetectEncoding reports whether data is UTF-8 or (otherwise) Shift_JIS
+func DetectEncoding(data []byte) Encoding {
+	if utf8.Valid(data) {
+		return EncodingUTF8
+	}
+	return EncodingShiftJIS
+}
+
+// ToUTF8 converts data to UTF-8, stripping a BOM if present
+func ToUTF8(data []byte) ([]byte, error) {
+	if DetectEncoding(data) == EncodingShiftJIS {
+		converted, err := japanese.ShiftJIS.NewDecoder().Bytes(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode Shift_JIS: %w", err)
+		}
+		data = converted
+	}
+	return bytes.TrimPrefix(data, utf8BOM), nil
+}
+
+// ToShiftJIS converts UTF-8 data to Shift_JIS (data already in Shift_JIS is returned as is)
+func ToShiftJIS(data []byte) ([]byte, error) {
+	if DetectEncoding(data) == EncodingShiftJIS {
+		return data, nil
+	}
+	converted, err := japanese.ShiftJIS.NewEncoder().Bytes(bytes.TrimPrefix(data, utf8BOM))
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode Shift_JIS: %w", err)
+	}
+	return converted, nil
+}
+
+// OriginalPath returns where the original of a normalized file is kept
+func OriginalPath(path string) string {
+	return filepath.Join(filepath.Dir(path), OriginalDir, filepath.Base(path))
+}
+
+// NormalizeFile rewrites the file at path as UTF-8 and returns the detected source encoding.
+// If keepOriginal is set, the original bytes are saved to OriginalPath(path).
+func NormalizeFile(path string, keepOriginal bool) (Encoding, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	enc := DetectEncoding(data)
+	if enc == EncodingUTF8 && !bytes.HasPrefix(data, utf8BOM) {
+		return enc, nil
+	}
+
+	converted, err := ToUTF8(data)
+	if err != nil {
+		return enc, err
+	}
+
+	if keepOriginal {
+		origPath := OriginalPath(path)
+		if err := os.MkdirAll(filepath.Dir(origPath), 0755); err != nil {
+			return enc, fmt.Errorf("failed to create original dir: %w", err)
+		}
+		if err := os.WriteFile(origPath, data, 0644); err != nil {
+			return enc, fmt.Errorf("failed to keep original: %w", err)
+		}
+	}
+
+	if err := os.WriteFile(path, converted, 0644); err != nil {
+		return enc, fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return enc, nil
+}
+
+// ReadFile reads a downloaded file in the requested encoding.
+// For Shift_JIS the kept original is preferred over re-encoding the UTF-8 copy.
+func ReadFile(path string, enc Encoding) ([]byte, error) {
+	return ReadFileFS(os.DirFS(filepath.Dir(path)), filepath.Base(path), enc)
+}
+
+// ReadFileFS is ReadFile for a file of fsys, such as a compressed session
+func ReadFileFS(fsys fs.FS, name string, enc Encoding) ([]byte, error) {
+	if enc == EncodingShiftJIS {
+		if orig, err := fs.ReadFile(fsys, path.Join(path.Dir(name), OriginalDir, path.Base(name))); err == nil {
+			return ToShiftJIS(orig)
+		}
+	}
+
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	if enc == EncodingShiftJIS {
+		return ToShiftJIS(data)
+	}
+	return ToUTF8(data)
+}
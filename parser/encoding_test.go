@@ -0,0 +1,130 @@
+package parser
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// sjisFixture is utf8Fixture as downloaded from etc-meisai.jp (Shift_JIS, CRLF)
+const (
+	utf8Fixture = "利用IC(至),通行料金\r\n東京,\"1,000\"\r\n"
+	sjisFixture = "\x97\x98\x97pIC(\x8e\x8a),\x92\xca\x8ds\x97\xbf\x8b\xe0\r\n\x93\x8c\x8b\x9e,\"1,000\"\r\n"
+)
+
+func TestParseEncoding(t *testing.T) {
+	for name, want := range map[string]Encoding{
+		"": EncodingUTF8, "utf8": EncodingUTF8, "UTF-8": EncodingUTF8,
+		"sjis": EncodingShiftJIS, "Shift_JIS": EncodingShiftJIS, "cp932": EncodingShiftJIS,
+	} {
+		if got, err := ParseEncoding(name); err != nil || got != want {
+			t.Errorf("ParseEncoding(%q) = %q, %v, want %q", name, got, err, want)
+		}
+	}
+	if _, err := ParseEncoding("euc-jp"); err == nil {
+		t.Error("ParseEncoding(euc-jp) succeeded")
+	}
+}
+
+func TestConvert(t *testing.T) {
+	if got := DetectEncoding([]byte(sjisFixture)); got != EncodingShiftJIS {
+		t.Errorf("DetectEncoding(sjis) = %q", got)
+	}
+	if got := DetectEncoding([]byte(utf8Fixture)); got != EncodingUTF8 {
+		t.Errorf("DetectEncoding(utf8) = %q", got)
+	}
+
+	for name, in := range map[string]string{"sjis": sjisFixture, "utf8": utf8Fixture, "bom": "\xef\xbb\xbf" + utf8Fixture} {
+		got, err := ToUTF8([]byte(in))
+		if err != nil || string(got) != utf8Fixture {
+			t.Errorf("ToUTF8(%s) = %q, %v", name, got, err)
+		}
+		got, err = ToShiftJIS([]byte(in))
+		if err != nil || string(got) != sjisFixture {
+			t.Errorf("ToShiftJIS(%s) = %q, %v", name, got, err)
+		}
+	}
+}
+
+func TestNormalizeFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "user1_meisai.csv")
+	if err := os.WriteFile(path, []byte(sjisFixture), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	enc, err := NormalizeFile(path, true)
+	if err != nil || enc != EncodingShiftJIS {
+		t.Fatalf("NormalizeFile = %q, %v", enc, err)
+	}
+	assertFile(t, path, utf8Fixture)
+	assertFile(t, OriginalPath(path), sjisFixture)
+
+	// 変換済みのファイルはそのまま（元ファイルも上書きしない）
+	enc, err = NormalizeFile(path, true)
+	if err != nil || enc != EncodingUTF8 {
+		t.Fatalf("second NormalizeFile = %q, %v", enc, err)
+	}
+	assertFile(t, path, utf8Fixture)
+	assertFile(t, OriginalPath(path), sjisFixture)
+
+	// 元ファイルを残さない場合・BOM付きUTF-8
+	other := filepath.Join(dir, "user2_meisai.csv")
+	os.WriteFile(other, []byte("\xef\xbb\xbf"+utf8Fixture), 0644)
+	if enc, err := NormalizeFile(other, false); err != nil || enc != EncodingUTF8 {
+		t.Fatalf("NormalizeFile(bom) = %q, %v", enc, err)
+	}
+	assertFile(t, other, utf8Fixture)
+	if _, err := os.Stat(OriginalPath(other)); !os.IsNotExist(err) {
+		t.Errorf("original of %s kept: %v", other, err)
+	}
+
+	if _, err := NormalizeFile(filepath.Join(dir, "missing.csv"), false); err == nil {
+		t.Error("NormalizeFile(missing) succeeded")
+	}
+}
+
+func TestReadFile(t *testing.T) {
+	dir := t.TempDir()
+	kept := filepath.Join(dir, "user1_meisai.csv")
+	os.WriteFile(kept, []byte(sjisFixture), 0644)
+	if _, err := NormalizeFile(kept, true); err != nil {
+		t.Fatal(err)
+	}
+	// 元ファイルが改変されていれば、UTF-8から再変換せずに元ファイルを返す
+	os.WriteFile(OriginalPath(kept), []byte(sjisFixture+"\x93\x8c\x8b\x9e\r\n"), 0644)
+
+	converted := filepath.Join(dir, "user2_meisai.csv")
+	os.WriteFile(converted, []byte(sjisFixture), 0644)
+	if _, err := NormalizeFile(converted, false); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		path string
+		enc  Encoding
+		want string
+	}{
+		{kept, EncodingUTF8, utf8Fixture},
+		{kept, EncodingShiftJIS, sjisFixture + "\x93\x8c\x8b\x9e\r\n"},
+		{converted, EncodingUTF8, utf8Fixture},
+		{converted, EncodingShiftJIS, sjisFixture},
+	} {
+		got, err := ReadFile(tt.path, tt.enc)
+		if err != nil || !bytes.Equal(got, []byte(tt.want)) {
+			t.Errorf("ReadFile(%s, %s) = %q, %v, want %q", filepath.Base(tt.path), tt.enc, got, err, tt.want)
+		}
+	}
+}
+
+func assertFile(t *testing.T, path, want string) {
+	t.Helper()
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != want {
+		t.Errorf("%s = %q, want %q", filepath.Base(path), got, want)
+	}
+}
@@ -0,0 +1,259 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/text/width"
+)
+
+// UsageRecord represents a single row of the ETC meisai CSV
+type UsageRecord struct {
+	EntryTime     time.Time `json:"entryTime"` // 利用年月日（自）+ 時刻（自）、入口情報がない場合はゼロ値
+	ExitTime      time.Time `json:"exitTime"`  // 利用年月日（至）+ 時刻（至）
+	EntryIC       string    `json:"entryIc"`
+	ExitIC        string    `json:"exitIc"`
+	VehicleClass  string    `json:"vehicleClass"`
+	CardNumber    string    `json:"cardNumber"`
+	OriginalToll  int       `json:"originalToll"` // 割引前料金
+	Discount      int       `json:"discount"`     // ETC割引額
+	Toll          int       `json:"toll"`         // 通行料金
+	VehicleNumber string    `json:"vehicleNumber"`
+	Note          string    `json:"note,omitempty"`
+}
+
+// column identifies a known meisai CSV column
+type column int
+
+const (
+	colEntryDate column = iota
+	colEntryTime
+	colExitDate
+	colExitTime
+	colEntryIC
+	colExitIC
+	colOriginalToll
+	colDiscount
+	colToll
+	colVehicleClass
+	colVehicleNumber
+	colCardNumber
+	colNote
+)
+
+// headerColumns maps normalized header names to columns
+var headerColumns = map[string]column{
+	"利用年月日(自)": colEntryDate,
+	"時刻(自)":    colEntryTime,
+	"利用年月日(至)": colExitDate,
+	"時刻(至)":    colExitTime,
+	"利用IC(自)":  colEntryIC,
+	"利用IC(至)":  colExitIC,
+	"割引前料金":    colOriginalToll,
+	"ETC割引額":   colDiscount,
+	"通行料金":     colToll,
+	"車種":       colVehicleClass,
+	"車両番号":     colVehicleNumber,
+	"ETCカード番号": colCardNumber,
+	"備考":       colNote,
+}
+
+// requiredColumns must be present in the header row
+var requiredColumns = []column{colExitDate, colExitTime, colExitIC, colToll, colCardNumber}
+
+// ParseMeisaiFile parses a downloaded meisai CSV file
+func ParseMeisaiFile(path string) ([]UsageRecord, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return ParseMeisai(bytes.NewReader(data))
+}
+
+// ParseMeisai parses meisai CSV content in Shift_JIS or UTF-8
+func ParseMeisai(r io.Reader) ([]UsageRecord, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV: %w", err)
+	}
+	if data, err = ToUTF8(data); err != nil {
+		return nil, err
+	}
+
+	cr := csv.NewReader(bytes.NewReader(data))
+	cr.FieldsPerRecord = -1
+	cr.LazyQuotes = true
+
+	header, err := cr.Read()
+	if err != nil {
+		if err == io.EOF {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read header: %w", err)
+	}
+
+	index := make(map[column]int)
+	for i, name := range header {
+		if col, ok := headerColumns[normalizeHeader(name)]; ok {
+			index[col] = i
+		}
+	}
+	for _, col := range requiredColumns {
+		if _, ok := index[col]; !ok {
+			return nil, fmt.Errorf("unexpected CSV header: %v", header)
+		}
+	}
+
+	var records []UsageRecord
+	line := 1
+	for {
+		row, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		line++
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if isBlankRow(row) {
+			continue
+		}
+
+		rec, err := parseRow(row, index)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		records = append(records, rec)
+	}
+
+	return records, nil
+}
+
+// parseRow converts a CSV row into a UsageRecord
+func parseRow(row []string, index map[column]int) (UsageRecord, error) {
+	field := func(col column) string {
+		i, ok := index[col]
+		if !ok || i >= len(row) {
+			return ""
+		}
+		return strings.TrimSpace(row[i])
+	}
+
+	var rec UsageRecord
+	var err error
+
+	if rec.EntryTime, err = parseDateTime(field(colEntryDate), field(colEntryTime)); err != nil {
+		return rec, fmt.Errorf("entry time: %w", err)
+	}
+	if rec.ExitTime, err = parseDateTime(field(colExitDate), field(colExitTime)); err != nil {
+		return rec, fmt.Errorf("exit time: %w", err)
+	}
+	if rec.ExitTime.IsZero() {
+		return rec, fmt.Errorf("missing exit date")
+	}
+	if rec.OriginalToll, err = parseAmount(field(colOriginalToll)); err != nil {
+		return rec, fmt.Errorf("original toll: %w", err)
+	}
+	if rec.Discount, err = parseAmount(field(colDiscount)); err != nil {
+		return rec, fmt.Errorf("discount: %w", err)
+	}
+	if rec.Toll, err = parseAmount(field(colToll)); err != nil {
+		return rec, fmt.Errorf("toll: %w", err)
+	}
+
+	rec.EntryIC = field(colEntryIC)
+	rec.ExitIC = field(colExitIC)
+	rec.VehicleClass = field(colVehicleClass)
+	rec.VehicleNumber = field(colVehicleNumber)
+	rec.CardNumber = field(colCardNumber)
+	rec.Note = field(colNote)
+	return rec, nil
+}
+
+// normalizeHeader folds full-width characters and strips spaces ("利用ＩＣ（自）" -> "利用IC(自)")
+func normalizeHeader(s string) string {
+	s = width.Fold.String(strings.TrimSpace(s))
+	return strings.ReplaceAll(s, " ", "")
+}
+
+// parseDateTime parses "YY/MM/DD" or "YYYY/MM/DD" with an optional "HH:MM[:SS]" time in local time
+func parseDateTime(date, clock string) (time.Time, error) {
+	if date == "" {
+		return time.Time{}, nil
+	}
+	date = width.Fold.String(date)
+	clock = width.Fold.String(clock)
+
+	parts := strings.Split(strings.ReplaceAll(date, "-", "/"), "/")
+	if len(parts) != 3 {
+		return time.Time{}, fmt.Errorf("invalid date %q", date)
+	}
+	var ymd [3]int
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid date %q", date)
+		}
+		ymd[i] = n
+	}
+	if ymd[0] < 100 {
+		ymd[0] += 2000
+	}
+
+	var hms [3]int
+	if clock != "" {
+		parts := strings.Split(clock, ":")
+		if len(parts) < 2 || len(parts) > 3 {
+			return time.Time{}, fmt.Errorf("invalid time %q", clock)
+		}
+		for i, p := range parts {
+			n, err := strconv.Atoi(p)
+			if err != nil {
+				return time.Time{}, fmt.Errorf("invalid time %q", clock)
+			}
+			hms[i] = n
+		}
+	}
+
+	return time.Date(ymd[0], time.Month(ymd[1]), ymd[2], hms[0], hms[1], hms[2], 0, time.Local), nil
+}
+
+// parseAmount parses a yen amount such as "1,230", "-200" or "▲200"
+func parseAmount(s string) (int, error) {
+	s = width.Fold.String(s)
+	s = strings.NewReplacer(",", "", "円", "", "¥", "", "\\", "").Replace(s)
+	if s == "" {
+		return 0, nil
+	}
+	negative := false
+	for _, prefix := range []string{"▲", "△", "-"} {
+		if strings.HasPrefix(s, prefix) {
+			s = strings.TrimPrefix(s, prefix)
+			negative = true
+			break
+		}
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+	if negative {
+		n = -n
+	}
+	return n, nil
+}
+
+func isBlankRow(row []string) bool {
+	for _, f := range row {
+		if strings.TrimSpace(f) != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,156 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseMeisai(t *testing.T) {
+	tests := []struct {
+		name    string
+		csv     string
+		want    []UsageRecord
+		wantErr string
+	}{
+		{
+			name: "standard header",
+			csv: "利用年月日(自),時刻(自),利用年月日(至),時刻(至),利用IC(自),利用IC(至),割引前料金,ETC割引額,通行料金,車種,車両番号,ETCカード番号,備考\n" +
+				"25/01/15,08:30,25/01/15,09:45,東京,横浜町田,\"1,230\",▲200,\"1,030\",普通車,品川300あ12-34,****1234,\n",
+			want: []UsageRecord{{
+				EntryTime:     time.Date(2025, 1, 15, 8, 30, 0, 0, time.Local),
+				ExitTime:      time.Date(2025, 1, 15, 9, 45, 0, 0, time.Local),
+				EntryIC:       "東京",
+				ExitIC:        "横浜町田",
+				OriginalToll:  1230,
+				Discount:      -200,
+				Toll:          1030,
+				VehicleClass:  "普通車",
+				VehicleNumber: "品川300あ12-34",
+				CardNumber:    "****1234",
+			}},
+		},
+		{
+			// 全角・空白入りの見出しと列の並び替え
+			name: "header aliases",
+			csv: " ETCカード番号 ,利用年月日（至）,時刻（至）,利用ＩＣ（至）,通行料金,備考\n" +
+				"****9999,2025/01/16,18:05:30,大井南,１，０００円,後日精算\n",
+			want: []UsageRecord{{
+				ExitTime:   time.Date(2025, 1, 16, 18, 5, 30, 0, time.Local),
+				ExitIC:     "大井南",
+				Toll:       1000,
+				CardNumber: "****9999",
+				Note:       "後日精算",
+			}},
+		},
+		{
+			name: "missing entry date and time",
+			csv: "利用年月日(自),時刻(自),利用年月日(至),時刻(至),利用IC(至),通行料金,ETCカード番号\n" +
+				",,25/01/17,10:00,川崎,500,****1234\n",
+			want: []UsageRecord{{
+				ExitTime:   time.Date(2025, 1, 17, 10, 0, 0, 0, time.Local),
+				ExitIC:     "川崎",
+				Toll:       500,
+				CardNumber: "****1234",
+			}},
+		},
+		{
+			name: "signed amounts",
+			csv: "利用年月日(至),時刻(至),利用IC(至),割引前料金,ETC割引額,通行料金,ETCカード番号\n" +
+				"25/01/18,10:00,川崎,-200,△50,\"-1,500\",****1234\n",
+			want: []UsageRecord{{
+				ExitTime:     time.Date(2025, 1, 18, 10, 0, 0, 0, time.Local),
+				ExitIC:       "川崎",
+				OriginalToll: -200,
+				Discount:     -50,
+				Toll:         -1500,
+				CardNumber:   "****1234",
+			}},
+		},
+		{
+			name: "blank and trailing rows",
+			csv: "利用年月日(至),時刻(至),利用IC(至),通行料金,ETCカード番号\n" +
+				",,,,\n" +
+				"25/01/19,07:00,厚木,800,****1234\n" +
+				" , ,\n" +
+				"\n",
+			want: []UsageRecord{{
+				ExitTime:   time.Date(2025, 1, 19, 7, 0, 0, 0, time.Local),
+				ExitIC:     "厚木",
+				Toll:       800,
+				CardNumber: "****1234",
+			}},
+		},
+		{
+			name: "header only",
+			csv:  "利用年月日(至),時刻(至),利用IC(至),通行料金,ETCカード番号\n",
+		},
+		{
+			name: "empty file",
+			csv:  "",
+		},
+		{
+			name:    "missing required column",
+			csv:     "利用年月日(至),時刻(至),利用IC(至),ETCカード番号\n25/01/19,07:00,厚木,****1234\n",
+			wantErr: "unexpected CSV header",
+		},
+		{
+			name:    "missing exit date",
+			csv:     "利用年月日(至),時刻(至),利用IC(至),通行料金,ETCカード番号\n,07:00,厚木,800,****1234\n",
+			wantErr: "line 2: missing exit date",
+		},
+		{
+			name:    "invalid amount",
+			csv:     "利用年月日(至),時刻(至),利用IC(至),通行料金,ETCカード番号\n25/01/19,07:00,厚木,abc,****1234\n",
+			wantErr: "line 2: toll",
+		},
+		{
+			name:    "invalid time",
+			csv:     "利用年月日(至),時刻(至),利用IC(至),通行料金,ETCカード番号\n25/01/19,7時,厚木,800,****1234\n",
+			wantErr: "line 2: exit time",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseMeisai(strings.NewReader(tt.csv))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d records, want %d: %+v", len(got), len(tt.want), got)
+			}
+			for i := range got {
+				if !got[i].EntryTime.Equal(tt.want[i].EntryTime) || !got[i].ExitTime.Equal(tt.want[i].ExitTime) {
+					t.Errorf("record %d times = %v, %v, want %v, %v", i, got[i].EntryTime, got[i].ExitTime, tt.want[i].EntryTime, tt.want[i].ExitTime)
+				}
+				g, w := got[i], tt.want[i]
+				g.EntryTime, g.ExitTime, w.EntryTime, w.ExitTime = time.Time{}, time.Time{}, time.Time{}, time.Time{}
+				if g != w {
+					t.Errorf("record %d = %+v, want %+v", i, g, w)
+				}
+			}
+		})
+	}
+}
+
+func TestParseMeisaiShiftJIS(t *testing.T) {
+	utf8 := "利用年月日(至),時刻(至),利用IC(至),通行料金,ETCカード番号\n25/01/19,07:00,厚木,800,****1234\n"
+	sjis, err := ToShiftJIS([]byte(utf8))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := ParseMeisai(strings.NewReader(string(sjis)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].ExitIC != "厚木" || got[0].Toll != 800 {
+		t.Errorf("got %+v", got)
+	}
+}
@@ -21,10 +21,242 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+// スクレイピング失敗の種別
+type ErrorCode int32
+
+const (
+	ErrorCode_ERROR_CODE_UNSPECIFIED         ErrorCode = 0 // 成功、または分類できないエラー
+	ErrorCode_ERROR_CODE_INVALID_CREDENTIALS ErrorCode = 1 // ログインIDまたはパスワードの誤り
+	ErrorCode_ERROR_CODE_ACCOUNT_LOCKED      ErrorCode = 2 // アカウントロック
+	ErrorCode_ERROR_CODE_PASSWORD_EXPIRED    ErrorCode = 3 // パスワード変更が必要
+	ErrorCode_ERROR_CODE_SITE_MAINTENANCE    ErrorCode = 4 // サイトメンテナンス中
+)
+
+// Enum value maps for ErrorCode.
+var (
+	ErrorCode_name = map[int32]string{
+		0: "ERROR_CODE_UNSPECIFIED",
+		1: "ERROR_CODE_INVALID_CREDENTIALS",
+		2: "ERROR_CODE_ACCOUNT_LOCKED",
+		3: "ERROR_CODE_PASSWORD_EXPIRED",
+		4: "ERROR_CODE_SITE_MAINTENANCE",
+	}
+	ErrorCode_value = map[string]int32{
+		"ERROR_CODE_UNSPECIFIED":         0,
+		"ERROR_CODE_INVALID_CREDENTIALS": 1,
+		"ERROR_CODE_ACCOUNT_LOCKED":      2,
+		"ERROR_CODE_PASSWORD_EXPIRED":    3,
+		"ERROR_CODE_SITE_MAINTENANCE":    4,
+	}
+)
+
+func (x ErrorCode) Enum() *ErrorCode {
+	p := new(ErrorCode)
+	*p = x
+	return p
+}
+
+func (x ErrorCode) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ErrorCode) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_scraper_proto_enumTypes[0].Descriptor()
+}
+
+func (ErrorCode) Type() protoreflect.EnumType {
+	return &file_proto_scraper_proto_enumTypes[0]
+}
+
+func (x ErrorCode) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ErrorCode.Descriptor instead.
+func (ErrorCode) EnumDescriptor() ([]byte, []int) {
+	return file_proto_scraper_proto_rawDescGZIP(), []int{0}
+}
+
+// ダウンロードファイルの文字コード
+type FileEncoding int32
+
+const (
+	FileEncoding_FILE_ENCODING_UTF8      FileEncoding = 0 // UTF-8（デフォルト）
+	FileEncoding_FILE_ENCODING_SHIFT_JIS FileEncoding = 1 // Shift_JIS（サイトから取得した元の文字コード）
+)
+
+// Enum value maps for FileEncoding.
+var (
+	FileEncoding_name = map[int32]string{
+		0: "FILE_ENCODING_UTF8",
+		1: "FILE_ENCODING_SHIFT_JIS",
+	}
+	FileEncoding_value = map[string]int32{
+		"FILE_ENCODING_UTF8":      0,
+		"FILE_ENCODING_SHIFT_JIS": 1,
+	}
+)
+
+func (x FileEncoding) Enum() *FileEncoding {
+	p := new(FileEncoding)
+	*p = x
+	return p
+}
+
+func (x FileEncoding) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (FileEncoding) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_scraper_proto_enumTypes[1].Descriptor()
+}
+
+func (FileEncoding) Type() protoreflect.EnumType {
+	return &file_proto_scraper_proto_enumTypes[1]
+}
+
+func (x FileEncoding) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use FileEncoding.Descriptor instead.
+func (FileEncoding) EnumDescriptor() ([]byte, []int) {
+	return file_proto_scraper_proto_rawDescGZIP(), []int{1}
+}
+
+// ジョブおよびアカウントの処理状態
+type JobState int32
+
+const (
+	JobState_JOB_STATE_UNSPECIFIED JobState = 0
+	JobState_JOB_STATE_QUEUED      JobState = 1 // 待機中
+	JobState_JOB_STATE_RUNNING     JobState = 2 // 実行中
+	JobState_JOB_STATE_SUCCEEDED   JobState = 3 // 成功
+	JobState_JOB_STATE_FAILED      JobState = 4 // 失敗
+	JobState_JOB_STATE_CANCELLED   JobState = 5 // キャンセル
+)
+
+// Enum value maps for JobState.
+var (
+	JobState_name = map[int32]string{
+		0: "JOB_STATE_UNSPECIFIED",
+		1: "JOB_STATE_QUEUED",
+		2: "JOB_STATE_RUNNING",
+		3: "JOB_STATE_SUCCEEDED",
+		4: "JOB_STATE_FAILED",
+		5: "JOB_STATE_CANCELLED",
+	}
+	JobState_value = map[string]int32{
+		"JOB_STATE_UNSPECIFIED": 0,
+		"JOB_STATE_QUEUED":      1,
+		"JOB_STATE_RUNNING":     2,
+		"JOB_STATE_SUCCEEDED":   3,
+		"JOB_STATE_FAILED":      4,
+		"JOB_STATE_CANCELLED":   5,
+	}
+)
+
+func (x JobState) Enum() *JobState {
+	p := new(JobState)
+	*p = x
+	return p
+}
+
+func (x JobState) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (JobState) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_scraper_proto_enumTypes[2].Descriptor()
+}
+
+func (JobState) Type() protoreflect.EnumType {
+	return &file_proto_scraper_proto_enumTypes[2]
+}
+
+func (x JobState) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use JobState.Descriptor instead.
+func (JobState) EnumDescriptor() ([]byte, []int) {
+	return file_proto_scraper_proto_rawDescGZIP(), []int{2}
+}
+
+// スクレイピングの進捗段階
+type ScrapeStage int32
+
+const (
+	ScrapeStage_SCRAPE_STAGE_UNSPECIFIED       ScrapeStage = 0
+	ScrapeStage_SCRAPE_STAGE_INITIALIZE        ScrapeStage = 1 // ブラウザ初期化
+	ScrapeStage_SCRAPE_STAGE_LOGIN             ScrapeStage = 2 // ログイン
+	ScrapeStage_SCRAPE_STAGE_SEARCH            ScrapeStage = 3 // 明細検索
+	ScrapeStage_SCRAPE_STAGE_CSV_CLICK         ScrapeStage = 4 // CSVリンクのクリック
+	ScrapeStage_SCRAPE_STAGE_DOWNLOAD_COMPLETE ScrapeStage = 5 // ダウンロード完了
+	ScrapeStage_SCRAPE_STAGE_ACCOUNT_FINISHED  ScrapeStage = 6 // アカウントの処理終了（resultに結果）
+	ScrapeStage_SCRAPE_STAGE_JOB_FINISHED      ScrapeStage = 7 // 全アカウントの処理終了（jobに結果、最後のイベント）
+)
+
+// Enum value maps for ScrapeStage.
+var (
+	ScrapeStage_name = map[int32]string{
+		0: "SCRAPE_STAGE_UNSPECIFIED",
+		1: "SCRAPE_STAGE_INITIALIZE",
+		2: "SCRAPE_STAGE_LOGIN",
+		3: "SCRAPE_STAGE_SEARCH",
+		4: "SCRAPE_STAGE_CSV_CLICK",
+		5: "SCRAPE_STAGE_DOWNLOAD_COMPLETE",
+		6: "SCRAPE_STAGE_ACCOUNT_FINISHED",
+		7: "SCRAPE_STAGE_JOB_FINISHED",
+	}
+	ScrapeStage_value = map[string]int32{
+		"SCRAPE_STAGE_UNSPECIFIED":       0,
+		"SCRAPE_STAGE_INITIALIZE":        1,
+		"SCRAPE_STAGE_LOGIN":             2,
+		"SCRAPE_STAGE_SEARCH":            3,
+		"SCRAPE_STAGE_CSV_CLICK":         4,
+		"SCRAPE_STAGE_DOWNLOAD_COMPLETE": 5,
+		"SCRAPE_STAGE_ACCOUNT_FINISHED":  6,
+		"SCRAPE_STAGE_JOB_FINISHED":      7,
+	}
+)
+
+func (x ScrapeStage) Enum() *ScrapeStage {
+	p := new(ScrapeStage)
+	*p = x
+	return p
+}
+
+func (x ScrapeStage) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ScrapeStage) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_scraper_proto_enumTypes[3].Descriptor()
+}
+
+func (ScrapeStage) Type() protoreflect.EnumType {
+	return &file_proto_scraper_proto_enumTypes[3]
+}
+
+func (x ScrapeStage) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ScrapeStage.Descriptor instead.
+func (ScrapeStage) EnumDescriptor() ([]byte, []int) {
+	return file_proto_scraper_proto_rawDescGZIP(), []int{3}
+}
+
 type ScrapeRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
 	Password      string                 `protobuf:"bytes,2,opt,name=password,proto3" json:"password,omitempty"`
+	FromDate      string                 `protobuf:"bytes,3,opt,name=from_date,json=fromDate,proto3" json:"from_date,omitempty"`          // 利用期間の開始日（YYYY-MM-DD、省略時はサイトのデフォルト期間）
+	ToDate        string                 `protobuf:"bytes,4,opt,name=to_date,json=toDate,proto3" json:"to_date,omitempty"`                // 利用期間の終了日（YYYY-MM-DD、省略時は当日）
+	LastMonths    int32                  `protobuf:"varint,5,opt,name=last_months,json=lastMonths,proto3" json:"last_months,omitempty"`   // 直近Nヶ月＋当月（from_date/to_dateとは併用不可）
+	ScraperType   string                 `protobuf:"bytes,6,opt,name=scraper_type,json=scraperType,proto3" json:"scraper_type,omitempty"` // スクレイパーの種類（省略時は"etc"、HealthResponse.scraper_typesを参照）
+	AccountRef    string                 `protobuf:"bytes,7,opt,name=account_ref,json=accountRef,proto3" json:"account_ref,omitempty"`    // 保管庫（-vault）のアカウントID（指定時はuser_id/passwordは不要）
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -73,12 +305,49 @@ func (x *ScrapeRequest) GetPassword() string {
 	return ""
 }
 
+func (x *ScrapeRequest) GetFromDate() string {
+	if x != nil {
+		return x.FromDate
+	}
+	return ""
+}
+
+func (x *ScrapeRequest) GetToDate() string {
+	if x != nil {
+		return x.ToDate
+	}
+	return ""
+}
+
+func (x *ScrapeRequest) GetLastMonths() int32 {
+	if x != nil {
+		return x.LastMonths
+	}
+	return 0
+}
+
+func (x *ScrapeRequest) GetScraperType() string {
+	if x != nil {
+		return x.ScraperType
+	}
+	return ""
+}
+
+func (x *ScrapeRequest) GetAccountRef() string {
+	if x != nil {
+		return x.AccountRef
+	}
+	return ""
+}
+
 type ScrapeResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
 	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
 	CsvPath       string                 `protobuf:"bytes,3,opt,name=csv_path,json=csvPath,proto3" json:"csv_path,omitempty"`
 	CsvContent    string                 `protobuf:"bytes,4,opt,name=csv_content,json=csvContent,proto3" json:"csv_content,omitempty"` // CSVの内容（オプション）
+	Records       []*UsageRecord         `protobuf:"bytes,5,rep,name=records,proto3" json:"records,omitempty"`                         // CSVをパースした利用明細
+	JobId         string                 `protobuf:"bytes,6,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`                // ジョブID（GetJobで参照可能）
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -141,6 +410,20 @@ func (x *ScrapeResponse) GetCsvContent() string {
 	return ""
 }
 
+func (x *ScrapeResponse) GetRecords() []*UsageRecord {
+	if x != nil {
+		return x.Records
+	}
+	return nil
+}
+
+func (x *ScrapeResponse) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
 type ScrapeMultipleRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Accounts      []*Account             `protobuf:"bytes,1,rep,name=accounts,proto3" json:"accounts,omitempty"`
@@ -189,6 +472,11 @@ type Account struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
 	Password      string                 `protobuf:"bytes,2,opt,name=password,proto3" json:"password,omitempty"`
+	FromDate      string                 `protobuf:"bytes,3,opt,name=from_date,json=fromDate,proto3" json:"from_date,omitempty"`          // 利用期間の開始日（YYYY-MM-DD）
+	ToDate        string                 `protobuf:"bytes,4,opt,name=to_date,json=toDate,proto3" json:"to_date,omitempty"`                // 利用期間の終了日（YYYY-MM-DD）
+	LastMonths    int32                  `protobuf:"varint,5,opt,name=last_months,json=lastMonths,proto3" json:"last_months,omitempty"`   // 直近Nヶ月＋当月
+	ScraperType   string                 `protobuf:"bytes,6,opt,name=scraper_type,json=scraperType,proto3" json:"scraper_type,omitempty"` // スクレイパーの種類（省略時は"etc"）
+	AccountRef    string                 `protobuf:"bytes,7,opt,name=account_ref,json=accountRef,proto3" json:"account_ref,omitempty"`    // 保管庫（-vault）のアカウントID（指定時はuser_id/passwordは不要）
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -237,11 +525,47 @@ func (x *Account) GetPassword() string {
 	return ""
 }
 
+func (x *Account) GetFromDate() string {
+	if x != nil {
+		return x.FromDate
+	}
+	return ""
+}
+
+func (x *Account) GetToDate() string {
+	if x != nil {
+		return x.ToDate
+	}
+	return ""
+}
+
+func (x *Account) GetLastMonths() int32 {
+	if x != nil {
+		return x.LastMonths
+	}
+	return 0
+}
+
+func (x *Account) GetScraperType() string {
+	if x != nil {
+		return x.ScraperType
+	}
+	return ""
+}
+
+func (x *Account) GetAccountRef() string {
+	if x != nil {
+		return x.AccountRef
+	}
+	return ""
+}
+
 type ScrapeMultipleResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Results       []*ScrapeResult        `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
 	SuccessCount  int32                  `protobuf:"varint,2,opt,name=success_count,json=successCount,proto3" json:"success_count,omitempty"`
 	TotalCount    int32                  `protobuf:"varint,3,opt,name=total_count,json=totalCount,proto3" json:"total_count,omitempty"`
+	JobId         string                 `protobuf:"bytes,4,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"` // ジョブID（結果はGetJobで取得）
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -297,6 +621,13 @@ func (x *ScrapeMultipleResponse) GetTotalCount() int32 {
 	return 0
 }
 
+func (x *ScrapeMultipleResponse) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
 type ScrapeResult struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
@@ -304,6 +635,12 @@ type ScrapeResult struct {
 	Message       string                 `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
 	CsvPath       string                 `protobuf:"bytes,4,opt,name=csv_path,json=csvPath,proto3" json:"csv_path,omitempty"`
 	CsvContent    string                 `protobuf:"bytes,5,opt,name=csv_content,json=csvContent,proto3" json:"csv_content,omitempty"`
+	Records       []*UsageRecord         `protobuf:"bytes,6,rep,name=records,proto3" json:"records,omitempty"`                                              // CSVをパースした利用明細
+	ErrorCode     ErrorCode              `protobuf:"varint,7,opt,name=error_code,json=errorCode,proto3,enum=scraper.ErrorCode" json:"error_code,omitempty"` // 失敗時のエラー種別
+	State         JobState               `protobuf:"varint,8,opt,name=state,proto3,enum=scraper.JobState" json:"state,omitempty"`                           // アカウントの処理状態
+	StartedAt     string                 `protobuf:"bytes,9,opt,name=started_at,json=startedAt,proto3" json:"started_at,omitempty"`                         // 開始日時（RFC3339）
+	FinishedAt    string                 `protobuf:"bytes,10,opt,name=finished_at,json=finishedAt,proto3" json:"finished_at,omitempty"`                     // 終了日時（RFC3339）
+	HasArtifacts  bool                   `protobuf:"varint,11,opt,name=has_artifacts,json=hasArtifacts,proto3" json:"has_artifacts,omitempty"`              // 失敗時の診断ファイルあり（GetArtifactsで取得）
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -373,65 +710,83 @@ func (x *ScrapeResult) GetCsvContent() string {
 	return ""
 }
 
-type HealthRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+func (x *ScrapeResult) GetRecords() []*UsageRecord {
+	if x != nil {
+		return x.Records
+	}
+	return nil
 }
 
-func (x *HealthRequest) Reset() {
-	*x = HealthRequest{}
-	mi := &file_proto_scraper_proto_msgTypes[6]
-	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-	ms.StoreMessageInfo(mi)
+func (x *ScrapeResult) GetErrorCode() ErrorCode {
+	if x != nil {
+		return x.ErrorCode
+	}
+	return ErrorCode_ERROR_CODE_UNSPECIFIED
 }
 
-func (x *HealthRequest) String() string {
-	return protoimpl.X.MessageStringOf(x)
+func (x *ScrapeResult) GetState() JobState {
+	if x != nil {
+		return x.State
+	}
+	return JobState_JOB_STATE_UNSPECIFIED
 }
 
-func (*HealthRequest) ProtoMessage() {}
+func (x *ScrapeResult) GetStartedAt() string {
+	if x != nil {
+		return x.StartedAt
+	}
+	return ""
+}
 
-func (x *HealthRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_scraper_proto_msgTypes[6]
+func (x *ScrapeResult) GetFinishedAt() string {
 	if x != nil {
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		if ms.LoadMessageInfo() == nil {
-			ms.StoreMessageInfo(mi)
-		}
-		return ms
+		return x.FinishedAt
 	}
-	return mi.MessageOf(x)
+	return ""
 }
 
-// Deprecated: Use HealthRequest.ProtoReflect.Descriptor instead.
-func (*HealthRequest) Descriptor() ([]byte, []int) {
-	return file_proto_scraper_proto_rawDescGZIP(), []int{6}
+func (x *ScrapeResult) GetHasArtifacts() bool {
+	if x != nil {
+		return x.HasArtifacts
+	}
+	return false
 }
 
-type HealthResponse struct {
+// 利用明細1行分
+type UsageRecord struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Healthy       bool                   `protobuf:"varint,1,opt,name=healthy,proto3" json:"healthy,omitempty"`
-	Version       string                 `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`
+	EntryDate     string                 `protobuf:"bytes,1,opt,name=entry_date,json=entryDate,proto3" json:"entry_date,omitempty"`              // 利用年月日（自）YYYY-MM-DD
+	EntryTime     string                 `protobuf:"bytes,2,opt,name=entry_time,json=entryTime,proto3" json:"entry_time,omitempty"`              // 時刻（自）HH:MM
+	ExitDate      string                 `protobuf:"bytes,3,opt,name=exit_date,json=exitDate,proto3" json:"exit_date,omitempty"`                 // 利用年月日（至）YYYY-MM-DD
+	ExitTime      string                 `protobuf:"bytes,4,opt,name=exit_time,json=exitTime,proto3" json:"exit_time,omitempty"`                 // 時刻（至）HH:MM
+	EntryIc       string                 `protobuf:"bytes,5,opt,name=entry_ic,json=entryIc,proto3" json:"entry_ic,omitempty"`                    // 利用IC（自）
+	ExitIc        string                 `protobuf:"bytes,6,opt,name=exit_ic,json=exitIc,proto3" json:"exit_ic,omitempty"`                       // 利用IC（至）
+	VehicleClass  string                 `protobuf:"bytes,7,opt,name=vehicle_class,json=vehicleClass,proto3" json:"vehicle_class,omitempty"`     // 車種
+	CardNumber    string                 `protobuf:"bytes,8,opt,name=card_number,json=cardNumber,proto3" json:"card_number,omitempty"`           // ETCカード番号
+	OriginalToll  int32                  `protobuf:"varint,9,opt,name=original_toll,json=originalToll,proto3" json:"original_toll,omitempty"`    // 割引前料金
+	Discount      int32                  `protobuf:"varint,10,opt,name=discount,proto3" json:"discount,omitempty"`                               // ETC割引額
+	Toll          int32                  `protobuf:"varint,11,opt,name=toll,proto3" json:"toll,omitempty"`                                       // 通行料金
+	VehicleNumber string                 `protobuf:"bytes,12,opt,name=vehicle_number,json=vehicleNumber,proto3" json:"vehicle_number,omitempty"` // 車両番号
+	Note          string                 `protobuf:"bytes,13,opt,name=note,proto3" json:"note,omitempty"`                                        // 備考
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *HealthResponse) Reset() {
-	*x = HealthResponse{}
-	mi := &file_proto_scraper_proto_msgTypes[7]
+func (x *UsageRecord) Reset() {
+	*x = UsageRecord{}
+	mi := &file_proto_scraper_proto_msgTypes[6]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *HealthResponse) String() string {
+func (x *UsageRecord) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*HealthResponse) ProtoMessage() {}
+func (*UsageRecord) ProtoMessage() {}
 
-func (x *HealthResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_scraper_proto_msgTypes[7]
+func (x *UsageRecord) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_scraper_proto_msgTypes[6]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -442,84 +797,1850 @@ func (x *HealthResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use HealthResponse.ProtoReflect.Descriptor instead.
-func (*HealthResponse) Descriptor() ([]byte, []int) {
-	return file_proto_scraper_proto_rawDescGZIP(), []int{7}
+// Deprecated: Use UsageRecord.ProtoReflect.Descriptor instead.
+func (*UsageRecord) Descriptor() ([]byte, []int) {
+	return file_proto_scraper_proto_rawDescGZIP(), []int{6}
 }
 
-func (x *HealthResponse) GetHealthy() bool {
+func (x *UsageRecord) GetEntryDate() string {
 	if x != nil {
-		return x.Healthy
+		return x.EntryDate
 	}
-	return false
+	return ""
 }
 
-func (x *HealthResponse) GetVersion() string {
+func (x *UsageRecord) GetEntryTime() string {
 	if x != nil {
-		return x.Version
+		return x.EntryTime
 	}
 	return ""
 }
 
-type GetDownloadedFilesRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+func (x *UsageRecord) GetExitDate() string {
+	if x != nil {
+		return x.ExitDate
+	}
+	return ""
 }
 
-func (x *GetDownloadedFilesRequest) Reset() {
-	*x = GetDownloadedFilesRequest{}
-	mi := &file_proto_scraper_proto_msgTypes[8]
-	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-	ms.StoreMessageInfo(mi)
+func (x *UsageRecord) GetExitTime() string {
+	if x != nil {
+		return x.ExitTime
+	}
+	return ""
 }
 
-func (x *GetDownloadedFilesRequest) String() string {
-	return protoimpl.X.MessageStringOf(x)
+func (x *UsageRecord) GetEntryIc() string {
+	if x != nil {
+		return x.EntryIc
+	}
+	return ""
 }
 
-func (*GetDownloadedFilesRequest) ProtoMessage() {}
+func (x *UsageRecord) GetExitIc() string {
+	if x != nil {
+		return x.ExitIc
+	}
+	return ""
+}
 
-func (x *GetDownloadedFilesRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_scraper_proto_msgTypes[8]
+func (x *UsageRecord) GetVehicleClass() string {
 	if x != nil {
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		if ms.LoadMessageInfo() == nil {
-			ms.StoreMessageInfo(mi)
-		}
-		return ms
+		return x.VehicleClass
 	}
-	return mi.MessageOf(x)
+	return ""
 }
 
-// Deprecated: Use GetDownloadedFilesRequest.ProtoReflect.Descriptor instead.
-func (*GetDownloadedFilesRequest) Descriptor() ([]byte, []int) {
-	return file_proto_scraper_proto_rawDescGZIP(), []int{8}
+func (x *UsageRecord) GetCardNumber() string {
+	if x != nil {
+		return x.CardNumber
+	}
+	return ""
 }
 
-type DownloadedFile struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Filename      string                 `protobuf:"bytes,1,opt,name=filename,proto3" json:"filename,omitempty"`
+func (x *UsageRecord) GetOriginalToll() int32 {
+	if x != nil {
+		return x.OriginalToll
+	}
+	return 0
+}
+
+func (x *UsageRecord) GetDiscount() int32 {
+	if x != nil {
+		return x.Discount
+	}
+	return 0
+}
+
+func (x *UsageRecord) GetToll() int32 {
+	if x != nil {
+		return x.Toll
+	}
+	return 0
+}
+
+func (x *UsageRecord) GetVehicleNumber() string {
+	if x != nil {
+		return x.VehicleNumber
+	}
+	return ""
+}
+
+func (x *UsageRecord) GetNote() string {
+	if x != nil {
+		return x.Note
+	}
+	return ""
+}
+
+type HealthRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HealthRequest) Reset() {
+	*x = HealthRequest{}
+	mi := &file_proto_scraper_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HealthRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HealthRequest) ProtoMessage() {}
+
+func (x *HealthRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_scraper_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HealthRequest.ProtoReflect.Descriptor instead.
+func (*HealthRequest) Descriptor() ([]byte, []int) {
+	return file_proto_scraper_proto_rawDescGZIP(), []int{7}
+}
+
+type HealthResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Healthy       bool                   `protobuf:"varint,1,opt,name=healthy,proto3" json:"healthy,omitempty"`
+	Version       string                 `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`
+	ScraperTypes  []string               `protobuf:"bytes,3,rep,name=scraper_types,json=scraperTypes,proto3" json:"scraper_types,omitempty"` // 利用可能なスクレイパーの種類
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HealthResponse) Reset() {
+	*x = HealthResponse{}
+	mi := &file_proto_scraper_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HealthResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HealthResponse) ProtoMessage() {}
+
+func (x *HealthResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_scraper_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HealthResponse.ProtoReflect.Descriptor instead.
+func (*HealthResponse) Descriptor() ([]byte, []int) {
+	return file_proto_scraper_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *HealthResponse) GetHealthy() bool {
+	if x != nil {
+		return x.Healthy
+	}
+	return false
+}
+
+func (x *HealthResponse) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *HealthResponse) GetScraperTypes() []string {
+	if x != nil {
+		return x.ScraperTypes
+	}
+	return nil
+}
+
+type GetDownloadedFilesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Encoding      FileEncoding           `protobuf:"varint,1,opt,name=encoding,proto3,enum=scraper.FileEncoding" json:"encoding,omitempty"` // 返却するファイルの文字コード
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetDownloadedFilesRequest) Reset() {
+	*x = GetDownloadedFilesRequest{}
+	mi := &file_proto_scraper_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetDownloadedFilesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetDownloadedFilesRequest) ProtoMessage() {}
+
+func (x *GetDownloadedFilesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_scraper_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetDownloadedFilesRequest.ProtoReflect.Descriptor instead.
+func (*GetDownloadedFilesRequest) Descriptor() ([]byte, []int) {
+	return file_proto_scraper_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *GetDownloadedFilesRequest) GetEncoding() FileEncoding {
+	if x != nil {
+		return x.Encoding
+	}
+	return FileEncoding_FILE_ENCODING_UTF8
+}
+
+type DownloadedFile struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Filename      string                 `protobuf:"bytes,1,opt,name=filename,proto3" json:"filename,omitempty"`
 	Content       []byte                 `protobuf:"bytes,2,opt,name=content,proto3" json:"content,omitempty"`
+	Records       []*UsageRecord         `protobuf:"bytes,3,rep,name=records,proto3" json:"records,omitempty"` // CSVをパースした利用明細
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DownloadedFile) Reset() {
+	*x = DownloadedFile{}
+	mi := &file_proto_scraper_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DownloadedFile) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DownloadedFile) ProtoMessage() {}
+
+func (x *DownloadedFile) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_scraper_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DownloadedFile.ProtoReflect.Descriptor instead.
+func (*DownloadedFile) Descriptor() ([]byte, []int) {
+	return file_proto_scraper_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *DownloadedFile) GetFilename() string {
+	if x != nil {
+		return x.Filename
+	}
+	return ""
+}
+
+func (x *DownloadedFile) GetContent() []byte {
+	if x != nil {
+		return x.Content
+	}
+	return nil
+}
+
+func (x *DownloadedFile) GetRecords() []*UsageRecord {
+	if x != nil {
+		return x.Records
+	}
+	return nil
+}
+
+type GetDownloadedFilesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Files         []*DownloadedFile      `protobuf:"bytes,1,rep,name=files,proto3" json:"files,omitempty"`
+	SessionFolder string                 `protobuf:"bytes,2,opt,name=session_folder,json=sessionFolder,proto3" json:"session_folder,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetDownloadedFilesResponse) Reset() {
+	*x = GetDownloadedFilesResponse{}
+	mi := &file_proto_scraper_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetDownloadedFilesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetDownloadedFilesResponse) ProtoMessage() {}
+
+func (x *GetDownloadedFilesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_scraper_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetDownloadedFilesResponse.ProtoReflect.Descriptor instead.
+func (*GetDownloadedFilesResponse) Descriptor() ([]byte, []int) {
+	return file_proto_scraper_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *GetDownloadedFilesResponse) GetFiles() []*DownloadedFile {
+	if x != nil {
+		return x.Files
+	}
+	return nil
+}
+
+func (x *GetDownloadedFilesResponse) GetSessionFolder() string {
+	if x != nil {
+		return x.SessionFolder
+	}
+	return ""
+}
+
+type Job struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	JobId         string                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	State         JobState               `protobuf:"varint,2,opt,name=state,proto3,enum=scraper.JobState" json:"state,omitempty"`
+	SessionFolder string                 `protobuf:"bytes,3,opt,name=session_folder,json=sessionFolder,proto3" json:"session_folder,omitempty"`
+	CreatedAt     string                 `protobuf:"bytes,4,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`    // 作成日時（RFC3339）
+	StartedAt     string                 `protobuf:"bytes,5,opt,name=started_at,json=startedAt,proto3" json:"started_at,omitempty"`    // 開始日時（RFC3339）
+	FinishedAt    string                 `protobuf:"bytes,6,opt,name=finished_at,json=finishedAt,proto3" json:"finished_at,omitempty"` // 終了日時（RFC3339）
+	Results       []*ScrapeResult        `protobuf:"bytes,7,rep,name=results,proto3" json:"results,omitempty"`                         // アカウントごとの状態
+	SuccessCount  int32                  `protobuf:"varint,8,opt,name=success_count,json=successCount,proto3" json:"success_count,omitempty"`
+	TotalCount    int32                  `protobuf:"varint,9,opt,name=total_count,json=totalCount,proto3" json:"total_count,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Job) Reset() {
+	*x = Job{}
+	mi := &file_proto_scraper_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Job) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Job) ProtoMessage() {}
+
+func (x *Job) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_scraper_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Job.ProtoReflect.Descriptor instead.
+func (*Job) Descriptor() ([]byte, []int) {
+	return file_proto_scraper_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *Job) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+func (x *Job) GetState() JobState {
+	if x != nil {
+		return x.State
+	}
+	return JobState_JOB_STATE_UNSPECIFIED
+}
+
+func (x *Job) GetSessionFolder() string {
+	if x != nil {
+		return x.SessionFolder
+	}
+	return ""
+}
+
+func (x *Job) GetCreatedAt() string {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return ""
+}
+
+func (x *Job) GetStartedAt() string {
+	if x != nil {
+		return x.StartedAt
+	}
+	return ""
+}
+
+func (x *Job) GetFinishedAt() string {
+	if x != nil {
+		return x.FinishedAt
+	}
+	return ""
+}
+
+func (x *Job) GetResults() []*ScrapeResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+func (x *Job) GetSuccessCount() int32 {
+	if x != nil {
+		return x.SuccessCount
+	}
+	return 0
+}
+
+func (x *Job) GetTotalCount() int32 {
+	if x != nil {
+		return x.TotalCount
+	}
+	return 0
+}
+
+type GetJobRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	JobId         string                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetJobRequest) Reset() {
+	*x = GetJobRequest{}
+	mi := &file_proto_scraper_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetJobRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetJobRequest) ProtoMessage() {}
+
+func (x *GetJobRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_scraper_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetJobRequest.ProtoReflect.Descriptor instead.
+func (*GetJobRequest) Descriptor() ([]byte, []int) {
+	return file_proto_scraper_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *GetJobRequest) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+type ListJobsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Limit         int32                  `protobuf:"varint,1,opt,name=limit,proto3" json:"limit,omitempty"` // 最大件数（0は全件）
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListJobsRequest) Reset() {
+	*x = ListJobsRequest{}
+	mi := &file_proto_scraper_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListJobsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListJobsRequest) ProtoMessage() {}
+
+func (x *ListJobsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_scraper_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListJobsRequest.ProtoReflect.Descriptor instead.
+func (*ListJobsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_scraper_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *ListJobsRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type ListJobsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Jobs          []*Job                 `protobuf:"bytes,1,rep,name=jobs,proto3" json:"jobs,omitempty"` // 明細（records）は含まない
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListJobsResponse) Reset() {
+	*x = ListJobsResponse{}
+	mi := &file_proto_scraper_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListJobsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListJobsResponse) ProtoMessage() {}
+
+func (x *ListJobsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_scraper_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListJobsResponse.ProtoReflect.Descriptor instead.
+func (*ListJobsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_scraper_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *ListJobsResponse) GetJobs() []*Job {
+	if x != nil {
+		return x.Jobs
+	}
+	return nil
+}
+
+type CancelJobRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	JobId         string                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CancelJobRequest) Reset() {
+	*x = CancelJobRequest{}
+	mi := &file_proto_scraper_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CancelJobRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CancelJobRequest) ProtoMessage() {}
+
+func (x *CancelJobRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_scraper_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CancelJobRequest.ProtoReflect.Descriptor instead.
+func (*CancelJobRequest) Descriptor() ([]byte, []int) {
+	return file_proto_scraper_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *CancelJobRequest) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+type CancelJobResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CancelJobResponse) Reset() {
+	*x = CancelJobResponse{}
+	mi := &file_proto_scraper_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CancelJobResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CancelJobResponse) ProtoMessage() {}
+
+func (x *CancelJobResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_scraper_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CancelJobResponse.ProtoReflect.Descriptor instead.
+func (*CancelJobResponse) Descriptor() ([]byte, []int) {
+	return file_proto_scraper_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *CancelJobResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *CancelJobResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type ScrapeEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	JobId         string                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	AccountIndex  int32                  `protobuf:"varint,2,opt,name=account_index,json=accountIndex,proto3" json:"account_index,omitempty"` // Accountsのインデックス
+	UserId        string                 `protobuf:"bytes,3,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Stage         ScrapeStage            `protobuf:"varint,4,opt,name=stage,proto3,enum=scraper.ScrapeStage" json:"stage,omitempty"`
+	Message       string                 `protobuf:"bytes,5,opt,name=message,proto3" json:"message,omitempty"`
+	Timestamp     string                 `protobuf:"bytes,6,opt,name=timestamp,proto3" json:"timestamp,omitempty"` // 発生日時（RFC3339）
+	Result        *ScrapeResult          `protobuf:"bytes,7,opt,name=result,proto3" json:"result,omitempty"`       // SCRAPE_STAGE_ACCOUNT_FINISHEDのみ
+	Job           *Job                   `protobuf:"bytes,8,opt,name=job,proto3" json:"job,omitempty"`             // SCRAPE_STAGE_JOB_FINISHEDのみ
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ScrapeEvent) Reset() {
+	*x = ScrapeEvent{}
+	mi := &file_proto_scraper_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ScrapeEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScrapeEvent) ProtoMessage() {}
+
+func (x *ScrapeEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_scraper_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScrapeEvent.ProtoReflect.Descriptor instead.
+func (*ScrapeEvent) Descriptor() ([]byte, []int) {
+	return file_proto_scraper_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *ScrapeEvent) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+func (x *ScrapeEvent) GetAccountIndex() int32 {
+	if x != nil {
+		return x.AccountIndex
+	}
+	return 0
+}
+
+func (x *ScrapeEvent) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *ScrapeEvent) GetStage() ScrapeStage {
+	if x != nil {
+		return x.Stage
+	}
+	return ScrapeStage_SCRAPE_STAGE_UNSPECIFIED
+}
+
+func (x *ScrapeEvent) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *ScrapeEvent) GetTimestamp() string {
+	if x != nil {
+		return x.Timestamp
+	}
+	return ""
+}
+
+func (x *ScrapeEvent) GetResult() *ScrapeResult {
+	if x != nil {
+		return x.Result
+	}
+	return nil
+}
+
+func (x *ScrapeEvent) GetJob() *Job {
+	if x != nil {
+		return x.Job
+	}
+	return nil
+}
+
+type GetArtifactsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	JobId         string                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	UserId        string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`                 // 省略時はジョブの全アカウント
+	IncludeData   bool                   `protobuf:"varint,3,opt,name=include_data,json=includeData,proto3" json:"include_data,omitempty"` // ファイルの内容を含める（falseは一覧のみ）
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetArtifactsRequest) Reset() {
+	*x = GetArtifactsRequest{}
+	mi := &file_proto_scraper_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetArtifactsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetArtifactsRequest) ProtoMessage() {}
+
+func (x *GetArtifactsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_scraper_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetArtifactsRequest.ProtoReflect.Descriptor instead.
+func (*GetArtifactsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_scraper_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *GetArtifactsRequest) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+func (x *GetArtifactsRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *GetArtifactsRequest) GetIncludeData() bool {
+	if x != nil {
+		return x.IncludeData
+	}
+	return false
+}
+
+type ArtifactFile struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"` // screenshot.jpg, page.html, url.txt, console.log, error.txt
+	Size          int64                  `protobuf:"varint,2,opt,name=size,proto3" json:"size,omitempty"`
+	Data          []byte                 `protobuf:"bytes,3,opt,name=data,proto3" json:"data,omitempty"` // include_data指定時のみ
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ArtifactFile) Reset() {
+	*x = ArtifactFile{}
+	mi := &file_proto_scraper_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ArtifactFile) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ArtifactFile) ProtoMessage() {}
+
+func (x *ArtifactFile) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_scraper_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ArtifactFile.ProtoReflect.Descriptor instead.
+func (*ArtifactFile) Descriptor() ([]byte, []int) {
+	return file_proto_scraper_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *ArtifactFile) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ArtifactFile) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *ArtifactFile) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+type AccountArtifacts struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`         // 失敗時のエラーメッセージ
+	Directory     string                 `protobuf:"bytes,3,opt,name=directory,proto3" json:"directory,omitempty"` // 保存先フォルダ
+	Files         []*ArtifactFile        `protobuf:"bytes,4,rep,name=files,proto3" json:"files,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AccountArtifacts) Reset() {
+	*x = AccountArtifacts{}
+	mi := &file_proto_scraper_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AccountArtifacts) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AccountArtifacts) ProtoMessage() {}
+
+func (x *AccountArtifacts) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_scraper_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AccountArtifacts.ProtoReflect.Descriptor instead.
+func (*AccountArtifacts) Descriptor() ([]byte, []int) {
+	return file_proto_scraper_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *AccountArtifacts) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *AccountArtifacts) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *AccountArtifacts) GetDirectory() string {
+	if x != nil {
+		return x.Directory
+	}
+	return ""
+}
+
+func (x *AccountArtifacts) GetFiles() []*ArtifactFile {
+	if x != nil {
+		return x.Files
+	}
+	return nil
+}
+
+type GetArtifactsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	JobId         string                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	Accounts      []*AccountArtifacts    `protobuf:"bytes,2,rep,name=accounts,proto3" json:"accounts,omitempty"` // 診断ファイルのあるアカウントのみ
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetArtifactsResponse) Reset() {
+	*x = GetArtifactsResponse{}
+	mi := &file_proto_scraper_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetArtifactsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetArtifactsResponse) ProtoMessage() {}
+
+func (x *GetArtifactsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_scraper_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetArtifactsResponse.ProtoReflect.Descriptor instead.
+func (*GetArtifactsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_scraper_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *GetArtifactsResponse) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+func (x *GetArtifactsResponse) GetAccounts() []*AccountArtifacts {
+	if x != nil {
+		return x.Accounts
+	}
+	return nil
+}
+
+// 定期実行スケジュール
+type Schedule struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Cron          string                 `protobuf:"bytes,2,opt,name=cron,proto3" json:"cron,omitempty"`                       // cron式（分 時 日 月 曜日、または @daily など）
+	Timezone      string                 `protobuf:"bytes,3,opt,name=timezone,proto3" json:"timezone,omitempty"`               // IANAタイムゾーン（例: Asia/Tokyo、省略時はサービスのローカル時刻）
+	Jitter        string                 `protobuf:"bytes,4,opt,name=jitter,proto3" json:"jitter,omitempty"`                   // 実行を遅らせる最大時間（例: "10m"）
+	CatchUp       bool                   `protobuf:"varint,5,opt,name=catch_up,json=catchUp,proto3" json:"catch_up,omitempty"` // 停止中に逃した実行を起動時に1回行う
+	Disabled      bool                   `protobuf:"varint,6,opt,name=disabled,proto3" json:"disabled,omitempty"`
+	Accounts      []*Account             `protobuf:"bytes,7,rep,name=accounts,proto3" json:"accounts,omitempty"`                      // passwordは返却されない（更新時に省略すると既存の値を維持、account_ref推奨）
+	LastRun       string                 `protobuf:"bytes,8,opt,name=last_run,json=lastRun,proto3" json:"last_run,omitempty"`         // 前回の実行日時（RFC3339、読み取り専用）
+	LastJobId     string                 `protobuf:"bytes,9,opt,name=last_job_id,json=lastJobId,proto3" json:"last_job_id,omitempty"` // 前回のジョブID（読み取り専用）
+	NextRun       string                 `protobuf:"bytes,10,opt,name=next_run,json=nextRun,proto3" json:"next_run,omitempty"`        // 次回の実行予定（RFC3339、読み取り専用）
+	Running       bool                   `protobuf:"varint,11,opt,name=running,proto3" json:"running,omitempty"`                      // 前回のジョブが実行中（読み取り専用）
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Schedule) Reset() {
+	*x = Schedule{}
+	mi := &file_proto_scraper_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Schedule) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Schedule) ProtoMessage() {}
+
+func (x *Schedule) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_scraper_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Schedule.ProtoReflect.Descriptor instead.
+func (*Schedule) Descriptor() ([]byte, []int) {
+	return file_proto_scraper_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *Schedule) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Schedule) GetCron() string {
+	if x != nil {
+		return x.Cron
+	}
+	return ""
+}
+
+func (x *Schedule) GetTimezone() string {
+	if x != nil {
+		return x.Timezone
+	}
+	return ""
+}
+
+func (x *Schedule) GetJitter() string {
+	if x != nil {
+		return x.Jitter
+	}
+	return ""
+}
+
+func (x *Schedule) GetCatchUp() bool {
+	if x != nil {
+		return x.CatchUp
+	}
+	return false
+}
+
+func (x *Schedule) GetDisabled() bool {
+	if x != nil {
+		return x.Disabled
+	}
+	return false
+}
+
+func (x *Schedule) GetAccounts() []*Account {
+	if x != nil {
+		return x.Accounts
+	}
+	return nil
+}
+
+func (x *Schedule) GetLastRun() string {
+	if x != nil {
+		return x.LastRun
+	}
+	return ""
+}
+
+func (x *Schedule) GetLastJobId() string {
+	if x != nil {
+		return x.LastJobId
+	}
+	return ""
+}
+
+func (x *Schedule) GetNextRun() string {
+	if x != nil {
+		return x.NextRun
+	}
+	return ""
+}
+
+func (x *Schedule) GetRunning() bool {
+	if x != nil {
+		return x.Running
+	}
+	return false
+}
+
+type ListSchedulesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListSchedulesRequest) Reset() {
+	*x = ListSchedulesRequest{}
+	mi := &file_proto_scraper_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSchedulesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSchedulesRequest) ProtoMessage() {}
+
+func (x *ListSchedulesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_scraper_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSchedulesRequest.ProtoReflect.Descriptor instead.
+func (*ListSchedulesRequest) Descriptor() ([]byte, []int) {
+	return file_proto_scraper_proto_rawDescGZIP(), []int{24}
+}
+
+type ListSchedulesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Schedules     []*Schedule            `protobuf:"bytes,1,rep,name=schedules,proto3" json:"schedules,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListSchedulesResponse) Reset() {
+	*x = ListSchedulesResponse{}
+	mi := &file_proto_scraper_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSchedulesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSchedulesResponse) ProtoMessage() {}
+
+func (x *ListSchedulesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_scraper_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSchedulesResponse.ProtoReflect.Descriptor instead.
+func (*ListSchedulesResponse) Descriptor() ([]byte, []int) {
+	return file_proto_scraper_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *ListSchedulesResponse) GetSchedules() []*Schedule {
+	if x != nil {
+		return x.Schedules
+	}
+	return nil
+}
+
+type DeleteScheduleRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteScheduleRequest) Reset() {
+	*x = DeleteScheduleRequest{}
+	mi := &file_proto_scraper_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteScheduleRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteScheduleRequest) ProtoMessage() {}
+
+func (x *DeleteScheduleRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_scraper_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteScheduleRequest.ProtoReflect.Descriptor instead.
+func (*DeleteScheduleRequest) Descriptor() ([]byte, []int) {
+	return file_proto_scraper_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *DeleteScheduleRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type DeleteScheduleResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteScheduleResponse) Reset() {
+	*x = DeleteScheduleResponse{}
+	mi := &file_proto_scraper_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteScheduleResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteScheduleResponse) ProtoMessage() {}
+
+func (x *DeleteScheduleResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_scraper_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteScheduleResponse.ProtoReflect.Descriptor instead.
+func (*DeleteScheduleResponse) Descriptor() ([]byte, []int) {
+	return file_proto_scraper_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *DeleteScheduleResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+type GetNewRecordsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"` // 省略時は全アカウント
+	JobId         string                 `protobuf:"bytes,2,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`    // 省略時は各アカウントの前回の実行
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetNewRecordsRequest) Reset() {
+	*x = GetNewRecordsRequest{}
+	mi := &file_proto_scraper_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetNewRecordsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetNewRecordsRequest) ProtoMessage() {}
+
+func (x *GetNewRecordsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_scraper_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetNewRecordsRequest.ProtoReflect.Descriptor instead.
+func (*GetNewRecordsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_scraper_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *GetNewRecordsRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *GetNewRecordsRequest) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+type NewRecords struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	JobId         string                 `protobuf:"bytes,2,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	CsvPath       string                 `protobuf:"bytes,3,opt,name=csv_path,json=csvPath,proto3" json:"csv_path,omitempty"` // 取得したCSVファイル（圧縮済みのセッションでは圧縮前のパス）
+	Records       []*UsageRecord         `protobuf:"bytes,4,rep,name=records,proto3" json:"records,omitempty"`                // このジョブで初めて取得した明細のみ
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NewRecords) Reset() {
+	*x = NewRecords{}
+	mi := &file_proto_scraper_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NewRecords) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NewRecords) ProtoMessage() {}
+
+func (x *NewRecords) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_scraper_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NewRecords.ProtoReflect.Descriptor instead.
+func (*NewRecords) Descriptor() ([]byte, []int) {
+	return file_proto_scraper_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *NewRecords) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *NewRecords) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+func (x *NewRecords) GetCsvPath() string {
+	if x != nil {
+		return x.CsvPath
+	}
+	return ""
+}
+
+func (x *NewRecords) GetRecords() []*UsageRecord {
+	if x != nil {
+		return x.Records
+	}
+	return nil
+}
+
+type GetNewRecordsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Accounts      []*NewRecords          `protobuf:"bytes,1,rep,name=accounts,proto3" json:"accounts,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetNewRecordsResponse) Reset() {
+	*x = GetNewRecordsResponse{}
+	mi := &file_proto_scraper_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetNewRecordsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetNewRecordsResponse) ProtoMessage() {}
+
+func (x *GetNewRecordsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_scraper_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetNewRecordsResponse.ProtoReflect.Descriptor instead.
+func (*GetNewRecordsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_scraper_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *GetNewRecordsResponse) GetAccounts() []*NewRecords {
+	if x != nil {
+		return x.Accounts
+	}
+	return nil
+}
+
+type QueryRecordsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	FromDate      string                 `protobuf:"bytes,2,opt,name=from_date,json=fromDate,proto3" json:"from_date,omitempty"`                // 利用年月日（至）がこの日以降（YYYY-MM-DD）
+	ToDate        string                 `protobuf:"bytes,3,opt,name=to_date,json=toDate,proto3" json:"to_date,omitempty"`                      // 利用年月日（至）がこの日以前（YYYY-MM-DD）
+	CardNumber    string                 `protobuf:"bytes,4,opt,name=card_number,json=cardNumber,proto3" json:"card_number,omitempty"`          // カード番号の末尾（下4桁など）
+	VehicleNumber string                 `protobuf:"bytes,5,opt,name=vehicle_number,json=vehicleNumber,proto3" json:"vehicle_number,omitempty"` // 車両番号の一部
+	Interchange   string                 `protobuf:"bytes,6,opt,name=interchange,proto3" json:"interchange,omitempty"`                          // 入口または出口ICの一部
+	AllRuns       bool                   `protobuf:"varint,7,opt,name=all_runs,json=allRuns,proto3" json:"all_runs,omitempty"`                  // 複数の実行で取得した明細を実行ごとに返す（省略時は最新の1件のみ）
+	Limit         int32                  `protobuf:"varint,8,opt,name=limit,proto3" json:"limit,omitempty"`                                     // 最大件数（0は1000件）
+	Offset        int32                  `protobuf:"varint,9,opt,name=offset,proto3" json:"offset,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *QueryRecordsRequest) Reset() {
+	*x = QueryRecordsRequest{}
+	mi := &file_proto_scraper_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *QueryRecordsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QueryRecordsRequest) ProtoMessage() {}
+
+func (x *QueryRecordsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_scraper_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QueryRecordsRequest.ProtoReflect.Descriptor instead.
+func (*QueryRecordsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_scraper_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *QueryRecordsRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *QueryRecordsRequest) GetFromDate() string {
+	if x != nil {
+		return x.FromDate
+	}
+	return ""
+}
+
+func (x *QueryRecordsRequest) GetToDate() string {
+	if x != nil {
+		return x.ToDate
+	}
+	return ""
+}
+
+func (x *QueryRecordsRequest) GetCardNumber() string {
+	if x != nil {
+		return x.CardNumber
+	}
+	return ""
+}
+
+func (x *QueryRecordsRequest) GetVehicleNumber() string {
+	if x != nil {
+		return x.VehicleNumber
+	}
+	return ""
+}
+
+func (x *QueryRecordsRequest) GetInterchange() string {
+	if x != nil {
+		return x.Interchange
+	}
+	return ""
+}
+
+func (x *QueryRecordsRequest) GetAllRuns() bool {
+	if x != nil {
+		return x.AllRuns
+	}
+	return false
+}
+
+func (x *QueryRecordsRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *QueryRecordsRequest) GetOffset() int32 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+type HistoryRecord struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	JobId         string                 `protobuf:"bytes,2,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`       // 明細を取得したジョブ
+	CsvPath       string                 `protobuf:"bytes,3,opt,name=csv_path,json=csvPath,proto3" json:"csv_path,omitempty"` // 圧縮済みのセッションでは圧縮前のパス
+	Record        *UsageRecord           `protobuf:"bytes,4,opt,name=record,proto3" json:"record,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HistoryRecord) Reset() {
+	*x = HistoryRecord{}
+	mi := &file_proto_scraper_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HistoryRecord) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HistoryRecord) ProtoMessage() {}
+
+func (x *HistoryRecord) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_scraper_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HistoryRecord.ProtoReflect.Descriptor instead.
+func (*HistoryRecord) Descriptor() ([]byte, []int) {
+	return file_proto_scraper_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *HistoryRecord) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *HistoryRecord) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+func (x *HistoryRecord) GetCsvPath() string {
+	if x != nil {
+		return x.CsvPath
+	}
+	return ""
+}
+
+func (x *HistoryRecord) GetRecord() *UsageRecord {
+	if x != nil {
+		return x.Record
+	}
+	return nil
+}
+
+type QueryRecordsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Records       []*HistoryRecord       `protobuf:"bytes,1,rep,name=records,proto3" json:"records,omitempty"` // 利用日時の古い順
+	Total         int32                  `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"`    // limit・offset適用前の件数
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *QueryRecordsResponse) Reset() {
+	*x = QueryRecordsResponse{}
+	mi := &file_proto_scraper_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *QueryRecordsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QueryRecordsResponse) ProtoMessage() {}
+
+func (x *QueryRecordsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_scraper_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QueryRecordsResponse.ProtoReflect.Descriptor instead.
+func (*QueryRecordsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_scraper_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *QueryRecordsResponse) GetRecords() []*HistoryRecord {
+	if x != nil {
+		return x.Records
+	}
+	return nil
+}
+
+func (x *QueryRecordsResponse) GetTotal() int32 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+type ListRunsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`       // このアカウントを含む実行のみ
+	FromDate      string                 `protobuf:"bytes,2,opt,name=from_date,json=fromDate,proto3" json:"from_date,omitempty"` // 作成日がこの日以降（YYYY-MM-DD）
+	ToDate        string                 `protobuf:"bytes,3,opt,name=to_date,json=toDate,proto3" json:"to_date,omitempty"`       // 作成日がこの日以前（YYYY-MM-DD）
+	Limit         int32                  `protobuf:"varint,4,opt,name=limit,proto3" json:"limit,omitempty"`                      // 最大件数（0は1000件）
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListRunsRequest) Reset() {
+	*x = ListRunsRequest{}
+	mi := &file_proto_scraper_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListRunsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListRunsRequest) ProtoMessage() {}
+
+func (x *ListRunsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_scraper_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListRunsRequest.ProtoReflect.Descriptor instead.
+func (*ListRunsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_scraper_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *ListRunsRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *ListRunsRequest) GetFromDate() string {
+	if x != nil {
+		return x.FromDate
+	}
+	return ""
+}
+
+func (x *ListRunsRequest) GetToDate() string {
+	if x != nil {
+		return x.ToDate
+	}
+	return ""
+}
+
+func (x *ListRunsRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type ListRunsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Runs          []*Job                 `protobuf:"bytes,1,rep,name=runs,proto3" json:"runs,omitempty"` // 明細（records）は含まない
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListRunsResponse) Reset() {
+	*x = ListRunsResponse{}
+	mi := &file_proto_scraper_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListRunsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListRunsResponse) ProtoMessage() {}
+
+func (x *ListRunsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_scraper_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListRunsResponse.ProtoReflect.Descriptor instead.
+func (*ListRunsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_scraper_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *ListRunsResponse) GetRuns() []*Job {
+	if x != nil {
+		return x.Runs
+	}
+	return nil
+}
+
+type ListSessionsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PageSize      int32                  `protobuf:"varint,1,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`   // 1ページの件数（0は50件）
+	PageToken     string                 `protobuf:"bytes,2,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"` // 前のレスポンスのnext_page_token
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *DownloadedFile) Reset() {
-	*x = DownloadedFile{}
-	mi := &file_proto_scraper_proto_msgTypes[9]
+func (x *ListSessionsRequest) Reset() {
+	*x = ListSessionsRequest{}
+	mi := &file_proto_scraper_proto_msgTypes[36]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *DownloadedFile) String() string {
+func (x *ListSessionsRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DownloadedFile) ProtoMessage() {}
+func (*ListSessionsRequest) ProtoMessage() {}
 
-func (x *DownloadedFile) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_scraper_proto_msgTypes[9]
+func (x *ListSessionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_scraper_proto_msgTypes[36]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -530,48 +2651,114 @@ func (x *DownloadedFile) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DownloadedFile.ProtoReflect.Descriptor instead.
-func (*DownloadedFile) Descriptor() ([]byte, []int) {
-	return file_proto_scraper_proto_rawDescGZIP(), []int{9}
+// Deprecated: Use ListSessionsRequest.ProtoReflect.Descriptor instead.
+func (*ListSessionsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_scraper_proto_rawDescGZIP(), []int{36}
 }
 
-func (x *DownloadedFile) GetFilename() string {
+func (x *ListSessionsRequest) GetPageSize() int32 {
 	if x != nil {
-		return x.Filename
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListSessionsRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
 	}
 	return ""
 }
 
-func (x *DownloadedFile) GetContent() []byte {
+type SessionFile struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"` // セッションフォルダからの相対パス（例: original/user1_meisai.csv）
+	Size          int64                  `protobuf:"varint,2,opt,name=size,proto3" json:"size,omitempty"`
+	ModifiedAt    string                 `protobuf:"bytes,3,opt,name=modified_at,json=modifiedAt,proto3" json:"modified_at,omitempty"` // 更新日時（RFC3339）
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SessionFile) Reset() {
+	*x = SessionFile{}
+	mi := &file_proto_scraper_proto_msgTypes[37]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SessionFile) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SessionFile) ProtoMessage() {}
+
+func (x *SessionFile) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_scraper_proto_msgTypes[37]
 	if x != nil {
-		return x.Content
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return nil
+	return mi.MessageOf(x)
 }
 
-type GetDownloadedFilesResponse struct {
+// Deprecated: Use SessionFile.ProtoReflect.Descriptor instead.
+func (*SessionFile) Descriptor() ([]byte, []int) {
+	return file_proto_scraper_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *SessionFile) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *SessionFile) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *SessionFile) GetModifiedAt() string {
+	if x != nil {
+		return x.ModifiedAt
+	}
+	return ""
+}
+
+type Session struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Files         []*DownloadedFile      `protobuf:"bytes,1,rep,name=files,proto3" json:"files,omitempty"`
-	SessionFolder string                 `protobuf:"bytes,2,opt,name=session_folder,json=sessionFolder,proto3" json:"session_folder,omitempty"`
+	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`           // フォルダ名（YYYYMMDD_HHMMSS）
+	CreatedAt     string                 `protobuf:"bytes,2,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`           // 作成日時（RFC3339、フォルダ名から）
+	AccountCount  int32                  `protobuf:"varint,3,opt,name=account_count,json=accountCount,proto3" json:"account_count,omitempty"` // CSVをダウンロードしたアカウント数
+	FileCount     int32                  `protobuf:"varint,4,opt,name=file_count,json=fileCount,proto3" json:"file_count,omitempty"`
+	TotalBytes    int64                  `protobuf:"varint,5,opt,name=total_bytes,json=totalBytes,proto3" json:"total_bytes,omitempty"`
+	JobId         string                 `protobuf:"bytes,6,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"` // このセッションのジョブ（記録が残っている場合）
+	Files         []*SessionFile         `protobuf:"bytes,7,rep,name=files,proto3" json:"files,omitempty"`              // GetSessionのみ
+	Archived      bool                   `protobuf:"varint,8,opt,name=archived,proto3" json:"archived,omitempty"`       // 保持ポリシーで <session_id>.zip に圧縮済み
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetDownloadedFilesResponse) Reset() {
-	*x = GetDownloadedFilesResponse{}
-	mi := &file_proto_scraper_proto_msgTypes[10]
+func (x *Session) Reset() {
+	*x = Session{}
+	mi := &file_proto_scraper_proto_msgTypes[38]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetDownloadedFilesResponse) String() string {
+func (x *Session) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetDownloadedFilesResponse) ProtoMessage() {}
+func (*Session) ProtoMessage() {}
 
-func (x *GetDownloadedFilesResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_scraper_proto_msgTypes[10]
+func (x *Session) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_scraper_proto_msgTypes[38]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -582,73 +2769,508 @@ func (x *GetDownloadedFilesResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetDownloadedFilesResponse.ProtoReflect.Descriptor instead.
-func (*GetDownloadedFilesResponse) Descriptor() ([]byte, []int) {
-	return file_proto_scraper_proto_rawDescGZIP(), []int{10}
+// Deprecated: Use Session.ProtoReflect.Descriptor instead.
+func (*Session) Descriptor() ([]byte, []int) {
+	return file_proto_scraper_proto_rawDescGZIP(), []int{38}
 }
 
-func (x *GetDownloadedFilesResponse) GetFiles() []*DownloadedFile {
+func (x *Session) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *Session) GetCreatedAt() string {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return ""
+}
+
+func (x *Session) GetAccountCount() int32 {
+	if x != nil {
+		return x.AccountCount
+	}
+	return 0
+}
+
+func (x *Session) GetFileCount() int32 {
+	if x != nil {
+		return x.FileCount
+	}
+	return 0
+}
+
+func (x *Session) GetTotalBytes() int64 {
+	if x != nil {
+		return x.TotalBytes
+	}
+	return 0
+}
+
+func (x *Session) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+func (x *Session) GetFiles() []*SessionFile {
 	if x != nil {
 		return x.Files
 	}
 	return nil
 }
 
-func (x *GetDownloadedFilesResponse) GetSessionFolder() string {
+func (x *Session) GetArchived() bool {
 	if x != nil {
-		return x.SessionFolder
+		return x.Archived
+	}
+	return false
+}
+
+type ListSessionsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Sessions      []*Session             `protobuf:"bytes,1,rep,name=sessions,proto3" json:"sessions,omitempty"`
+	NextPageToken string                 `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"` // 最後のページでは空
+	Total         int32                  `protobuf:"varint,3,opt,name=total,proto3" json:"total,omitempty"`                                       // セッションの総数
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListSessionsResponse) Reset() {
+	*x = ListSessionsResponse{}
+	mi := &file_proto_scraper_proto_msgTypes[39]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSessionsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSessionsResponse) ProtoMessage() {}
+
+func (x *ListSessionsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_scraper_proto_msgTypes[39]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSessionsResponse.ProtoReflect.Descriptor instead.
+func (*ListSessionsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_scraper_proto_rawDescGZIP(), []int{39}
+}
+
+func (x *ListSessionsResponse) GetSessions() []*Session {
+	if x != nil {
+		return x.Sessions
+	}
+	return nil
+}
+
+func (x *ListSessionsResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+func (x *ListSessionsResponse) GetTotal() int32 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+type GetSessionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetSessionRequest) Reset() {
+	*x = GetSessionRequest{}
+	mi := &file_proto_scraper_proto_msgTypes[40]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetSessionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSessionRequest) ProtoMessage() {}
+
+func (x *GetSessionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_scraper_proto_msgTypes[40]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSessionRequest.ProtoReflect.Descriptor instead.
+func (*GetSessionRequest) Descriptor() ([]byte, []int) {
+	return file_proto_scraper_proto_rawDescGZIP(), []int{40}
+}
+
+func (x *GetSessionRequest) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+type GetFileRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	Filename      string                 `protobuf:"bytes,2,opt,name=filename,proto3" json:"filename,omitempty"`                            // GetSessionのfiles.name
+	Encoding      FileEncoding           `protobuf:"varint,3,opt,name=encoding,proto3,enum=scraper.FileEncoding" json:"encoding,omitempty"` // CSVファイルのみ適用
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetFileRequest) Reset() {
+	*x = GetFileRequest{}
+	mi := &file_proto_scraper_proto_msgTypes[41]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetFileRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetFileRequest) ProtoMessage() {}
+
+func (x *GetFileRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_scraper_proto_msgTypes[41]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetFileRequest.ProtoReflect.Descriptor instead.
+func (*GetFileRequest) Descriptor() ([]byte, []int) {
+	return file_proto_scraper_proto_rawDescGZIP(), []int{41}
+}
+
+func (x *GetFileRequest) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *GetFileRequest) GetFilename() string {
+	if x != nil {
+		return x.Filename
 	}
 	return ""
 }
 
+func (x *GetFileRequest) GetEncoding() FileEncoding {
+	if x != nil {
+		return x.Encoding
+	}
+	return FileEncoding_FILE_ENCODING_UTF8
+}
+
 var File_proto_scraper_proto protoreflect.FileDescriptor
 
 const file_proto_scraper_proto_rawDesc = "" +
 	"\n" +
-	"\x13proto/scraper.proto\x12\ascraper\"D\n" +
+	"\x13proto/scraper.proto\x12\ascraper\"\xdf\x01\n" +
 	"\rScrapeRequest\x12\x17\n" +
 	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x1a\n" +
-	"\bpassword\x18\x02 \x01(\tR\bpassword\"\x80\x01\n" +
+	"\bpassword\x18\x02 \x01(\tR\bpassword\x12\x1b\n" +
+	"\tfrom_date\x18\x03 \x01(\tR\bfromDate\x12\x17\n" +
+	"\ato_date\x18\x04 \x01(\tR\x06toDate\x12\x1f\n" +
+	"\vlast_months\x18\x05 \x01(\x05R\n" +
+	"lastMonths\x12!\n" +
+	"\fscraper_type\x18\x06 \x01(\tR\vscraperType\x12\x1f\n" +
+	"\vaccount_ref\x18\a \x01(\tR\n" +
+	"accountRef\"\xc7\x01\n" +
 	"\x0eScrapeResponse\x12\x18\n" +
 	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
 	"\amessage\x18\x02 \x01(\tR\amessage\x12\x19\n" +
 	"\bcsv_path\x18\x03 \x01(\tR\acsvPath\x12\x1f\n" +
 	"\vcsv_content\x18\x04 \x01(\tR\n" +
-	"csvContent\"E\n" +
+	"csvContent\x12.\n" +
+	"\arecords\x18\x05 \x03(\v2\x14.scraper.UsageRecordR\arecords\x12\x15\n" +
+	"\x06job_id\x18\x06 \x01(\tR\x05jobId\"E\n" +
 	"\x15ScrapeMultipleRequest\x12,\n" +
-	"\baccounts\x18\x01 \x03(\v2\x10.scraper.AccountR\baccounts\">\n" +
+	"\baccounts\x18\x01 \x03(\v2\x10.scraper.AccountR\baccounts\"\xd9\x01\n" +
 	"\aAccount\x12\x17\n" +
 	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x1a\n" +
-	"\bpassword\x18\x02 \x01(\tR\bpassword\"\x8f\x01\n" +
+	"\bpassword\x18\x02 \x01(\tR\bpassword\x12\x1b\n" +
+	"\tfrom_date\x18\x03 \x01(\tR\bfromDate\x12\x17\n" +
+	"\ato_date\x18\x04 \x01(\tR\x06toDate\x12\x1f\n" +
+	"\vlast_months\x18\x05 \x01(\x05R\n" +
+	"lastMonths\x12!\n" +
+	"\fscraper_type\x18\x06 \x01(\tR\vscraperType\x12\x1f\n" +
+	"\vaccount_ref\x18\a \x01(\tR\n" +
+	"accountRef\"\xa6\x01\n" +
 	"\x16ScrapeMultipleResponse\x12/\n" +
 	"\aresults\x18\x01 \x03(\v2\x15.scraper.ScrapeResultR\aresults\x12#\n" +
 	"\rsuccess_count\x18\x02 \x01(\x05R\fsuccessCount\x12\x1f\n" +
 	"\vtotal_count\x18\x03 \x01(\x05R\n" +
-	"totalCount\"\x97\x01\n" +
+	"totalCount\x12\x15\n" +
+	"\x06job_id\x18\x04 \x01(\tR\x05jobId\"\x88\x03\n" +
 	"\fScrapeResult\x12\x17\n" +
 	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x18\n" +
 	"\asuccess\x18\x02 \x01(\bR\asuccess\x12\x18\n" +
 	"\amessage\x18\x03 \x01(\tR\amessage\x12\x19\n" +
 	"\bcsv_path\x18\x04 \x01(\tR\acsvPath\x12\x1f\n" +
 	"\vcsv_content\x18\x05 \x01(\tR\n" +
-	"csvContent\"\x0f\n" +
-	"\rHealthRequest\"D\n" +
+	"csvContent\x12.\n" +
+	"\arecords\x18\x06 \x03(\v2\x14.scraper.UsageRecordR\arecords\x121\n" +
+	"\n" +
+	"error_code\x18\a \x01(\x0e2\x12.scraper.ErrorCodeR\terrorCode\x12'\n" +
+	"\x05state\x18\b \x01(\x0e2\x11.scraper.JobStateR\x05state\x12\x1d\n" +
+	"\n" +
+	"started_at\x18\t \x01(\tR\tstartedAt\x12\x1f\n" +
+	"\vfinished_at\x18\n" +
+	" \x01(\tR\n" +
+	"finishedAt\x12#\n" +
+	"\rhas_artifacts\x18\v \x01(\bR\fhasArtifacts\"\x8f\x03\n" +
+	"\vUsageRecord\x12\x1d\n" +
+	"\n" +
+	"entry_date\x18\x01 \x01(\tR\tentryDate\x12\x1d\n" +
+	"\n" +
+	"entry_time\x18\x02 \x01(\tR\tentryTime\x12\x1b\n" +
+	"\texit_date\x18\x03 \x01(\tR\bexitDate\x12\x1b\n" +
+	"\texit_time\x18\x04 \x01(\tR\bexitTime\x12\x19\n" +
+	"\bentry_ic\x18\x05 \x01(\tR\aentryIc\x12\x17\n" +
+	"\aexit_ic\x18\x06 \x01(\tR\x06exitIc\x12#\n" +
+	"\rvehicle_class\x18\a \x01(\tR\fvehicleClass\x12\x1f\n" +
+	"\vcard_number\x18\b \x01(\tR\n" +
+	"cardNumber\x12#\n" +
+	"\roriginal_toll\x18\t \x01(\x05R\foriginalToll\x12\x1a\n" +
+	"\bdiscount\x18\n" +
+	" \x01(\x05R\bdiscount\x12\x12\n" +
+	"\x04toll\x18\v \x01(\x05R\x04toll\x12%\n" +
+	"\x0evehicle_number\x18\f \x01(\tR\rvehicleNumber\x12\x12\n" +
+	"\x04note\x18\r \x01(\tR\x04note\"\x0f\n" +
+	"\rHealthRequest\"i\n" +
 	"\x0eHealthResponse\x12\x18\n" +
 	"\ahealthy\x18\x01 \x01(\bR\ahealthy\x12\x18\n" +
-	"\aversion\x18\x02 \x01(\tR\aversion\"\x1b\n" +
-	"\x19GetDownloadedFilesRequest\"F\n" +
+	"\aversion\x18\x02 \x01(\tR\aversion\x12#\n" +
+	"\rscraper_types\x18\x03 \x03(\tR\fscraperTypes\"N\n" +
+	"\x19GetDownloadedFilesRequest\x121\n" +
+	"\bencoding\x18\x01 \x01(\x0e2\x15.scraper.FileEncodingR\bencoding\"v\n" +
 	"\x0eDownloadedFile\x12\x1a\n" +
 	"\bfilename\x18\x01 \x01(\tR\bfilename\x12\x18\n" +
-	"\acontent\x18\x02 \x01(\fR\acontent\"r\n" +
+	"\acontent\x18\x02 \x01(\fR\acontent\x12.\n" +
+	"\arecords\x18\x03 \x03(\v2\x14.scraper.UsageRecordR\arecords\"r\n" +
 	"\x1aGetDownloadedFilesResponse\x12-\n" +
 	"\x05files\x18\x01 \x03(\v2\x17.scraper.DownloadedFileR\x05files\x12%\n" +
-	"\x0esession_folder\x18\x02 \x01(\tR\rsessionFolder2\xb4\x02\n" +
+	"\x0esession_folder\x18\x02 \x01(\tR\rsessionFolder\"\xc2\x02\n" +
+	"\x03Job\x12\x15\n" +
+	"\x06job_id\x18\x01 \x01(\tR\x05jobId\x12'\n" +
+	"\x05state\x18\x02 \x01(\x0e2\x11.scraper.JobStateR\x05state\x12%\n" +
+	"\x0esession_folder\x18\x03 \x01(\tR\rsessionFolder\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x04 \x01(\tR\tcreatedAt\x12\x1d\n" +
+	"\n" +
+	"started_at\x18\x05 \x01(\tR\tstartedAt\x12\x1f\n" +
+	"\vfinished_at\x18\x06 \x01(\tR\n" +
+	"finishedAt\x12/\n" +
+	"\aresults\x18\a \x03(\v2\x15.scraper.ScrapeResultR\aresults\x12#\n" +
+	"\rsuccess_count\x18\b \x01(\x05R\fsuccessCount\x12\x1f\n" +
+	"\vtotal_count\x18\t \x01(\x05R\n" +
+	"totalCount\"&\n" +
+	"\rGetJobRequest\x12\x15\n" +
+	"\x06job_id\x18\x01 \x01(\tR\x05jobId\"'\n" +
+	"\x0fListJobsRequest\x12\x14\n" +
+	"\x05limit\x18\x01 \x01(\x05R\x05limit\"4\n" +
+	"\x10ListJobsResponse\x12 \n" +
+	"\x04jobs\x18\x01 \x03(\v2\f.scraper.JobR\x04jobs\")\n" +
+	"\x10CancelJobRequest\x12\x15\n" +
+	"\x06job_id\x18\x01 \x01(\tR\x05jobId\"G\n" +
+	"\x11CancelJobResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\"\x95\x02\n" +
+	"\vScrapeEvent\x12\x15\n" +
+	"\x06job_id\x18\x01 \x01(\tR\x05jobId\x12#\n" +
+	"\raccount_index\x18\x02 \x01(\x05R\faccountIndex\x12\x17\n" +
+	"\auser_id\x18\x03 \x01(\tR\x06userId\x12*\n" +
+	"\x05stage\x18\x04 \x01(\x0e2\x14.scraper.ScrapeStageR\x05stage\x12\x18\n" +
+	"\amessage\x18\x05 \x01(\tR\amessage\x12\x1c\n" +
+	"\ttimestamp\x18\x06 \x01(\tR\ttimestamp\x12-\n" +
+	"\x06result\x18\a \x01(\v2\x15.scraper.ScrapeResultR\x06result\x12\x1e\n" +
+	"\x03job\x18\b \x01(\v2\f.scraper.JobR\x03job\"h\n" +
+	"\x13GetArtifactsRequest\x12\x15\n" +
+	"\x06job_id\x18\x01 \x01(\tR\x05jobId\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\x12!\n" +
+	"\finclude_data\x18\x03 \x01(\bR\vincludeData\"J\n" +
+	"\fArtifactFile\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x12\n" +
+	"\x04size\x18\x02 \x01(\x03R\x04size\x12\x12\n" +
+	"\x04data\x18\x03 \x01(\fR\x04data\"\x8c\x01\n" +
+	"\x10AccountArtifacts\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\x12\x1c\n" +
+	"\tdirectory\x18\x03 \x01(\tR\tdirectory\x12+\n" +
+	"\x05files\x18\x04 \x03(\v2\x15.scraper.ArtifactFileR\x05files\"d\n" +
+	"\x14GetArtifactsResponse\x12\x15\n" +
+	"\x06job_id\x18\x01 \x01(\tR\x05jobId\x125\n" +
+	"\baccounts\x18\x02 \x03(\v2\x19.scraper.AccountArtifactsR\baccounts\"\xb7\x02\n" +
+	"\bSchedule\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04cron\x18\x02 \x01(\tR\x04cron\x12\x1a\n" +
+	"\btimezone\x18\x03 \x01(\tR\btimezone\x12\x16\n" +
+	"\x06jitter\x18\x04 \x01(\tR\x06jitter\x12\x19\n" +
+	"\bcatch_up\x18\x05 \x01(\bR\acatchUp\x12\x1a\n" +
+	"\bdisabled\x18\x06 \x01(\bR\bdisabled\x12,\n" +
+	"\baccounts\x18\a \x03(\v2\x10.scraper.AccountR\baccounts\x12\x19\n" +
+	"\blast_run\x18\b \x01(\tR\alastRun\x12\x1e\n" +
+	"\vlast_job_id\x18\t \x01(\tR\tlastJobId\x12\x19\n" +
+	"\bnext_run\x18\n" +
+	" \x01(\tR\anextRun\x12\x18\n" +
+	"\arunning\x18\v \x01(\bR\arunning\"\x16\n" +
+	"\x14ListSchedulesRequest\"H\n" +
+	"\x15ListSchedulesResponse\x12/\n" +
+	"\tschedules\x18\x01 \x03(\v2\x11.scraper.ScheduleR\tschedules\"'\n" +
+	"\x15DeleteScheduleRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"2\n" +
+	"\x16DeleteScheduleResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\"F\n" +
+	"\x14GetNewRecordsRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x15\n" +
+	"\x06job_id\x18\x02 \x01(\tR\x05jobId\"\x87\x01\n" +
+	"\n" +
+	"NewRecords\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x15\n" +
+	"\x06job_id\x18\x02 \x01(\tR\x05jobId\x12\x19\n" +
+	"\bcsv_path\x18\x03 \x01(\tR\acsvPath\x12.\n" +
+	"\arecords\x18\x04 \x03(\v2\x14.scraper.UsageRecordR\arecords\"H\n" +
+	"\x15GetNewRecordsResponse\x12/\n" +
+	"\baccounts\x18\x01 \x03(\v2\x13.scraper.NewRecordsR\baccounts\"\x97\x02\n" +
+	"\x13QueryRecordsRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x1b\n" +
+	"\tfrom_date\x18\x02 \x01(\tR\bfromDate\x12\x17\n" +
+	"\ato_date\x18\x03 \x01(\tR\x06toDate\x12\x1f\n" +
+	"\vcard_number\x18\x04 \x01(\tR\n" +
+	"cardNumber\x12%\n" +
+	"\x0evehicle_number\x18\x05 \x01(\tR\rvehicleNumber\x12 \n" +
+	"\vinterchange\x18\x06 \x01(\tR\vinterchange\x12\x19\n" +
+	"\ball_runs\x18\a \x01(\bR\aallRuns\x12\x14\n" +
+	"\x05limit\x18\b \x01(\x05R\x05limit\x12\x16\n" +
+	"\x06offset\x18\t \x01(\x05R\x06offset\"\x88\x01\n" +
+	"\rHistoryRecord\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x15\n" +
+	"\x06job_id\x18\x02 \x01(\tR\x05jobId\x12\x19\n" +
+	"\bcsv_path\x18\x03 \x01(\tR\acsvPath\x12,\n" +
+	"\x06record\x18\x04 \x01(\v2\x14.scraper.UsageRecordR\x06record\"^\n" +
+	"\x14QueryRecordsResponse\x120\n" +
+	"\arecords\x18\x01 \x03(\v2\x16.scraper.HistoryRecordR\arecords\x12\x14\n" +
+	"\x05total\x18\x02 \x01(\x05R\x05total\"v\n" +
+	"\x0fListRunsRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x1b\n" +
+	"\tfrom_date\x18\x02 \x01(\tR\bfromDate\x12\x17\n" +
+	"\ato_date\x18\x03 \x01(\tR\x06toDate\x12\x14\n" +
+	"\x05limit\x18\x04 \x01(\x05R\x05limit\"4\n" +
+	"\x10ListRunsResponse\x12 \n" +
+	"\x04runs\x18\x01 \x03(\v2\f.scraper.JobR\x04runs\"Q\n" +
+	"\x13ListSessionsRequest\x12\x1b\n" +
+	"\tpage_size\x18\x01 \x01(\x05R\bpageSize\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\x02 \x01(\tR\tpageToken\"V\n" +
+	"\vSessionFile\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x12\n" +
+	"\x04size\x18\x02 \x01(\x03R\x04size\x12\x1f\n" +
+	"\vmodified_at\x18\x03 \x01(\tR\n" +
+	"modifiedAt\"\x8b\x02\n" +
+	"\aSession\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x01 \x01(\tR\tsessionId\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x02 \x01(\tR\tcreatedAt\x12#\n" +
+	"\raccount_count\x18\x03 \x01(\x05R\faccountCount\x12\x1d\n" +
+	"\n" +
+	"file_count\x18\x04 \x01(\x05R\tfileCount\x12\x1f\n" +
+	"\vtotal_bytes\x18\x05 \x01(\x03R\n" +
+	"totalBytes\x12\x15\n" +
+	"\x06job_id\x18\x06 \x01(\tR\x05jobId\x12*\n" +
+	"\x05files\x18\a \x03(\v2\x14.scraper.SessionFileR\x05files\x12\x1a\n" +
+	"\barchived\x18\b \x01(\bR\barchived\"\x82\x01\n" +
+	"\x14ListSessionsResponse\x12,\n" +
+	"\bsessions\x18\x01 \x03(\v2\x10.scraper.SessionR\bsessions\x12&\n" +
+	"\x0fnext_page_token\x18\x02 \x01(\tR\rnextPageToken\x12\x14\n" +
+	"\x05total\x18\x03 \x01(\x05R\x05total\"2\n" +
+	"\x11GetSessionRequest\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x01 \x01(\tR\tsessionId\"~\n" +
+	"\x0eGetFileRequest\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x01 \x01(\tR\tsessionId\x12\x1a\n" +
+	"\bfilename\x18\x02 \x01(\tR\bfilename\x121\n" +
+	"\bencoding\x18\x03 \x01(\x0e2\x15.scraper.FileEncodingR\bencoding*\xac\x01\n" +
+	"\tErrorCode\x12\x1a\n" +
+	"\x16ERROR_CODE_UNSPECIFIED\x10\x00\x12\"\n" +
+	"\x1eERROR_CODE_INVALID_CREDENTIALS\x10\x01\x12\x1d\n" +
+	"\x19ERROR_CODE_ACCOUNT_LOCKED\x10\x02\x12\x1f\n" +
+	"\x1bERROR_CODE_PASSWORD_EXPIRED\x10\x03\x12\x1f\n" +
+	"\x1bERROR_CODE_SITE_MAINTENANCE\x10\x04*C\n" +
+	"\fFileEncoding\x12\x16\n" +
+	"\x12FILE_ENCODING_UTF8\x10\x00\x12\x1b\n" +
+	"\x17FILE_ENCODING_SHIFT_JIS\x10\x01*\x9a\x01\n" +
+	"\bJobState\x12\x19\n" +
+	"\x15JOB_STATE_UNSPECIFIED\x10\x00\x12\x14\n" +
+	"\x10JOB_STATE_QUEUED\x10\x01\x12\x15\n" +
+	"\x11JOB_STATE_RUNNING\x10\x02\x12\x17\n" +
+	"\x13JOB_STATE_SUCCEEDED\x10\x03\x12\x14\n" +
+	"\x10JOB_STATE_FAILED\x10\x04\x12\x17\n" +
+	"\x13JOB_STATE_CANCELLED\x10\x05*\xfb\x01\n" +
+	"\vScrapeStage\x12\x1c\n" +
+	"\x18SCRAPE_STAGE_UNSPECIFIED\x10\x00\x12\x1b\n" +
+	"\x17SCRAPE_STAGE_INITIALIZE\x10\x01\x12\x16\n" +
+	"\x12SCRAPE_STAGE_LOGIN\x10\x02\x12\x17\n" +
+	"\x13SCRAPE_STAGE_SEARCH\x10\x03\x12\x1a\n" +
+	"\x16SCRAPE_STAGE_CSV_CLICK\x10\x04\x12\"\n" +
+	"\x1eSCRAPE_STAGE_DOWNLOAD_COMPLETE\x10\x05\x12!\n" +
+	"\x1dSCRAPE_STAGE_ACCOUNT_FINISHED\x10\x06\x12\x1d\n" +
+	"\x19SCRAPE_STAGE_JOB_FINISHED\x10\a2\xfa\t\n" +
 	"\n" +
 	"ETCScraper\x129\n" +
 	"\x06Scrape\x12\x16.scraper.ScrapeRequest\x1a\x17.scraper.ScrapeResponse\x12Q\n" +
-	"\x0eScrapeMultiple\x12\x1e.scraper.ScrapeMultipleRequest\x1a\x1f.scraper.ScrapeMultipleResponse\x129\n" +
+	"\x0eScrapeMultiple\x12\x1e.scraper.ScrapeMultipleRequest\x1a\x1f.scraper.ScrapeMultipleResponse\x12F\n" +
+	"\fScrapeStream\x12\x1e.scraper.ScrapeMultipleRequest\x1a\x14.scraper.ScrapeEvent0\x01\x129\n" +
 	"\x06Health\x12\x16.scraper.HealthRequest\x1a\x17.scraper.HealthResponse\x12]\n" +
-	"\x12GetDownloadedFiles\x12\".scraper.GetDownloadedFilesRequest\x1a#.scraper.GetDownloadedFilesResponseB\x1cZ\x1agithub.com/scrape-vm/protob\x06proto3"
+	"\x12GetDownloadedFiles\x12\".scraper.GetDownloadedFilesRequest\x1a#.scraper.GetDownloadedFilesResponse\x12.\n" +
+	"\x06GetJob\x12\x16.scraper.GetJobRequest\x1a\f.scraper.Job\x12?\n" +
+	"\bListJobs\x12\x18.scraper.ListJobsRequest\x1a\x19.scraper.ListJobsResponse\x12B\n" +
+	"\tCancelJob\x12\x19.scraper.CancelJobRequest\x1a\x1a.scraper.CancelJobResponse\x12K\n" +
+	"\fGetArtifacts\x12\x1c.scraper.GetArtifactsRequest\x1a\x1d.scraper.GetArtifactsResponse\x12N\n" +
+	"\rListSchedules\x12\x1d.scraper.ListSchedulesRequest\x1a\x1e.scraper.ListSchedulesResponse\x123\n" +
+	"\vPutSchedule\x12\x11.scraper.Schedule\x1a\x11.scraper.Schedule\x12Q\n" +
+	"\x0eDeleteSchedule\x12\x1e.scraper.DeleteScheduleRequest\x1a\x1f.scraper.DeleteScheduleResponse\x12N\n" +
+	"\rGetNewRecords\x12\x1d.scraper.GetNewRecordsRequest\x1a\x1e.scraper.GetNewRecordsResponse\x12K\n" +
+	"\fQueryRecords\x12\x1c.scraper.QueryRecordsRequest\x1a\x1d.scraper.QueryRecordsResponse\x12?\n" +
+	"\bListRuns\x12\x18.scraper.ListRunsRequest\x1a\x19.scraper.ListRunsResponse\x12K\n" +
+	"\fListSessions\x12\x1c.scraper.ListSessionsRequest\x1a\x1d.scraper.ListSessionsResponse\x12:\n" +
+	"\n" +
+	"GetSession\x12\x1a.scraper.GetSessionRequest\x1a\x10.scraper.Session\x12;\n" +
+	"\aGetFile\x12\x17.scraper.GetFileRequest\x1a\x17.scraper.DownloadedFileB\x1cZ\x1agithub.com/scrape-vm/protob\x06proto3"
 
 var (
 	file_proto_scraper_proto_rawDescOnce sync.Once
@@ -662,37 +3284,125 @@ func file_proto_scraper_proto_rawDescGZIP() []byte {
 	return file_proto_scraper_proto_rawDescData
 }
 
-var file_proto_scraper_proto_msgTypes = make([]protoimpl.MessageInfo, 11)
+var file_proto_scraper_proto_enumTypes = make([]protoimpl.EnumInfo, 4)
+var file_proto_scraper_proto_msgTypes = make([]protoimpl.MessageInfo, 42)
 var file_proto_scraper_proto_goTypes = []any{
-	(*ScrapeRequest)(nil),              // 0: scraper.ScrapeRequest
-	(*ScrapeResponse)(nil),             // 1: scraper.ScrapeResponse
-	(*ScrapeMultipleRequest)(nil),      // 2: scraper.ScrapeMultipleRequest
-	(*Account)(nil),                    // 3: scraper.Account
-	(*ScrapeMultipleResponse)(nil),     // 4: scraper.ScrapeMultipleResponse
-	(*ScrapeResult)(nil),               // 5: scraper.ScrapeResult
-	(*HealthRequest)(nil),              // 6: scraper.HealthRequest
-	(*HealthResponse)(nil),             // 7: scraper.HealthResponse
-	(*GetDownloadedFilesRequest)(nil),  // 8: scraper.GetDownloadedFilesRequest
-	(*DownloadedFile)(nil),             // 9: scraper.DownloadedFile
-	(*GetDownloadedFilesResponse)(nil), // 10: scraper.GetDownloadedFilesResponse
+	(ErrorCode)(0),                     // 0: scraper.ErrorCode
+	(FileEncoding)(0),                  // 1: scraper.FileEncoding
+	(JobState)(0),                      // 2: scraper.JobState
+	(ScrapeStage)(0),                   // 3: scraper.ScrapeStage
+	(*ScrapeRequest)(nil),              // 4: scraper.ScrapeRequest
+	(*ScrapeResponse)(nil),             // 5: scraper.ScrapeResponse
+	(*ScrapeMultipleRequest)(nil),      // 6: scraper.ScrapeMultipleRequest
+	(*Account)(nil),                    // 7: scraper.Account
+	(*ScrapeMultipleResponse)(nil),     // 8: scraper.ScrapeMultipleResponse
+	(*ScrapeResult)(nil),               // 9: scraper.ScrapeResult
+	(*UsageRecord)(nil),                // 10: scraper.UsageRecord
+	(*HealthRequest)(nil),              // 11: scraper.HealthRequest
+	(*HealthResponse)(nil),             // 12: scraper.HealthResponse
+	(*GetDownloadedFilesRequest)(nil),  // 13: scraper.GetDownloadedFilesRequest
+	(*DownloadedFile)(nil),             // 14: scraper.DownloadedFile
+	(*GetDownloadedFilesResponse)(nil), // 15: scraper.GetDownloadedFilesResponse
+	(*Job)(nil),                        // 16: scraper.Job
+	(*GetJobRequest)(nil),              // 17: scraper.GetJobRequest
+	(*ListJobsRequest)(nil),            // 18: scraper.ListJobsRequest
+	(*ListJobsResponse)(nil),           // 19: scraper.ListJobsResponse
+	(*CancelJobRequest)(nil),           // 20: scraper.CancelJobRequest
+	(*CancelJobResponse)(nil),          // 21: scraper.CancelJobResponse
+	(*ScrapeEvent)(nil),                // 22: scraper.ScrapeEvent
+	(*GetArtifactsRequest)(nil),        // 23: scraper.GetArtifactsRequest
+	(*ArtifactFile)(nil),               // 24: scraper.ArtifactFile
+	(*AccountArtifacts)(nil),           // 25: scraper.AccountArtifacts
+	(*GetArtifactsResponse)(nil),       // 26: scraper.GetArtifactsResponse
+	(*Schedule)(nil),                   // 27: scraper.Schedule
+	(*ListSchedulesRequest)(nil),       // 28: scraper.ListSchedulesRequest
+	(*ListSchedulesResponse)(nil),      // 29: scraper.ListSchedulesResponse
+	(*DeleteScheduleRequest)(nil),      // 30: scraper.DeleteScheduleRequest
+	(*DeleteScheduleResponse)(nil),     // 31: scraper.DeleteScheduleResponse
+	(*GetNewRecordsRequest)(nil),       // 32: scraper.GetNewRecordsRequest
+	(*NewRecords)(nil),                 // 33: scraper.NewRecords
+	(*GetNewRecordsResponse)(nil),      // 34: scraper.GetNewRecordsResponse
+	(*QueryRecordsRequest)(nil),        // 35: scraper.QueryRecordsRequest
+	(*HistoryRecord)(nil),              // 36: scraper.HistoryRecord
+	(*QueryRecordsResponse)(nil),       // 37: scraper.QueryRecordsResponse
+	(*ListRunsRequest)(nil),            // 38: scraper.ListRunsRequest
+	(*ListRunsResponse)(nil),           // 39: scraper.ListRunsResponse
+	(*ListSessionsRequest)(nil),        // 40: scraper.ListSessionsRequest
+	(*SessionFile)(nil),                // 41: scraper.SessionFile
+	(*Session)(nil),                    // 42: scraper.Session
+	(*ListSessionsResponse)(nil),       // 43: scraper.ListSessionsResponse
+	(*GetSessionRequest)(nil),          // 44: scraper.GetSessionRequest
+	(*GetFileRequest)(nil),             // 45: scraper.GetFileRequest
 }
 var file_proto_scraper_proto_depIdxs = []int32{
-	3,  // 0: scraper.ScrapeMultipleRequest.accounts:type_name -> scraper.Account
-	5,  // 1: scraper.ScrapeMultipleResponse.results:type_name -> scraper.ScrapeResult
-	9,  // 2: scraper.GetDownloadedFilesResponse.files:type_name -> scraper.DownloadedFile
-	0,  // 3: scraper.ETCScraper.Scrape:input_type -> scraper.ScrapeRequest
-	2,  // 4: scraper.ETCScraper.ScrapeMultiple:input_type -> scraper.ScrapeMultipleRequest
-	6,  // 5: scraper.ETCScraper.Health:input_type -> scraper.HealthRequest
-	8,  // 6: scraper.ETCScraper.GetDownloadedFiles:input_type -> scraper.GetDownloadedFilesRequest
-	1,  // 7: scraper.ETCScraper.Scrape:output_type -> scraper.ScrapeResponse
-	4,  // 8: scraper.ETCScraper.ScrapeMultiple:output_type -> scraper.ScrapeMultipleResponse
-	7,  // 9: scraper.ETCScraper.Health:output_type -> scraper.HealthResponse
-	10, // 10: scraper.ETCScraper.GetDownloadedFiles:output_type -> scraper.GetDownloadedFilesResponse
-	7,  // [7:11] is the sub-list for method output_type
-	3,  // [3:7] is the sub-list for method input_type
-	3,  // [3:3] is the sub-list for extension type_name
-	3,  // [3:3] is the sub-list for extension extendee
-	0,  // [0:3] is the sub-list for field type_name
+	10, // 0: scraper.ScrapeResponse.records:type_name -> scraper.UsageRecord
+	7,  // 1: scraper.ScrapeMultipleRequest.accounts:type_name -> scraper.Account
+	9,  // 2: scraper.ScrapeMultipleResponse.results:type_name -> scraper.ScrapeResult
+	10, // 3: scraper.ScrapeResult.records:type_name -> scraper.UsageRecord
+	0,  // 4: scraper.ScrapeResult.error_code:type_name -> scraper.ErrorCode
+	2,  // 5: scraper.ScrapeResult.state:type_name -> scraper.JobState
+	1,  // 6: scraper.GetDownloadedFilesRequest.encoding:type_name -> scraper.FileEncoding
+	10, // 7: scraper.DownloadedFile.records:type_name -> scraper.UsageRecord
+	14, // 8: scraper.GetDownloadedFilesResponse.files:type_name -> scraper.DownloadedFile
+	2,  // 9: scraper.Job.state:type_name -> scraper.JobState
+	9,  // 10: scraper.Job.results:type_name -> scraper.ScrapeResult
+	16, // 11: scraper.ListJobsResponse.jobs:type_name -> scraper.Job
+	3,  // 12: scraper.ScrapeEvent.stage:type_name -> scraper.ScrapeStage
+	9,  // 13: scraper.ScrapeEvent.result:type_name -> scraper.ScrapeResult
+	16, // 14: scraper.ScrapeEvent.job:type_name -> scraper.Job
+	24, // 15: scraper.AccountArtifacts.files:type_name -> scraper.ArtifactFile
+	25, // 16: scraper.GetArtifactsResponse.accounts:type_name -> scraper.AccountArtifacts
+	7,  // 17: scraper.Schedule.accounts:type_name -> scraper.Account
+	27, // 18: scraper.ListSchedulesResponse.schedules:type_name -> scraper.Schedule
+	10, // 19: scraper.NewRecords.records:type_name -> scraper.UsageRecord
+	33, // 20: scraper.GetNewRecordsResponse.accounts:type_name -> scraper.NewRecords
+	10, // 21: scraper.HistoryRecord.record:type_name -> scraper.UsageRecord
+	36, // 22: scraper.QueryRecordsResponse.records:type_name -> scraper.HistoryRecord
+	16, // 23: scraper.ListRunsResponse.runs:type_name -> scraper.Job
+	41, // 24: scraper.Session.files:type_name -> scraper.SessionFile
+	42, // 25: scraper.ListSessionsResponse.sessions:type_name -> scraper.Session
+	1,  // 26: scraper.GetFileRequest.encoding:type_name -> scraper.FileEncoding
+	4,  // 27: scraper.ETCScraper.Scrape:input_type -> scraper.ScrapeRequest
+	6,  // 28: scraper.ETCScraper.ScrapeMultiple:input_type -> scraper.ScrapeMultipleRequest
+	6,  // 29: scraper.ETCScraper.ScrapeStream:input_type -> scraper.ScrapeMultipleRequest
+	11, // 30: scraper.ETCScraper.Health:input_type -> scraper.HealthRequest
+	13, // 31: scraper.ETCScraper.GetDownloadedFiles:input_type -> scraper.GetDownloadedFilesRequest
+	17, // 32: scraper.ETCScraper.GetJob:input_type -> scraper.GetJobRequest
+	18, // 33: scraper.ETCScraper.ListJobs:input_type -> scraper.ListJobsRequest
+	20, // 34: scraper.ETCScraper.CancelJob:input_type -> scraper.CancelJobRequest
+	23, // 35: scraper.ETCScraper.GetArtifacts:input_type -> scraper.GetArtifactsRequest
+	28, // 36: scraper.ETCScraper.ListSchedules:input_type -> scraper.ListSchedulesRequest
+	27, // 37: scraper.ETCScraper.PutSchedule:input_type -> scraper.Schedule
+	30, // 38: scraper.ETCScraper.DeleteSchedule:input_type -> scraper.DeleteScheduleRequest
+	32, // 39: scraper.ETCScraper.GetNewRecords:input_type -> scraper.GetNewRecordsRequest
+	35, // 40: scraper.ETCScraper.QueryRecords:input_type -> scraper.QueryRecordsRequest
+	38, // 41: scraper.ETCScraper.ListRuns:input_type -> scraper.ListRunsRequest
+	40, // 42: scraper.ETCScraper.ListSessions:input_type -> scraper.ListSessionsRequest
+	44, // 43: scraper.ETCScraper.GetSession:input_type -> scraper.GetSessionRequest
+	45, // 44: scraper.ETCScraper.GetFile:input_type -> scraper.GetFileRequest
+	5,  // 45: scraper.ETCScraper.Scrape:output_type -> scraper.ScrapeResponse
+	8,  // 46: scraper.ETCScraper.ScrapeMultiple:output_type -> scraper.ScrapeMultipleResponse
+	22, // 47: scraper.ETCScraper.ScrapeStream:output_type -> scraper.ScrapeEvent
+	12, // 48: scraper.ETCScraper.Health:output_type -> scraper.HealthResponse
+	15, // 49: scraper.ETCScraper.GetDownloadedFiles:output_type -> scraper.GetDownloadedFilesResponse
+	16, // 50: scraper.ETCScraper.GetJob:output_type -> scraper.Job
+	19, // 51: scraper.ETCScraper.ListJobs:output_type -> scraper.ListJobsResponse
+	21, // 52: scraper.ETCScraper.CancelJob:output_type -> scraper.CancelJobResponse
+	26, // 53: scraper.ETCScraper.GetArtifacts:output_type -> scraper.GetArtifactsResponse
+	29, // 54: scraper.ETCScraper.ListSchedules:output_type -> scraper.ListSchedulesResponse
+	27, // 55: scraper.ETCScraper.PutSchedule:output_type -> scraper.Schedule
+	31, // 56: scraper.ETCScraper.DeleteSchedule:output_type -> scraper.DeleteScheduleResponse
+	34, // 57: scraper.ETCScraper.GetNewRecords:output_type -> scraper.GetNewRecordsResponse
+	37, // 58: scraper.ETCScraper.QueryRecords:output_type -> scraper.QueryRecordsResponse
+	39, // 59: scraper.ETCScraper.ListRuns:output_type -> scraper.ListRunsResponse
+	43, // 60: scraper.ETCScraper.ListSessions:output_type -> scraper.ListSessionsResponse
+	42, // 61: scraper.ETCScraper.GetSession:output_type -> scraper.Session
+	14, // 62: scraper.ETCScraper.GetFile:output_type -> scraper.DownloadedFile
+	45, // [45:63] is the sub-list for method output_type
+	27, // [27:45] is the sub-list for method input_type
+	27, // [27:27] is the sub-list for extension type_name
+	27, // [27:27] is the sub-list for extension extendee
+	0,  // [0:27] is the sub-list for field type_name
 }
 
 func init() { file_proto_scraper_proto_init() }
@@ -705,13 +3415,14 @@ func file_proto_scraper_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_scraper_proto_rawDesc), len(file_proto_scraper_proto_rawDesc)),
-			NumEnums:      0,
-			NumMessages:   11,
+			NumEnums:      4,
+			NumMessages:   42,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
 		GoTypes:           file_proto_scraper_proto_goTypes,
 		DependencyIndexes: file_proto_scraper_proto_depIdxs,
+		EnumInfos:         file_proto_scraper_proto_enumTypes,
 		MessageInfos:      file_proto_scraper_proto_msgTypes,
 	}.Build()
 	File_proto_scraper_proto = out.File
@@ -21,8 +21,22 @@ const _ = grpc.SupportPackageIsVersion9
 const (
 	ETCScraper_Scrape_FullMethodName             = "/scraper.ETCScraper/Scrape"
 	ETCScraper_ScrapeMultiple_FullMethodName     = "/scraper.ETCScraper/ScrapeMultiple"
+	ETCScraper_ScrapeStream_FullMethodName       = "/scraper.ETCScraper/ScrapeStream"
 	ETCScraper_Health_FullMethodName             = "/scraper.ETCScraper/Health"
 	ETCScraper_GetDownloadedFiles_FullMethodName = "/scraper.ETCScraper/GetDownloadedFiles"
+	ETCScraper_GetJob_FullMethodName             = "/scraper.ETCScraper/GetJob"
+	ETCScraper_ListJobs_FullMethodName           = "/scraper.ETCScraper/ListJobs"
+	ETCScraper_CancelJob_FullMethodName          = "/scraper.ETCScraper/CancelJob"
+	ETCScraper_GetArtifacts_FullMethodName       = "/scraper.ETCScraper/GetArtifacts"
+	ETCScraper_ListSchedules_FullMethodName      = "/scraper.ETCScraper/ListSchedules"
+	ETCScraper_PutSchedule_FullMethodName        = "/scraper.ETCScraper/PutSchedule"
+	ETCScraper_DeleteSchedule_FullMethodName     = "/scraper.ETCScraper/DeleteSchedule"
+	ETCScraper_GetNewRecords_FullMethodName      = "/scraper.ETCScraper/GetNewRecords"
+	ETCScraper_QueryRecords_FullMethodName       = "/scraper.ETCScraper/QueryRecords"
+	ETCScraper_ListRuns_FullMethodName           = "/scraper.ETCScraper/ListRuns"
+	ETCScraper_ListSessions_FullMethodName       = "/scraper.ETCScraper/ListSessions"
+	ETCScraper_GetSession_FullMethodName         = "/scraper.ETCScraper/GetSession"
+	ETCScraper_GetFile_FullMethodName            = "/scraper.ETCScraper/GetFile"
 )
 
 // ETCScraperClient is the client API for ETCScraper service.
@@ -33,10 +47,38 @@ type ETCScraperClient interface {
 	Scrape(ctx context.Context, in *ScrapeRequest, opts ...grpc.CallOption) (*ScrapeResponse, error)
 	// 複数アカウントのスクレイピング
 	ScrapeMultiple(ctx context.Context, in *ScrapeMultipleRequest, opts ...grpc.CallOption) (*ScrapeMultipleResponse, error)
+	// 複数アカウントのスクレイピング（進捗をストリームで返却）
+	ScrapeStream(ctx context.Context, in *ScrapeMultipleRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ScrapeEvent], error)
 	// ヘルスチェック
 	Health(ctx context.Context, in *HealthRequest, opts ...grpc.CallOption) (*HealthResponse, error)
 	// ダウンロード済みファイルの取得
 	GetDownloadedFiles(ctx context.Context, in *GetDownloadedFilesRequest, opts ...grpc.CallOption) (*GetDownloadedFilesResponse, error)
+	// ジョブの状態取得
+	GetJob(ctx context.Context, in *GetJobRequest, opts ...grpc.CallOption) (*Job, error)
+	// ジョブ一覧（新しい順）
+	ListJobs(ctx context.Context, in *ListJobsRequest, opts ...grpc.CallOption) (*ListJobsResponse, error)
+	// ジョブのキャンセル
+	CancelJob(ctx context.Context, in *CancelJobRequest, opts ...grpc.CallOption) (*CancelJobResponse, error)
+	// 失敗時の診断ファイル（スクリーンショット・HTML・URL・コンソールログ）の取得
+	GetArtifacts(ctx context.Context, in *GetArtifactsRequest, opts ...grpc.CallOption) (*GetArtifactsResponse, error)
+	// 定期実行スケジュールの一覧（サービスモード）
+	ListSchedules(ctx context.Context, in *ListSchedulesRequest, opts ...grpc.CallOption) (*ListSchedulesResponse, error)
+	// 定期実行スケジュールの作成・更新（同じidは置き換え）
+	PutSchedule(ctx context.Context, in *Schedule, opts ...grpc.CallOption) (*Schedule, error)
+	// 定期実行スケジュールの削除
+	DeleteSchedule(ctx context.Context, in *DeleteScheduleRequest, opts ...grpc.CallOption) (*DeleteScheduleResponse, error)
+	// 前回の実行で初めて取得した利用明細（過去のセッションと重複する行を除く）
+	GetNewRecords(ctx context.Context, in *GetNewRecordsRequest, opts ...grpc.CallOption) (*GetNewRecordsResponse, error)
+	// 履歴データベースの利用明細の検索（アカウント・期間・カード・車両・IC）
+	QueryRecords(ctx context.Context, in *QueryRecordsRequest, opts ...grpc.CallOption) (*QueryRecordsResponse, error)
+	// 履歴データベースの実行履歴（新しい順）
+	ListRuns(ctx context.Context, in *ListRunsRequest, opts ...grpc.CallOption) (*ListRunsResponse, error)
+	// セッションフォルダの一覧（新しい順、ページング）
+	ListSessions(ctx context.Context, in *ListSessionsRequest, opts ...grpc.CallOption) (*ListSessionsResponse, error)
+	// セッションフォルダの詳細（ファイル一覧）
+	GetSession(ctx context.Context, in *GetSessionRequest, opts ...grpc.CallOption) (*Session, error)
+	// セッションフォルダ内のファイルの取得
+	GetFile(ctx context.Context, in *GetFileRequest, opts ...grpc.CallOption) (*DownloadedFile, error)
 }
 
 type eTCScraperClient struct {
@@ -67,6 +109,25 @@ func (c *eTCScraperClient) ScrapeMultiple(ctx context.Context, in *ScrapeMultipl
 	return out, nil
 }
 
+func (c *eTCScraperClient) ScrapeStream(ctx context.Context, in *ScrapeMultipleRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ScrapeEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &ETCScraper_ServiceDesc.Streams[0], ETCScraper_ScrapeStream_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ScrapeMultipleRequest, ScrapeEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ETCScraper_ScrapeStreamClient = grpc.ServerStreamingClient[ScrapeEvent]
+
 func (c *eTCScraperClient) Health(ctx context.Context, in *HealthRequest, opts ...grpc.CallOption) (*HealthResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(HealthResponse)
@@ -87,6 +148,136 @@ func (c *eTCScraperClient) GetDownloadedFiles(ctx context.Context, in *GetDownlo
 	return out, nil
 }
 
+func (c *eTCScraperClient) GetJob(ctx context.Context, in *GetJobRequest, opts ...grpc.CallOption) (*Job, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Job)
+	err := c.cc.Invoke(ctx, ETCScraper_GetJob_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eTCScraperClient) ListJobs(ctx context.Context, in *ListJobsRequest, opts ...grpc.CallOption) (*ListJobsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListJobsResponse)
+	err := c.cc.Invoke(ctx, ETCScraper_ListJobs_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eTCScraperClient) CancelJob(ctx context.Context, in *CancelJobRequest, opts ...grpc.CallOption) (*CancelJobResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CancelJobResponse)
+	err := c.cc.Invoke(ctx, ETCScraper_CancelJob_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eTCScraperClient) GetArtifacts(ctx context.Context, in *GetArtifactsRequest, opts ...grpc.CallOption) (*GetArtifactsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetArtifactsResponse)
+	err := c.cc.Invoke(ctx, ETCScraper_GetArtifacts_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eTCScraperClient) ListSchedules(ctx context.Context, in *ListSchedulesRequest, opts ...grpc.CallOption) (*ListSchedulesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListSchedulesResponse)
+	err := c.cc.Invoke(ctx, ETCScraper_ListSchedules_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eTCScraperClient) PutSchedule(ctx context.Context, in *Schedule, opts ...grpc.CallOption) (*Schedule, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Schedule)
+	err := c.cc.Invoke(ctx, ETCScraper_PutSchedule_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eTCScraperClient) DeleteSchedule(ctx context.Context, in *DeleteScheduleRequest, opts ...grpc.CallOption) (*DeleteScheduleResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteScheduleResponse)
+	err := c.cc.Invoke(ctx, ETCScraper_DeleteSchedule_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eTCScraperClient) GetNewRecords(ctx context.Context, in *GetNewRecordsRequest, opts ...grpc.CallOption) (*GetNewRecordsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetNewRecordsResponse)
+	err := c.cc.Invoke(ctx, ETCScraper_GetNewRecords_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eTCScraperClient) QueryRecords(ctx context.Context, in *QueryRecordsRequest, opts ...grpc.CallOption) (*QueryRecordsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(QueryRecordsResponse)
+	err := c.cc.Invoke(ctx, ETCScraper_QueryRecords_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eTCScraperClient) ListRuns(ctx context.Context, in *ListRunsRequest, opts ...grpc.CallOption) (*ListRunsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListRunsResponse)
+	err := c.cc.Invoke(ctx, ETCScraper_ListRuns_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eTCScraperClient) ListSessions(ctx context.Context, in *ListSessionsRequest, opts ...grpc.CallOption) (*ListSessionsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListSessionsResponse)
+	err := c.cc.Invoke(ctx, ETCScraper_ListSessions_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eTCScraperClient) GetSession(ctx context.Context, in *GetSessionRequest, opts ...grpc.CallOption) (*Session, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Session)
+	err := c.cc.Invoke(ctx, ETCScraper_GetSession_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eTCScraperClient) GetFile(ctx context.Context, in *GetFileRequest, opts ...grpc.CallOption) (*DownloadedFile, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DownloadedFile)
+	err := c.cc.Invoke(ctx, ETCScraper_GetFile_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // ETCScraperServer is the server API for ETCScraper service.
 // All implementations must embed UnimplementedETCScraperServer
 // for forward compatibility.
@@ -95,10 +286,38 @@ type ETCScraperServer interface {
 	Scrape(context.Context, *ScrapeRequest) (*ScrapeResponse, error)
 	// 複数アカウントのスクレイピング
 	ScrapeMultiple(context.Context, *ScrapeMultipleRequest) (*ScrapeMultipleResponse, error)
+	// 複数アカウントのスクレイピング（進捗をストリームで返却）
+	ScrapeStream(*ScrapeMultipleRequest, grpc.ServerStreamingServer[ScrapeEvent]) error
 	// ヘルスチェック
 	Health(context.Context, *HealthRequest) (*HealthResponse, error)
 	// ダウンロード済みファイルの取得
 	GetDownloadedFiles(context.Context, *GetDownloadedFilesRequest) (*GetDownloadedFilesResponse, error)
+	// ジョブの状態取得
+	GetJob(context.Context, *GetJobRequest) (*Job, error)
+	// ジョブ一覧（新しい順）
+	ListJobs(context.Context, *ListJobsRequest) (*ListJobsResponse, error)
+	// ジョブのキャンセル
+	CancelJob(context.Context, *CancelJobRequest) (*CancelJobResponse, error)
+	// 失敗時の診断ファイル（スクリーンショット・HTML・URL・コンソールログ）の取得
+	GetArtifacts(context.Context, *GetArtifactsRequest) (*GetArtifactsResponse, error)
+	// 定期実行スケジュールの一覧（サービスモード）
+	ListSchedules(context.Context, *ListSchedulesRequest) (*ListSchedulesResponse, error)
+	// 定期実行スケジュールの作成・更新（同じidは置き換え）
+	PutSchedule(context.Context, *Schedule) (*Schedule, error)
+	// 定期実行スケジュールの削除
+	DeleteSchedule(context.Context, *DeleteScheduleRequest) (*DeleteScheduleResponse, error)
+	// 前回の実行で初めて取得した利用明細（過去のセッションと重複する行を除く）
+	GetNewRecords(context.Context, *GetNewRecordsRequest) (*GetNewRecordsResponse, error)
+	// 履歴データベースの利用明細の検索（アカウント・期間・カード・車両・IC）
+	QueryRecords(context.Context, *QueryRecordsRequest) (*QueryRecordsResponse, error)
+	// 履歴データベースの実行履歴（新しい順）
+	ListRuns(context.Context, *ListRunsRequest) (*ListRunsResponse, error)
+	// セッションフォルダの一覧（新しい順、ページング）
+	ListSessions(context.Context, *ListSessionsRequest) (*ListSessionsResponse, error)
+	// セッションフォルダの詳細（ファイル一覧）
+	GetSession(context.Context, *GetSessionRequest) (*Session, error)
+	// セッションフォルダ内のファイルの取得
+	GetFile(context.Context, *GetFileRequest) (*DownloadedFile, error)
 	mustEmbedUnimplementedETCScraperServer()
 }
 
@@ -115,12 +334,54 @@ func (UnimplementedETCScraperServer) Scrape(context.Context, *ScrapeRequest) (*S
 func (UnimplementedETCScraperServer) ScrapeMultiple(context.Context, *ScrapeMultipleRequest) (*ScrapeMultipleResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method ScrapeMultiple not implemented")
 }
+func (UnimplementedETCScraperServer) ScrapeStream(*ScrapeMultipleRequest, grpc.ServerStreamingServer[ScrapeEvent]) error {
+	return status.Error(codes.Unimplemented, "method ScrapeStream not implemented")
+}
 func (UnimplementedETCScraperServer) Health(context.Context, *HealthRequest) (*HealthResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method Health not implemented")
 }
 func (UnimplementedETCScraperServer) GetDownloadedFiles(context.Context, *GetDownloadedFilesRequest) (*GetDownloadedFilesResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method GetDownloadedFiles not implemented")
 }
+func (UnimplementedETCScraperServer) GetJob(context.Context, *GetJobRequest) (*Job, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetJob not implemented")
+}
+func (UnimplementedETCScraperServer) ListJobs(context.Context, *ListJobsRequest) (*ListJobsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListJobs not implemented")
+}
+func (UnimplementedETCScraperServer) CancelJob(context.Context, *CancelJobRequest) (*CancelJobResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method CancelJob not implemented")
+}
+func (UnimplementedETCScraperServer) GetArtifacts(context.Context, *GetArtifactsRequest) (*GetArtifactsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetArtifacts not implemented")
+}
+func (UnimplementedETCScraperServer) ListSchedules(context.Context, *ListSchedulesRequest) (*ListSchedulesResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListSchedules not implemented")
+}
+func (UnimplementedETCScraperServer) PutSchedule(context.Context, *Schedule) (*Schedule, error) {
+	return nil, status.Error(codes.Unimplemented, "method PutSchedule not implemented")
+}
+func (UnimplementedETCScraperServer) DeleteSchedule(context.Context, *DeleteScheduleRequest) (*DeleteScheduleResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method DeleteSchedule not implemented")
+}
+func (UnimplementedETCScraperServer) GetNewRecords(context.Context, *GetNewRecordsRequest) (*GetNewRecordsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetNewRecords not implemented")
+}
+func (UnimplementedETCScraperServer) QueryRecords(context.Context, *QueryRecordsRequest) (*QueryRecordsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method QueryRecords not implemented")
+}
+func (UnimplementedETCScraperServer) ListRuns(context.Context, *ListRunsRequest) (*ListRunsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListRuns not implemented")
+}
+func (UnimplementedETCScraperServer) ListSessions(context.Context, *ListSessionsRequest) (*ListSessionsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListSessions not implemented")
+}
+func (UnimplementedETCScraperServer) GetSession(context.Context, *GetSessionRequest) (*Session, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetSession not implemented")
+}
+func (UnimplementedETCScraperServer) GetFile(context.Context, *GetFileRequest) (*DownloadedFile, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetFile not implemented")
+}
 func (UnimplementedETCScraperServer) mustEmbedUnimplementedETCScraperServer() {}
 func (UnimplementedETCScraperServer) testEmbeddedByValue()                    {}
 
@@ -178,6 +439,17 @@ func _ETCScraper_ScrapeMultiple_Handler(srv interface{}, ctx context.Context, de
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ETCScraper_ScrapeStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ScrapeMultipleRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ETCScraperServer).ScrapeStream(m, &grpc.GenericServerStream[ScrapeMultipleRequest, ScrapeEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ETCScraper_ScrapeStreamServer = grpc.ServerStreamingServer[ScrapeEvent]
+
 func _ETCScraper_Health_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(HealthRequest)
 	if err := dec(in); err != nil {
@@ -214,6 +486,240 @@ func _ETCScraper_GetDownloadedFiles_Handler(srv interface{}, ctx context.Context
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ETCScraper_GetJob_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetJobRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ETCScraperServer).GetJob(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ETCScraper_GetJob_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ETCScraperServer).GetJob(ctx, req.(*GetJobRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ETCScraper_ListJobs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListJobsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ETCScraperServer).ListJobs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ETCScraper_ListJobs_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ETCScraperServer).ListJobs(ctx, req.(*ListJobsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ETCScraper_CancelJob_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CancelJobRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ETCScraperServer).CancelJob(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ETCScraper_CancelJob_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ETCScraperServer).CancelJob(ctx, req.(*CancelJobRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ETCScraper_GetArtifacts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetArtifactsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ETCScraperServer).GetArtifacts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ETCScraper_GetArtifacts_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ETCScraperServer).GetArtifacts(ctx, req.(*GetArtifactsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ETCScraper_ListSchedules_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListSchedulesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ETCScraperServer).ListSchedules(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ETCScraper_ListSchedules_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ETCScraperServer).ListSchedules(ctx, req.(*ListSchedulesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ETCScraper_PutSchedule_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Schedule)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ETCScraperServer).PutSchedule(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ETCScraper_PutSchedule_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ETCScraperServer).PutSchedule(ctx, req.(*Schedule))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ETCScraper_DeleteSchedule_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteScheduleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ETCScraperServer).DeleteSchedule(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ETCScraper_DeleteSchedule_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ETCScraperServer).DeleteSchedule(ctx, req.(*DeleteScheduleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ETCScraper_GetNewRecords_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetNewRecordsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ETCScraperServer).GetNewRecords(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ETCScraper_GetNewRecords_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ETCScraperServer).GetNewRecords(ctx, req.(*GetNewRecordsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ETCScraper_QueryRecords_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(QueryRecordsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ETCScraperServer).QueryRecords(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ETCScraper_QueryRecords_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ETCScraperServer).QueryRecords(ctx, req.(*QueryRecordsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ETCScraper_ListRuns_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListRunsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ETCScraperServer).ListRuns(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ETCScraper_ListRuns_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ETCScraperServer).ListRuns(ctx, req.(*ListRunsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ETCScraper_ListSessions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListSessionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ETCScraperServer).ListSessions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ETCScraper_ListSessions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ETCScraperServer).ListSessions(ctx, req.(*ListSessionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ETCScraper_GetSession_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetSessionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ETCScraperServer).GetSession(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ETCScraper_GetSession_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ETCScraperServer).GetSession(ctx, req.(*GetSessionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ETCScraper_GetFile_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetFileRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ETCScraperServer).GetFile(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ETCScraper_GetFile_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ETCScraperServer).GetFile(ctx, req.(*GetFileRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // ETCScraper_ServiceDesc is the grpc.ServiceDesc for ETCScraper service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -237,7 +743,65 @@ var ETCScraper_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetDownloadedFiles",
 			Handler:    _ETCScraper_GetDownloadedFiles_Handler,
 		},
+		{
+			MethodName: "GetJob",
+			Handler:    _ETCScraper_GetJob_Handler,
+		},
+		{
+			MethodName: "ListJobs",
+			Handler:    _ETCScraper_ListJobs_Handler,
+		},
+		{
+			MethodName: "CancelJob",
+			Handler:    _ETCScraper_CancelJob_Handler,
+		},
+		{
+			MethodName: "GetArtifacts",
+			Handler:    _ETCScraper_GetArtifacts_Handler,
+		},
+		{
+			MethodName: "ListSchedules",
+			Handler:    _ETCScraper_ListSchedules_Handler,
+		},
+		{
+			MethodName: "PutSchedule",
+			Handler:    _ETCScraper_PutSchedule_Handler,
+		},
+		{
+			MethodName: "DeleteSchedule",
+			Handler:    _ETCScraper_DeleteSchedule_Handler,
+		},
+		{
+			MethodName: "GetNewRecords",
+			Handler:    _ETCScraper_GetNewRecords_Handler,
+		},
+		{
+			MethodName: "QueryRecords",
+			Handler:    _ETCScraper_QueryRecords_Handler,
+		},
+		{
+			MethodName: "ListRuns",
+			Handler:    _ETCScraper_ListRuns_Handler,
+		},
+		{
+			MethodName: "ListSessions",
+			Handler:    _ETCScraper_ListSessions_Handler,
+		},
+		{
+			MethodName: "GetSession",
+			Handler:    _ETCScraper_GetSession_Handler,
+		},
+		{
+			MethodName: "GetFile",
+			Handler:    _ETCScraper_GetFile_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ScrapeStream",
+			Handler:       _ETCScraper_ScrapeStream_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "proto/scraper.proto",
 }
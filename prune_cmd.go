@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+
+	"github.com/scrape-vm/jobs"
+	"github.com/scrape-vm/retention"
+)
+
+// runPrune applies the retention policy to the download directory once,
+// or only lists the actions with dryRun
+func runPrune(logger *log.Logger, downloadPath string, policy retention.Policy, dryRun bool) {
+	if !policy.Enabled() {
+		log.Fatal("Usage: etc-scraper -prune [-dry-run] -keep-sessions=N | -keep-days=N | -max-download-mb=N | -compress-after-days=N")
+	}
+
+	// サービスが実行中のジョブのセッションフォルダは対象外（ジョブの記録は変更しない）
+	active, err := jobs.LoadActiveSessions(filepath.Join(downloadPath, jobs.StoreFile))
+	if err != nil {
+		log.Fatalf("Failed to read jobs: %v", err)
+	}
+
+	logger.Printf("Retention policy for %s: %s", downloadPath, policy)
+	actions, err := retention.Prune(downloadPath, policy, active, dryRun, logger)
+	if err != nil {
+		log.Fatalf("Failed to prune: %v", err)
+	}
+	if len(actions) == 0 {
+		fmt.Println("Nothing to do")
+		return
+	}
+
+	var bytes int64
+	failed := 0
+	for _, a := range actions {
+		if a.Err != nil {
+			failed++
+		} else if a.Op == "remove" {
+			bytes += a.Bytes
+		}
+		prefix := ""
+		if dryRun {
+			prefix = "[dry-run] "
+		}
+		fmt.Println(prefix + a.String())
+	}
+	if dryRun {
+		fmt.Printf("%d action(s) would free %.1f MB (plus compression)\n", len(actions), float64(bytes)/(1<<20))
+		return
+	}
+	fmt.Printf("%d action(s), %d failed, %.1f MB freed (plus compression)\n", len(actions), failed, float64(bytes)/(1<<20))
+}
@@ -0,0 +1,324 @@
+// Package retention keeps the download directory from growing forever. It
+// removes session folders beyond a count or an age, enforces a size cap and
+// can compress old sessions into zip archives next to the folders.
+package retention
+
+import (
+	"archive/zip"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultInterval is how often the service applies the policy
+const DefaultInterval = time.Hour
+
+const sessionLayout = "20060102_150405"
+
+var sessionPattern = regexp.MustCompile(`^(\d{8}_\d{6})(\.zip)?$`)
+
+// Policy decides which sessions are kept. Zero values disable a rule; the
+// newest session is never removed.
+type Policy struct {
+	KeepSessions  int           // keep at most this many sessions (folders and archives)
+	MaxAge        time.Duration // remove sessions older than this
+	MaxBytes      int64         // remove the oldest sessions until the total is below this
+	CompressAfter time.Duration // zip session folders older than this into <session>.zip
+}
+
+// Enabled reports whether any rule is set
+func (p Policy) Enabled() bool {
+	return p.KeepSessions > 0 || p.MaxAge > 0 || p.MaxBytes > 0 || p.CompressAfter > 0
+}
+
+// String describes the policy for logs
+func (p Policy) String() string {
+	var parts []string
+	if p.KeepSessions > 0 {
+		parts = append(parts, fmt.Sprintf("keep %d sessions", p.KeepSessions))
+	}
+	if p.MaxAge > 0 {
+		parts = append(parts, fmt.Sprintf("keep %s", formatDays(p.MaxAge)))
+	}
+	if p.MaxBytes > 0 {
+		parts = append(parts, fmt.Sprintf("max %d MB", p.MaxBytes>>20))
+	}
+	if p.CompressAfter > 0 {
+		parts = append(parts, fmt.Sprintf("compress after %s", formatDays(p.CompressAfter)))
+	}
+	if len(parts) == 0 {
+		return "disabled"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Action is a change made (or, in a dry run, planned) to a session
+type Action struct {
+	Session string // session ID (YYYYMMDD_HHMMSS)
+	Path    string
+	Op      string // "remove" or "compress"
+	Reason  string
+	Bytes   int64 // size before the action
+	Err     error
+}
+
+func (a Action) String() string {
+	s := fmt.Sprintf("%s %s (%s, %.1f MB)", a.Op, filepath.Base(a.Path), a.Reason, float64(a.Bytes)/(1<<20))
+	if a.Err != nil {
+		s += ": " + a.Err.Error()
+	}
+	return s
+}
+
+// session is a session folder or archive in the download directory
+type session struct {
+	id       string
+	path     string
+	created  time.Time
+	bytes    int64
+	archived bool
+	active   bool
+}
+
+// Prune applies the policy to the session folders and archives in root and
+// returns the actions in order. With dryRun nothing is changed. active lists
+// the session folders of unfinished jobs (see jobs.Manager.ActiveSessions),
+// which are left alone.
+func Prune(root string, policy Policy, active []string, dryRun bool, logger *log.Logger) ([]Action, error) {
+	if logger == nil {
+		logger = log.Default()
+	}
+	sessions, err := scan(root)
+	if err != nil {
+		return nil, err
+	}
+	busy := make(map[string]bool)
+	for _, folder := range active {
+		busy[filepath.Base(folder)] = true
+	}
+	for _, s := range sessions {
+		s.active = busy[s.id]
+	}
+	now := time.Now()
+	var actions []Action
+
+	do := func(s *session, op, reason string) {
+		a := Action{Session: s.id, Path: s.path, Op: op, Reason: reason, Bytes: s.bytes}
+		if !dryRun {
+			switch op {
+			case "remove":
+				a.Err = os.RemoveAll(s.path)
+			case "compress":
+				var zipped string
+				if zipped, a.Err = compress(s.path); a.Err == nil {
+					s.path, s.archived = zipped, true
+					if info, err := os.Stat(zipped); err == nil {
+						s.bytes = info.Size()
+					}
+				}
+			}
+		}
+		if a.Err != nil {
+			logger.Printf("Retention: %v", a)
+		}
+		actions = append(actions, a)
+	}
+
+	// sessions は新しい順。先頭（最新）は常に残す
+	var kept []*session
+	for i, s := range sessions {
+		switch {
+		case i == 0 || s.active:
+			kept = append(kept, s)
+		case policy.KeepSessions > 0 && i >= policy.KeepSessions:
+			do(s, "remove", fmt.Sprintf("beyond %d sessions", policy.KeepSessions))
+		case policy.MaxAge > 0 && now.Sub(s.created) > policy.MaxAge:
+			do(s, "remove", "older than "+formatDays(policy.MaxAge))
+		default:
+			kept = append(kept, s)
+		}
+	}
+
+	if policy.CompressAfter > 0 {
+		for i, s := range kept {
+			if i > 0 && !s.archived && !s.active && now.Sub(s.created) > policy.CompressAfter {
+				do(s, "compress", "older than "+formatDays(policy.CompressAfter))
+			}
+		}
+	}
+
+	if policy.MaxBytes > 0 {
+		var total int64
+		for _, s := range kept {
+			total += s.bytes
+		}
+		for i := len(kept) - 1; i > 0 && total > policy.MaxBytes; i-- {
+			s := kept[i]
+			if s.active {
+				continue
+			}
+			total -= s.bytes
+			do(s, "remove", fmt.Sprintf("over %d MB", policy.MaxBytes>>20))
+		}
+	}
+	return actions, nil
+}
+
+// Run applies the policy now and then every interval until ctx is done.
+// active is called before each run for the session folders to skip.
+func Run(ctx context.Context, root string, policy Policy, interval time.Duration, active func() []string, logger *log.Logger) {
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	logger.Printf("Retention: %s (every %s)", policy, interval)
+	for {
+		var folders []string
+		if active != nil {
+			folders = active()
+		}
+		actions, err := Prune(root, policy, folders, false, logger)
+		if err != nil {
+			logger.Printf("Retention: %v", err)
+		}
+		for _, a := range actions {
+			if a.Err == nil {
+				logger.Printf("Retention: %v", a)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(interval):
+		}
+	}
+}
+
+// scan returns the sessions in root, newest first
+func scan(root string) ([]*session, error) {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	byID := make(map[string]*session)
+	for _, e := range entries {
+		m := sessionPattern.FindStringSubmatch(e.Name())
+		if m == nil || (m[2] == "") != e.IsDir() {
+			continue
+		}
+		created, err := time.ParseInLocation(sessionLayout, m[1], time.Local)
+		if err != nil {
+			continue
+		}
+		s := &session{id: m[1], path: filepath.Join(root, e.Name()), created: created, archived: m[2] != ""}
+		if s.archived {
+			if info, err := e.Info(); err == nil {
+				s.bytes = info.Size()
+			}
+		} else {
+			s.bytes = folderSize(s.path)
+		}
+		// 圧縮途中で止まった場合はフォルダの方を使う
+		if prev, ok := byID[s.id]; ok && !prev.archived {
+			continue
+		}
+		byID[s.id] = s
+	}
+
+	sessions := make([]*session, 0, len(byID))
+	for _, s := range byID {
+		sessions = append(sessions, s)
+	}
+	sort.Slice(sessions, func(i, k int) bool { return sessions[i].id > sessions[k].id })
+	return sessions, nil
+}
+
+// folderSize returns the size of the files in a session folder
+func folderSize(dir string) int64 {
+	var size int64
+	filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		if info, err := d.Info(); err == nil {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size
+}
+
+// compress writes the folder to <folder>.zip and removes the folder
+func compress(dir string) (string, error) {
+	target := dir + ".zip"
+	tmp := target + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return "", err
+	}
+
+	zw := zip.NewWriter(f)
+	err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		header, err := zip.FileInfoHeader(info)
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.Base(dir) + "/" + filepath.ToSlash(rel)
+		header.Method = zip.Deflate
+		w, err := zw.CreateHeader(header)
+		if err != nil {
+			return err
+		}
+		src, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer src.Close()
+		_, err = io.Copy(w, src)
+		return err
+	})
+	if cerr := zw.Close(); err == nil {
+		err = cerr
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp, target)
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return "", err
+	}
+	return target, os.RemoveAll(dir)
+}
+
+func formatDays(d time.Duration) string {
+	if d%(24*time.Hour) == 0 {
+		return fmt.Sprintf("%d days", d/(24*time.Hour))
+	}
+	return d.String()
+}
@@ -0,0 +1,117 @@
+package retention
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// makeSession creates a session folder daysAgo days old with two files of size bytes
+func makeSession(t *testing.T, root string, daysAgo, size int) string {
+	t.Helper()
+	created := time.Now().AddDate(0, 0, -daysAgo)
+	id := created.Format(sessionLayout)
+	dir := filepath.Join(root, id)
+	if err := os.MkdirAll(filepath.Join(dir, "original"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"user1_meisai.csv", "original/user1_meisai.csv"} {
+		if err := os.WriteFile(filepath.Join(dir, filepath.FromSlash(name)), []byte(strings.Repeat("x", size)), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return id
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func TestPruneKeepAndAge(t *testing.T) {
+	root := t.TempDir()
+	var ids []string
+	for _, days := range []int{1, 2, 3, 10, 40} {
+		ids = append(ids, makeSession(t, root, days, 10))
+	}
+	os.WriteFile(filepath.Join(root, "jobs.json"), []byte("[]"), 0644)
+
+	// ドライランでは何も変更しない
+	actions, err := Prune(root, Policy{KeepSessions: 4, MaxAge: 30 * 24 * time.Hour}, nil, true, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(actions) != 1 || actions[0].Session != ids[4] || actions[0].Op != "remove" || !exists(filepath.Join(root, ids[4])) {
+		t.Fatalf("dry run actions = %v", actions)
+	}
+
+	actions, _ = Prune(root, Policy{KeepSessions: 3, MaxAge: 5 * 24 * time.Hour}, nil, false, nil)
+	if len(actions) != 2 {
+		t.Fatalf("actions = %v, want 2 removals", actions)
+	}
+	for i, id := range ids {
+		if want := i < 3; exists(filepath.Join(root, id)) != want {
+			t.Errorf("session %s exists = %v, want %v", id, !want, want)
+		}
+	}
+	if !exists(filepath.Join(root, "jobs.json")) {
+		t.Error("jobs.json was removed")
+	}
+}
+
+func TestPruneSizeCapKeepsNewest(t *testing.T) {
+	root := t.TempDir()
+	newest := makeSession(t, root, 1, 600<<10)
+	older := makeSession(t, root, 2, 600<<10)
+
+	// 1MB未満にはできないが、最新のセッションは残す
+	actions, _ := Prune(root, Policy{MaxBytes: 1 << 20}, nil, false, nil)
+	if len(actions) != 1 || actions[0].Session != older {
+		t.Fatalf("actions = %v, want removal of %s", actions, older)
+	}
+	if !exists(filepath.Join(root, newest)) {
+		t.Error("newest session was removed")
+	}
+}
+
+func TestPruneCompress(t *testing.T) {
+	root := t.TempDir()
+	makeSession(t, root, 1, 100)
+	old := makeSession(t, root, 20, 100)
+	active := makeSession(t, root, 30, 100)
+	// 実行中のジョブのセッションは対象外
+	running := []string{filepath.Join(root, active)}
+
+	actions, err := Prune(root, Policy{CompressAfter: 7 * 24 * time.Hour}, running, false, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(actions) != 1 || actions[0].Op != "compress" || actions[0].Err != nil {
+		t.Fatalf("actions = %v, want compression of %s", actions, old)
+	}
+	if exists(filepath.Join(root, old)) || !exists(filepath.Join(root, active)) {
+		t.Error("wrong session folders left")
+	}
+
+	zr, err := zip.OpenReader(filepath.Join(root, old+".zip"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer zr.Close()
+	var names []string
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+	}
+	if len(names) != 2 || names[0] != old+"/original/user1_meisai.csv" {
+		t.Errorf("zip entries = %v", names)
+	}
+
+	// 圧縮済みのセッションも件数に数える（実行中のものは残る）
+	actions, _ = Prune(root, Policy{KeepSessions: 1}, running, false, nil)
+	if len(actions) != 1 || !strings.HasSuffix(actions[0].Path, ".zip") {
+		t.Errorf("actions = %v, want removal of the archive", actions)
+	}
+}
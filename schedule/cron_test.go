@@ -0,0 +1,56 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCronNext(t *testing.T) {
+	jst := time.FixedZone("JST", 9*60*60)
+	from := time.Date(2025, 1, 31, 6, 30, 0, 0, jst) // 金曜日
+
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"30 6 * * mon-fri", time.Date(2025, 2, 3, 6, 30, 0, 0, jst)}, // 同時刻は含まない
+		{"*/15 * * * *", time.Date(2025, 1, 31, 6, 45, 0, 0, jst)},
+		{"0 9-18/3 * * *", time.Date(2025, 1, 31, 9, 0, 0, 0, jst)},
+		{"@monthly", time.Date(2025, 2, 1, 0, 0, 0, 0, jst)},
+		{"0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, jst)},
+		{"0 0 1 * sun", time.Date(2025, 2, 1, 0, 0, 0, 0, jst)}, // 日と曜日はどちらかに一致
+		{"0 12 * * 7", time.Date(2025, 2, 2, 12, 0, 0, 0, jst)},
+	}
+	for _, tt := range tests {
+		c, err := ParseCron(tt.expr)
+		if err != nil {
+			t.Errorf("ParseCron(%q): %v", tt.expr, err)
+			continue
+		}
+		if got := c.Next(from); !got.Equal(tt.want) {
+			t.Errorf("%q.Next = %v, want %v", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestCronPrev(t *testing.T) {
+	c, err := ParseCron("30 6 * * mon-fri")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sunday := time.Date(2025, 2, 2, 10, 0, 0, 0, time.UTC)
+	if got, want := c.Prev(sunday), time.Date(2025, 1, 31, 6, 30, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("Prev = %v, want %v", got, want)
+	}
+	if got := c.Prev(time.Date(2025, 1, 31, 6, 30, 0, 0, time.UTC)); got.Minute() != 30 || got.Day() != 31 {
+		t.Errorf("Prev at a matching minute = %v, want that minute", got)
+	}
+}
+
+func TestParseCronErrors(t *testing.T) {
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "5-1 * * * *", "*/0 * * * *", "* * * foo *"} {
+		if _, err := ParseCron(expr); err == nil {
+			t.Errorf("ParseCron(%q) succeeded", expr)
+		}
+	}
+}
@@ -0,0 +1,383 @@
+// Package schedule runs recurring scrapes of account groups on cron
+// expressions. Schedules are stored in a JSON file, together with the time
+// and job of their last run so missed runs can be caught up after downtime.
+package schedule
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/scrape-vm/engine"
+)
+
+// DefaultFile is the default schedule file name
+const DefaultFile = "schedules.json"
+
+// missedGrace is how late a run without CatchUp may start (e.g. after the
+// machine slept) before it is skipped
+const missedGrace = 5 * time.Minute
+
+// maxWait bounds the sleep between checks, so clock changes are noticed
+const maxWait = time.Hour
+
+var (
+	// ErrNotFound is returned for an unknown schedule ID
+	ErrNotFound = errors.New("schedule not found")
+	// ErrInvalid is wrapped by validation errors of a schedule
+	ErrInvalid = errors.New("invalid schedule")
+)
+
+// Schedule is a recurring scrape of a group of accounts
+type Schedule struct {
+	ID       string           `json:"id"`
+	Cron     string           `json:"cron"`               // see ParseCron
+	Timezone string           `json:"timezone,omitempty"` // IANA name, e.g. "Asia/Tokyo"; local time if empty
+	Jitter   string           `json:"jitter,omitempty"`   // random delay up to this duration, e.g. "10m"
+	CatchUp  bool             `json:"catchUp,omitempty"`  // run once at startup if a run was missed while stopped
+	Disabled bool             `json:"disabled,omitempty"`
+	Accounts []engine.Account `json:"accounts"` // prefer AccountRef to passwords in this file
+
+	// Updated by the scheduler
+	LastRun   time.Time `json:"lastRun,omitempty"`
+	LastJobID string    `json:"lastJobId,omitempty"`
+}
+
+// Status is a schedule with its next run
+type Status struct {
+	Schedule
+	NextRun time.Time // zero when disabled
+	Running bool      // the job of the last run is still queued or running
+}
+
+// fileFormat is the schedule file
+type fileFormat struct {
+	Schedules []Schedule `json:"schedules"`
+}
+
+// entry is a loaded schedule
+type entry struct {
+	Schedule
+	cron   *Cron
+	loc    *time.Location
+	jitter time.Duration
+	next   time.Time // next cron time; zero when disabled
+	due    time.Time // next plus jitter
+}
+
+// Scheduler starts the jobs of the schedules in a file on the engine
+type Scheduler struct {
+	path   string
+	engine *engine.Engine
+	logger *log.Logger
+
+	mu      sync.Mutex
+	entries map[string]*entry
+	wake    chan struct{}
+}
+
+// New loads the schedules of path (none if the file does not exist). Runs
+// missed since the last run are due immediately for schedules with CatchUp.
+func New(path string, eng *engine.Engine, logger *log.Logger) (*Scheduler, error) {
+	s := &Scheduler{
+		path:    path,
+		engine:  eng,
+		logger:  logger,
+		entries: make(map[string]*entry),
+		wake:    make(chan struct{}, 1),
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schedules: %w", err)
+	}
+	var f fileFormat
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("invalid schedule file %s: %w", path, err)
+	}
+
+	now := time.Now()
+	for _, sc := range f.Schedules {
+		e, err := compile(sc)
+		if err != nil {
+			return nil, err
+		}
+		if _, dup := s.entries[e.ID]; dup {
+			return nil, fmt.Errorf("%w: duplicate id %q", ErrInvalid, e.ID)
+		}
+		s.plan(e, now, true)
+		s.entries[e.ID] = e
+	}
+	return s, nil
+}
+
+// Run starts the jobs of the schedules as they become due, until ctx is cancelled
+func (s *Scheduler) Run(ctx context.Context) {
+	s.logger.Printf("Scheduler started (%d schedules, %s)", len(s.List()), s.path)
+	for {
+		timer := time.NewTimer(s.wait(time.Now()))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			s.logger.Println("Scheduler stopped")
+			return
+		case <-s.wake:
+			timer.Stop()
+		case <-timer.C:
+			s.runDue(time.Now())
+		}
+	}
+}
+
+// List returns the schedules, sorted by ID
+func (s *Scheduler) List() []Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list := make([]Status, 0, len(s.entries))
+	for _, e := range s.entries {
+		list = append(list, s.status(e))
+	}
+	sort.Slice(list, func(i, k int) bool { return list[i].ID < list[k].ID })
+	return list
+}
+
+// Put creates or replaces a schedule. The last run of a replaced schedule is
+// kept, and so are the passwords of its accounts when sc omits them (as
+// returned by List through the API).
+func (s *Scheduler) Put(sc Schedule) (Status, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sc.ID = strings.TrimSpace(sc.ID)
+	sc.LastRun, sc.LastJobID = time.Time{}, ""
+	if old, ok := s.entries[sc.ID]; ok {
+		sc.LastRun, sc.LastJobID = old.LastRun, old.LastJobID
+		keepPasswords(sc.Accounts, old.Accounts)
+	}
+	e, err := compile(sc)
+	if err != nil {
+		return Status{}, err
+	}
+	s.plan(e, time.Now(), false)
+	s.entries[e.ID] = e
+
+	if err := s.saveLocked(); err != nil {
+		return Status{}, err
+	}
+	s.logger.Printf("Schedule %s saved (cron %q, next run %s)", e.ID, e.Cron, formatNext(e.due))
+	s.notify()
+	return s.status(e), nil
+}
+
+// Delete removes a schedule; a running job of it is not cancelled
+func (s *Scheduler) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.entries[id]; !ok {
+		return fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	delete(s.entries, id)
+	if err := s.saveLocked(); err != nil {
+		return err
+	}
+	s.logger.Printf("Schedule %s deleted", id)
+	s.notify()
+	return nil
+}
+
+// compile validates a schedule
+func compile(sc Schedule) (*entry, error) {
+	if sc.ID == "" {
+		return nil, fmt.Errorf("%w: id is required", ErrInvalid)
+	}
+	cron, err := ParseCron(sc.Cron)
+	if err != nil {
+		return nil, fmt.Errorf("%w %s: %v", ErrInvalid, sc.ID, err)
+	}
+	loc := time.Local
+	if sc.Timezone != "" {
+		if loc, err = time.LoadLocation(sc.Timezone); err != nil {
+			return nil, fmt.Errorf("%w %s: timezone: %v", ErrInvalid, sc.ID, err)
+		}
+	}
+	var jitter time.Duration
+	if sc.Jitter != "" {
+		if jitter, err = time.ParseDuration(sc.Jitter); err != nil || jitter < 0 {
+			return nil, fmt.Errorf("%w %s: jitter %q", ErrInvalid, sc.ID, sc.Jitter)
+		}
+	}
+	if len(sc.Accounts) == 0 {
+		return nil, fmt.Errorf("%w %s: no accounts", ErrInvalid, sc.ID)
+	}
+	for _, acc := range sc.Accounts {
+		if acc.AccountRef == "" && (acc.UserID == "" || acc.Password == "") {
+			return nil, fmt.Errorf("%w %s: each account needs account_ref or user_id and password", ErrInvalid, sc.ID)
+		}
+	}
+	return &entry{Schedule: sc, cron: cron, loc: loc, jitter: jitter}, nil
+}
+
+// plan sets the next run of e. At startup a schedule with CatchUp whose last
+// cron time passed since its last run is due immediately.
+func (s *Scheduler) plan(e *entry, now time.Time, startup bool) {
+	e.next, e.due = time.Time{}, time.Time{}
+	if e.Disabled {
+		return
+	}
+	if startup && e.CatchUp && !e.LastRun.IsZero() {
+		if prev := e.cron.Prev(now.In(e.loc)); !prev.IsZero() && prev.After(e.LastRun) {
+			s.logger.Printf("Schedule %s missed the run at %s, catching up", e.ID, prev.Format(time.RFC3339))
+			e.next, e.due = now, now
+			return
+		}
+	}
+	e.next = e.cron.Next(now.In(e.loc))
+	if e.next.IsZero() {
+		s.logger.Printf("Schedule %s: cron %q never matches", e.ID, e.Cron)
+		return
+	}
+	e.due = e.next
+	if e.jitter > 0 {
+		e.due = e.next.Add(time.Duration(rand.Int63n(int64(e.jitter))))
+	}
+}
+
+// wait returns the time until the next due schedule
+func (s *Scheduler) wait(now time.Time) time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	wait := maxWait
+	for _, e := range s.entries {
+		if e.due.IsZero() {
+			continue
+		}
+		if d := e.due.Sub(now); d < wait {
+			wait = d
+		}
+	}
+	if wait < 0 {
+		wait = 0
+	}
+	return wait
+}
+
+// runDue starts the jobs of all due schedules and plans their next runs
+func (s *Scheduler) runDue(now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	changed := false
+	for _, e := range s.entries {
+		if e.due.IsZero() || e.due.After(now) {
+			continue
+		}
+		switch {
+		case !e.CatchUp && now.Sub(e.due) > missedGrace:
+			s.logger.Printf("Schedule %s: run at %s missed (no catch-up), skipped", e.ID, e.due.Format(time.RFC3339))
+		case s.runningLocked(e):
+			s.logger.Printf("Schedule %s: previous job %s is still running, skipped", e.ID, e.LastJobID)
+		default:
+			job, err := s.engine.Start(e.Accounts, engine.Hooks{})
+			if err != nil {
+				s.logger.Printf("Schedule %s: failed to start job: %v", e.ID, err)
+				break
+			}
+			s.logger.Printf("Schedule %s: started job %s (%d accounts)", e.ID, job.ID, len(e.Accounts))
+			e.LastRun, e.LastJobID = now, job.ID
+			changed = true
+		}
+		s.plan(e, now, false)
+	}
+
+	if changed {
+		if err := s.saveLocked(); err != nil {
+			s.logger.Printf("Warning: could not save schedules: %v", err)
+		}
+	}
+}
+
+// runningLocked reports whether the last job of e is queued or running
+func (s *Scheduler) runningLocked(e *entry) bool {
+	if e.LastJobID == "" {
+		return false
+	}
+	job, err := s.engine.Jobs.Get(e.LastJobID)
+	return err == nil && !job.State.Finished()
+}
+
+func (s *Scheduler) status(e *entry) Status {
+	sc := e.Schedule
+	sc.Accounts = append([]engine.Account(nil), e.Accounts...)
+	return Status{Schedule: sc, NextRun: e.due, Running: s.runningLocked(e)}
+}
+
+// saveLocked writes the schedules; the file may contain passwords, so it is
+// only readable by the owner
+func (s *Scheduler) saveLocked() error {
+	f := fileFormat{Schedules: make([]Schedule, 0, len(s.entries))}
+	for _, e := range s.entries {
+		f.Schedules = append(f.Schedules, e.Schedule)
+	}
+	sort.Slice(f.Schedules, func(i, k int) bool { return f.Schedules[i].ID < f.Schedules[k].ID })
+
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("failed to save schedules: %w", err)
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to save schedules: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("failed to save schedules: %w", err)
+	}
+	return nil
+}
+
+// notify wakes Run to recompute its timer
+func (s *Scheduler) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// keepPasswords fills in missing passwords from the previous accounts with the same user ID
+func keepPasswords(accounts, previous []engine.Account) {
+	for i := range accounts {
+		if accounts[i].Password != "" || accounts[i].UserID == "" {
+			continue
+		}
+		for _, prev := range previous {
+			if prev.UserID == accounts[i].UserID {
+				accounts[i].Password = prev.Password
+				break
+			}
+		}
+	}
+}
+
+func formatNext(t time.Time) string {
+	if t.IsZero() {
+		return "none"
+	}
+	return t.Format(time.RFC3339)
+}
@@ -0,0 +1,201 @@
+package scrapers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	cdplog "github.com/chromedp/cdproto/log"
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/cdproto/runtime"
+	"github.com/chromedp/chromedp"
+)
+
+// ArtifactsFolder is the folder inside the session folder holding failure artifacts
+const ArtifactsFolder = "artifacts"
+
+// Files written for a failed step
+const (
+	ArtifactScreenshot = "screenshot.jpg"
+	ArtifactHTML       = "page.html"
+	ArtifactURL        = "url.txt"
+	ArtifactConsole    = "console.log"
+	ArtifactError      = "error.txt"
+)
+
+const (
+	// maxLogLines is the number of console/network log lines kept per scraper
+	maxLogLines = 1000
+	// captureTimeout bounds the time spent capturing artifacts after a failure
+	captureTimeout = 20 * time.Second
+)
+
+// ArtifactFile is a file captured after a failure
+type ArtifactFile struct {
+	Name string `json:"name"`
+	Size int64  `json:"size"`
+	Data []byte `json:"data,omitempty"`
+}
+
+// artifactsError carries the folder of the artifacts captured for err
+type artifactsError struct {
+	err error
+	dir string
+}
+
+func (e *artifactsError) Error() string {
+	return e.err.Error()
+}
+
+func (e *artifactsError) Unwrap() error {
+	return e.err
+}
+
+// ArtifactsDir returns the folder of the failure artifacts captured for err, or ""
+func ArtifactsDir(err error) string {
+	var ae *artifactsError
+	if errors.As(err, &ae) {
+		return ae.dir
+	}
+	return ""
+}
+
+// ReadArtifacts lists the files of an artifacts folder, with their contents if withData is set
+func ReadArtifacts(dir string, withData bool) ([]ArtifactFile, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []ArtifactFile
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		f := ArtifactFile{Name: e.Name(), Size: info.Size()}
+		if withData {
+			if f.Data, err = os.ReadFile(filepath.Join(dir, e.Name())); err != nil {
+				return nil, err
+			}
+		}
+		files = append(files, f)
+	}
+	sort.Slice(files, func(i, k int) bool { return files[i].Name < files[k].Name })
+	return files, nil
+}
+
+// eventLog keeps the latest browser console and network events for diagnostics
+type eventLog struct {
+	mu    sync.Mutex
+	lines []string
+}
+
+// handle records console messages, exceptions, browser log entries and
+// failed or erroneous requests from a chromedp target event
+func (l *eventLog) handle(ev interface{}) {
+	switch e := ev.(type) {
+	case *runtime.EventConsoleAPICalled:
+		args := make([]string, 0, len(e.Args))
+		for _, a := range e.Args {
+			if a.Value != nil {
+				args = append(args, string(a.Value))
+			} else {
+				args = append(args, a.Description)
+			}
+		}
+		l.add("console.%s: %s", e.Type, strings.Join(args, " "))
+	case *runtime.EventExceptionThrown:
+		msg := e.ExceptionDetails.Text
+		if e.ExceptionDetails.Exception != nil {
+			msg += " " + e.ExceptionDetails.Exception.Description
+		}
+		l.add("exception: %s (%s:%d)", msg, e.ExceptionDetails.URL, e.ExceptionDetails.LineNumber)
+	case *cdplog.EventEntryAdded:
+		l.add("log.%s: %s %s", e.Entry.Level, e.Entry.Text, e.Entry.URL)
+	case *network.EventRequestWillBeSent:
+		l.add("request: %s %s", e.Request.Method, e.Request.URL)
+	case *network.EventResponseReceived:
+		l.add("response: %d %s", e.Response.Status, e.Response.URL)
+	case *network.EventLoadingFailed:
+		l.add("failed: %s %s", e.ErrorText, e.RequestID)
+	}
+}
+
+func (l *eventLog) add(format string, args ...interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	line := time.Now().Format("15:04:05.000") + " " + fmt.Sprintf(format, args...)
+	l.lines = append(l.lines, line)
+	if len(l.lines) > maxLogLines {
+		l.lines = l.lines[len(l.lines)-maxLogLines:]
+	}
+}
+
+func (l *eventLog) String() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return strings.Join(l.lines, "\n") + "\n"
+}
+
+// captureFailure saves a screenshot, the HTML, the URL and the browser log of the
+// current page into the artifacts folder of the session, and returns err annotated
+// with that folder. Nothing is captured when ctx was cancelled by the caller.
+func (s *ETCScraper) captureFailure(ctx context.Context, err error) error {
+	if err == nil || s.Ctx == nil || errors.Is(ctx.Err(), context.Canceled) {
+		return err
+	}
+
+	dir := filepath.Join(s.Config.DownloadPath, ArtifactsFolder,
+		fmt.Sprintf("%s_%s", s.Config.UserID, time.Now().Format("150405")))
+	if mkErr := os.MkdirAll(dir, 0755); mkErr != nil {
+		s.Logger.Printf("Warning: could not create artifacts folder: %v", mkErr)
+		return err
+	}
+
+	// フェーズのcontextは期限切れの可能性があるので、ブラウザのcontextから取り直す
+	cctx, cancel := context.WithTimeout(s.Ctx, captureTimeout)
+	defer cancel()
+
+	var url, title, html string
+	var screenshot []byte
+	if cErr := chromedp.Run(cctx, chromedp.Location(&url), chromedp.Title(&title)); cErr != nil {
+		s.Logger.Printf("Warning: could not read page URL: %v", cErr)
+	}
+	if cErr := chromedp.Run(cctx, chromedp.OuterHTML("html", &html, chromedp.ByQuery)); cErr != nil {
+		s.Logger.Printf("Warning: could not capture HTML: %v", cErr)
+	}
+	if cErr := chromedp.Run(cctx, chromedp.FullScreenshot(&screenshot, 80)); cErr != nil {
+		s.Logger.Printf("Warning: could not capture screenshot: %v", cErr)
+	}
+
+	files := map[string][]byte{
+		ArtifactURL:     []byte(url + "\n" + title + "\n"),
+		ArtifactConsole: []byte(s.events.String()),
+		ArtifactError:   []byte(fmt.Sprintf("%s\n%s\n", time.Now().Format(time.RFC3339), err)),
+	}
+	if html != "" {
+		files[ArtifactHTML] = []byte(html)
+	}
+	if len(screenshot) > 0 {
+		files[ArtifactScreenshot] = screenshot
+	}
+	for name, data := range files {
+		if wErr := os.WriteFile(filepath.Join(dir, name), data, 0644); wErr != nil {
+			s.Logger.Printf("Warning: could not save %s: %v", name, wErr)
+		}
+	}
+
+	s.Logger.Printf("Failure artifacts saved to: %s", dir)
+	return &artifactsError{err: err, dir: dir}
+}
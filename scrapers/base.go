@@ -2,17 +2,114 @@ package scrapers
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log"
 	"time"
 )
 
+// DateLayout is the layout of usage dates accepted in requests and flags
+const DateLayout = "2006-01-02"
+
+// Default deadlines used when ScraperConfig leaves them unset
+const (
+	DefaultTimeout      = 60 * time.Second
+	DefaultTotalTimeout = 5 * time.Minute
+)
+
 // ScraperConfig holds common configuration for all scrapers
 type ScraperConfig struct {
+	ScraperType  string // registered scraper name (see Register), DefaultType if empty
 	UserID       string
 	Password     string
 	DownloadPath string
 	Headless     bool
-	Timeout      time.Duration
+	Timeout      time.Duration // deadline for each phase (initialize, login, search, download)
+	TotalTimeout time.Duration // deadline for the whole account, including browser startup
+	KeepOriginal bool          // keep the original Shift_JIS file when converting downloads to UTF-8
+
+	// Usage period to search for. When neither FromDate nor LastMonths is set
+	// the site's default period is used.
+	FromDate   time.Time
+	ToDate     time.Time
+	LastMonths int // previous N calendar months plus the current month to date
+
+	// OnProgress, if set, is called as the scraper moves through its stages
+	OnProgress func(ProgressEvent)
+
+	// Pool, if set, provides the browser instead of starting a new Chrome.
+	// Headless is then taken from the pool.
+	Pool *BrowserPool
+
+	// BaseURL overrides the site root (e.g. a local test site); empty for the real site
+	BaseURL string
+}
+
+// SetPeriod parses the usage period from request values.
+// Empty strings and a zero lastMonths leave the period unset.
+func (c *ScraperConfig) SetPeriod(from, to string, lastMonths int) error {
+	if lastMonths < 0 {
+		return fmt.Errorf("invalid last months: %d", lastMonths)
+	}
+	if lastMonths > 0 && (from != "" || to != "") {
+		return fmt.Errorf("last months cannot be combined with from/to dates")
+	}
+	c.LastMonths = lastMonths
+
+	if from != "" {
+		t, err := time.ParseInLocation(DateLayout, from, time.Local)
+		if err != nil {
+			return fmt.Errorf("invalid from date %q: %w", from, err)
+		}
+		c.FromDate = t
+	}
+	if to != "" {
+		if from == "" {
+			return fmt.Errorf("to date requires a from date")
+		}
+		t, err := time.ParseInLocation(DateLayout, to, time.Local)
+		if err != nil {
+			return fmt.Errorf("invalid to date %q: %w", to, err)
+		}
+		c.ToDate = t
+	}
+	if !c.ToDate.IsZero() && c.ToDate.Before(c.FromDate) {
+		return fmt.Errorf("to date %s is before from date %s", to, from)
+	}
+	return nil
+}
+
+// PhaseTimeout returns the deadline for a single phase of the scrape
+func (c *ScraperConfig) PhaseTimeout() time.Duration {
+	if c.Timeout > 0 {
+		return c.Timeout
+	}
+	return DefaultTimeout
+}
+
+// AccountTimeout returns the deadline for scraping the whole account
+func (c *ScraperConfig) AccountTimeout() time.Duration {
+	if c.TotalTimeout > 0 {
+		return c.TotalTimeout
+	}
+	return DefaultTotalTimeout
+}
+
+// SearchPeriod returns the usage period to search for, relative to now.
+// ok is false when the site's default period should be used.
+func (c *ScraperConfig) SearchPeriod(now time.Time) (from, to time.Time, ok bool) {
+	switch {
+	case c.LastMonths > 0:
+		y, m, _ := now.Date()
+		return time.Date(y, m-time.Month(c.LastMonths), 1, 0, 0, 0, 0, now.Location()), now, true
+	case !c.FromDate.IsZero():
+		to = c.ToDate
+		if to.IsZero() {
+			to = now
+		}
+		return c.FromDate, to, true
+	}
+	return time.Time{}, time.Time{}, false
 }
 
 // ScraperResult represents the result of a scraping operation
@@ -23,41 +120,25 @@ type ScraperResult struct {
 	Content  []byte
 }
 
-// Scraper is the interface that all scrapers must implement
+// Scraper is the interface that all scrapers must implement.
+// Cancelling the context passed to Initialize stops the browser; the contexts
+// passed to the other methods bound that call only.
 type Scraper interface {
 	// Initialize sets up the browser and prepares for scraping
-	Initialize() error
+	Initialize(ctx context.Context) error
 	// Login performs authentication
-	Login() error
+	Login(ctx context.Context) error
 	// Download performs the main scraping/download operation
-	Download() (string, error)
+	Download(ctx context.Context) (string, error)
 	// Close cleans up resources
 	Close() error
 }
 
 // Account represents a user account for scraping
 type Account struct {
-	UserID   string
-	Password string
-}
-
-// ProcessAccount processes a single account using the provided scraper factory
-func ProcessAccount(config *ScraperConfig, logger *log.Logger, factory func(*ScraperConfig, *log.Logger) (Scraper, error)) (string, error) {
-	scraper, err := factory(config, logger)
-	if err != nil {
-		return "", err
-	}
-	defer scraper.Close()
-
-	if err := scraper.Initialize(); err != nil {
-		return "", err
-	}
-
-	if err := scraper.Login(); err != nil {
-		return "", err
-	}
-
-	return scraper.Download()
+	UserID      string
+	Password    string
+	ScraperType string // registered scraper name, DefaultType if empty
 }
 
 // BaseScraper provides common functionality for all scrapers
@@ -70,3 +151,37 @@ type BaseScraper struct {
 	DownloadDone chan string
 	DownloadPath string
 }
+
+// phaseContext returns a context for one phase of the scrape. It is derived from
+// the browser context, bounded by the phase timeout and cancelled together with ctx.
+func (s *BaseScraper) phaseContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	pctx, cancel := context.WithTimeout(s.Ctx, s.Config.PhaseTimeout())
+	stop := context.AfterFunc(ctx, cancel)
+	return pctx, func() {
+		stop()
+		cancel()
+	}
+}
+
+// phaseError names the phase in err when the phase was cut short by a deadline or cancellation
+func phaseError(ctx context.Context, phase string, err error) error {
+	if err == nil || ctx.Err() == nil {
+		return err
+	}
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("%s timed out: %w", phase, err)
+	}
+	return fmt.Errorf("%s cancelled: %w", phase, err)
+}
+
+// sleep waits for d or until ctx is done
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
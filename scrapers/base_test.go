@@ -0,0 +1,89 @@
+package scrapers
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSetPeriod(t *testing.T) {
+	tests := []struct {
+		name       string
+		from, to   string
+		lastMonths int
+		wantFrom   time.Time
+		wantTo     time.Time
+		wantErr    string
+	}{
+		{name: "unset"},
+		{name: "from only", from: "2025-01-10", wantFrom: time.Date(2025, 1, 10, 0, 0, 0, 0, time.Local)},
+		{
+			name: "from and to", from: "2025-01-10", to: "2025-02-28",
+			wantFrom: time.Date(2025, 1, 10, 0, 0, 0, 0, time.Local),
+			wantTo:   time.Date(2025, 2, 28, 0, 0, 0, 0, time.Local),
+		},
+		{
+			name: "single day", from: "2025-01-10", to: "2025-01-10",
+			wantFrom: time.Date(2025, 1, 10, 0, 0, 0, 0, time.Local),
+			wantTo:   time.Date(2025, 1, 10, 0, 0, 0, 0, time.Local),
+		},
+		{name: "last months", lastMonths: 3},
+		{name: "bad from format", from: "2025/01/10", wantErr: "invalid from date"},
+		{name: "bad to format", from: "2025-01-10", to: "20250228", wantErr: "invalid to date"},
+		{name: "invalid day", from: "2025-02-30", wantErr: "invalid from date"},
+		{name: "to without from", to: "2025-02-28", wantErr: "requires a from date"},
+		{name: "to before from", from: "2025-02-01", to: "2025-01-31", wantErr: "is before from date"},
+		{name: "last months with from", from: "2025-01-10", lastMonths: 1, wantErr: "cannot be combined"},
+		{name: "last months with to", to: "2025-01-10", lastMonths: 1, wantErr: "cannot be combined"},
+		{name: "negative last months", lastMonths: -1, wantErr: "invalid last months"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var c ScraperConfig
+			err := c.SetPeriod(tt.from, tt.to, tt.lastMonths)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !c.FromDate.Equal(tt.wantFrom) || !c.ToDate.Equal(tt.wantTo) || c.LastMonths != tt.lastMonths {
+				t.Errorf("period = %v - %v (%d months), want %v - %v", c.FromDate, c.ToDate, c.LastMonths, tt.wantFrom, tt.wantTo)
+			}
+		})
+	}
+}
+
+func TestSearchPeriod(t *testing.T) {
+	now := time.Date(2025, 1, 15, 10, 30, 0, 0, time.Local)
+	date := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 0, 0, 0, 0, time.Local) }
+
+	tests := []struct {
+		name     string
+		config   ScraperConfig
+		wantFrom time.Time
+		wantTo   time.Time
+		wantOK   bool
+	}{
+		{name: "site default", config: ScraperConfig{}},
+		{name: "from the first of the month", config: ScraperConfig{FromDate: date(2025, 1, 1)}, wantFrom: date(2025, 1, 1), wantTo: now, wantOK: true},
+		// 1月から前年に繰り下がる
+		{name: "last month over new year", config: ScraperConfig{LastMonths: 1}, wantFrom: date(2024, 12, 1), wantTo: now, wantOK: true},
+		{name: "last 13 months", config: ScraperConfig{LastMonths: 13}, wantFrom: date(2023, 12, 1), wantTo: now, wantOK: true},
+		{name: "from to now", config: ScraperConfig{FromDate: date(2024, 11, 20)}, wantFrom: date(2024, 11, 20), wantTo: now, wantOK: true},
+		{name: "from and to", config: ScraperConfig{FromDate: date(2024, 11, 20), ToDate: date(2024, 12, 10)}, wantFrom: date(2024, 11, 20), wantTo: date(2024, 12, 10), wantOK: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			from, to, ok := tt.config.SearchPeriod(now)
+			if ok != tt.wantOK || !from.Equal(tt.wantFrom) || !to.Equal(tt.wantTo) {
+				t.Errorf("SearchPeriod = %v - %v, %v, want %v - %v, %v", from, to, ok, tt.wantFrom, tt.wantTo, tt.wantOK)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package scrapers
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Login failures reported by the site
+var (
+	ErrInvalidCredentials = errors.New("invalid credentials")
+	ErrAccountLocked      = errors.New("account locked")
+	ErrPasswordExpired    = errors.New("password expired, change required")
+	ErrSiteMaintenance    = errors.New("site under maintenance")
+)
+
+// StepError reports which step of the site flow failed
+type StepError struct {
+	Step string
+	Err  error
+}
+
+func (e *StepError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Step, e.Err)
+}
+
+func (e *StepError) Unwrap() error {
+	return e.Err
+}
+
+// stepError wraps err in a StepError for step, or returns nil
+func stepError(step string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &StepError{Step: step, Err: err}
+}
+
+// loginErrorPatterns maps site messages to login errors, checked in order
+var loginErrorPatterns = []struct {
+	err      error
+	keywords []string
+}{
+	{ErrSiteMaintenance, []string{"メンテナンス中", "メンテナンスのため", "システム停止中", "サービスを停止しております"}},
+	{ErrAccountLocked, []string{"ロックされて", "ロック中", "ロックしました"}},
+	{ErrPasswordExpired, []string{"パスワードの有効期限", "パスワードを変更してください", "パスワードの変更が必要"}},
+	{ErrInvalidCredentials, []string{"ログインIDまたはパスワード", "ユーザーIDまたはパスワード", "パスワードが違", "パスワードに誤り", "正しくありません", "認証に失敗"}},
+}
+
+// loginPage is the state of the page shown after navigating or logging in
+type loginPage struct {
+	Title         string `json:"title"`
+	Text          string `json:"text"`
+	LoginLink     bool   `json:"loginLink"`
+	LoginForm     bool   `json:"loginForm"`
+	PasswordInput bool   `json:"passwordInput"`
+}
+
+// classifyLoginPage returns the reason and login error for the page shown after
+// submitting credentials, or a nil error if the login succeeded
+func classifyLoginPage(p loginPage) (string, error) {
+	switch {
+	case p.LoginForm:
+		// ログインフォームに戻された場合はメッセージで判別、なければ認証エラー扱い
+		if line, err := matchLoginError(p.Text); err != nil {
+			return line, err
+		}
+		return "login form is still displayed", ErrInvalidCredentials
+	case p.PasswordInput:
+		// ログインフォーム以外のパスワード入力欄はパスワード変更画面
+		if line, err := matchLoginError(p.Text); err != nil {
+			return line, err
+		}
+		return "password change form is displayed", ErrPasswordExpired
+	case strings.Contains(p.Title, "エラー") || strings.Contains(p.Title, "メンテナンス"):
+		if line, err := matchLoginError(p.Text); err != nil {
+			return line, err
+		}
+	}
+	return "", nil
+}
+
+// matchLoginError returns the line of the page text matching a login error, and that error
+func matchLoginError(pageText string) (string, error) {
+	lines := strings.Split(pageText, "\n")
+	for _, p := range loginErrorPatterns {
+		for _, line := range lines {
+			for _, kw := range p.keywords {
+				if strings.Contains(line, kw) {
+					return strings.TrimSpace(line), p.err
+				}
+			}
+		}
+	}
+	return "", nil
+}
+
+// ErrorKind returns a stable identifier for typed login errors ("" for other errors),
+// suitable for persisting alongside job state
+func ErrorKind(err error) string {
+	switch {
+	case errors.Is(err, ErrInvalidCredentials):
+		return "invalid_credentials"
+	case errors.Is(err, ErrAccountLocked):
+		return "account_locked"
+	case errors.Is(err, ErrPasswordExpired):
+		return "password_expired"
+	case errors.Is(err, ErrSiteMaintenance):
+		return "site_maintenance"
+	}
+	return ""
+}
@@ -0,0 +1,102 @@
+package scrapers
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestClassifyLoginPage(t *testing.T) {
+	tests := []struct {
+		name       string
+		page       loginPage
+		wantErr    error
+		wantReason string
+	}{
+		{
+			name: "logged in",
+			page: loginPage{Title: "ETC利用照会サービス", Text: "ご利用明細\nログアウト"},
+		},
+		{
+			name:       "invalid credentials message",
+			page:       loginPage{Title: "ログイン", Text: "ログイン\n ログインIDまたはパスワードが正しくありません。 \n", LoginForm: true},
+			wantErr:    ErrInvalidCredentials,
+			wantReason: "ログインIDまたはパスワードが正しくありません。",
+		},
+		{
+			name:       "login form without message",
+			page:       loginPage{Title: "ログイン", Text: "ログイン", LoginForm: true},
+			wantErr:    ErrInvalidCredentials,
+			wantReason: "login form is still displayed",
+		},
+		{
+			name:       "account locked",
+			page:       loginPage{Title: "ログイン", Text: "アカウントがロックされています", LoginForm: true},
+			wantErr:    ErrAccountLocked,
+			wantReason: "アカウントがロックされています",
+		},
+		{
+			// ロックのメッセージは認証エラーの文言より優先する
+			name:       "locked after wrong password",
+			page:       loginPage{Text: "パスワードに誤りがあります\nアカウントをロックしました", LoginForm: true},
+			wantErr:    ErrAccountLocked,
+			wantReason: "アカウントをロックしました",
+		},
+		{
+			name:       "password expired message",
+			page:       loginPage{Title: "お知らせ", Text: "パスワードの有効期限が切れています", PasswordInput: true},
+			wantErr:    ErrPasswordExpired,
+			wantReason: "パスワードの有効期限が切れています",
+		},
+		{
+			name:       "password change form",
+			page:       loginPage{Title: "パスワード変更", Text: "新しいパスワード", PasswordInput: true},
+			wantErr:    ErrPasswordExpired,
+			wantReason: "password change form is displayed",
+		},
+		{
+			name:       "maintenance page",
+			page:       loginPage{Title: "メンテナンスのお知らせ", Text: "ただいまシステムメンテナンス中です"},
+			wantErr:    ErrSiteMaintenance,
+			wantReason: "ただいまシステムメンテナンス中です",
+		},
+		{
+			name:       "maintenance on login form",
+			page:       loginPage{Text: "メンテナンスのため停止しています", LoginForm: true},
+			wantErr:    ErrSiteMaintenance,
+			wantReason: "メンテナンスのため停止しています",
+		},
+		{
+			name: "error page without known message",
+			page: loginPage{Title: "エラー", Text: "しばらくしてから再度お試しください"},
+		},
+		{
+			// エラーページ以外の本文は判定しない
+			name: "message outside an error page",
+			page: loginPage{Title: "よくある質問", Text: "パスワードが違う場合は"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reason, err := classifyLoginPage(tt.page)
+			if err != tt.wantErr || reason != tt.wantReason {
+				t.Errorf("classifyLoginPage = %q, %v, want %q, %v", reason, err, tt.wantReason, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestErrorKind(t *testing.T) {
+	for err, want := range map[error]string{
+		ErrInvalidCredentials:                               "invalid_credentials",
+		fmt.Errorf("failed to login: %w", ErrAccountLocked): "account_locked",
+		ErrPasswordExpired:                                  "password_expired",
+		ErrSiteMaintenance:                                  "site_maintenance",
+		errors.New("timeout"):                               "",
+	} {
+		if got := ErrorKind(err); got != want {
+			t.Errorf("ErrorKind(%v) = %q, want %q", err, got, want)
+		}
+	}
+}
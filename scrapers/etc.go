@@ -2,6 +2,7 @@ package scrapers
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"log"
 	"os"
@@ -9,14 +10,24 @@ import (
 	"time"
 
 	"github.com/chromedp/cdproto/browser"
+	"github.com/chromedp/cdproto/network"
 	"github.com/chromedp/cdproto/page"
 	"github.com/chromedp/cdproto/target"
 	"github.com/chromedp/chromedp"
 )
 
+// ETCBaseURL is the root of the ETC meisai service
+const ETCBaseURL = "https://www.etc-meisai.jp/"
+
+func init() {
+	Register(DefaultType, NewETCScraper)
+}
+
 // ETCScraper handles web scraping for ETC meisai service (etc-meisai.jp)
 type ETCScraper struct {
 	BaseScraper
+	net    *netTracker
+	events *eventLog
 }
 
 // NewETCScraper creates a new ETC scraper instance
@@ -31,51 +42,77 @@ func NewETCScraper(config *ScraperConfig, logger *log.Logger) (Scraper, error) {
 			Logger:       logger,
 			DownloadDone: make(chan string, 1),
 		},
+		net:    newNetTracker(),
+		events: &eventLog{},
 	}, nil
 }
 
-// Initialize sets up chromedp browser
-func (s *ETCScraper) Initialize() error {
+// Initialize sets up chromedp browser, or a browser context on a pooled browser
+// when ScraperConfig.Pool is set. The browser lives until ctx is cancelled, the
+// total timeout expires or Close is called.
+func (s *ETCScraper) Initialize(ctx context.Context) error {
 	s.Logger.Println("Initializing browser...")
-
-	if err := os.MkdirAll(s.Config.DownloadPath, 0755); err != nil {
-		return fmt.Errorf("failed to create download directory: %w", err)
-	}
+	s.Progress(StageInitialize, "Initializing browser")
 
 	absDownloadPath, err := filepath.Abs(s.Config.DownloadPath)
 	if err != nil {
 		return fmt.Errorf("failed to get absolute path: %w", err)
 	}
+	// 同じフォルダに複数アカウントが同時にダウンロードしても取り違えないよう、アカウントごとの作業フォルダに保存する
+	absDownloadPath = filepath.Join(absDownloadPath, ".download-"+s.Config.UserID)
+	if err := os.MkdirAll(absDownloadPath, 0755); err != nil {
+		return fmt.Errorf("failed to create download directory: %w", err)
+	}
 	s.DownloadPath = absDownloadPath
 
-	opts := append(chromedp.DefaultExecAllocatorOptions[:],
-		chromedp.Flag("headless", s.Config.Headless),
-		chromedp.Flag("disable-gpu", true),
-		chromedp.Flag("no-sandbox", true),
-		chromedp.Flag("disable-dev-shm-usage", true),
-		chromedp.WindowSize(1920, 1080),
-	)
-
-	if s.Config.Headless {
-		s.Logger.Println("Running in HEADLESS mode")
+	// アカウント全体の期限を過ぎたらブラウザ（プール使用時はブラウザコンテキスト）ごと終了する
+	rootCtx, rootCancel := context.WithTimeout(ctx, s.Config.AccountTimeout())
+	if s.Config.Pool != nil {
+		tabCtx, release, err := s.Config.Pool.NewContext(rootCtx)
+		if err != nil {
+			rootCancel()
+			return phaseError(rootCtx, "initialize", err)
+		}
+		s.Ctx = tabCtx
+		s.Cancel = release
+		s.AllocCancel = rootCancel
+		s.Logger.Println("Using pooled browser with a new browser context")
 	} else {
-		s.Logger.Println("Running in VISIBLE mode")
+		if s.Config.Headless {
+			s.Logger.Println("Running in HEADLESS mode")
+		} else {
+			s.Logger.Println("Running in VISIBLE mode")
+		}
+
+		allocCtx, allocCancel := chromedp.NewExecAllocator(rootCtx, browserOptions(s.Config.Headless)...)
+		browserCtx, cancel := chromedp.NewContext(allocCtx, chromedp.WithLogf(s.Logger.Printf))
+
+		s.Ctx = browserCtx
+		s.Cancel = cancel
+		s.AllocCancel = func() {
+			allocCancel()
+			rootCancel()
+		}
 	}
 
-	allocCtx, allocCancel := chromedp.NewExecAllocator(context.Background(), opts...)
-	ctx, cancel := chromedp.NewContext(allocCtx, chromedp.WithLogf(s.Logger.Printf))
+	// 最初のRunでブラウザ・タブを作成する（タイムアウト付きのcontextだと終了時にブラウザが閉じるため、s.Ctxで実行）
+	if err := chromedp.Run(s.Ctx); err != nil {
+		return phaseError(s.Ctx, "initialize", fmt.Errorf("failed to start browser: %w", err))
+	}
 
-	s.Ctx = ctx
-	s.Cancel = cancel
-	s.AllocCancel = allocCancel
+	pctx, pcancel := s.phaseContext(ctx)
+	defer pcancel()
 
-	// ブラウザ全体でダウンロードを許可（新しいタブでも有効）
-	if err := chromedp.Run(s.Ctx,
+	// ブラウザコンテキスト全体でダウンロードを許可（新しいタブでも有効）
+	// ネットワークイベントはページ遷移・通信完了の待機に使う
+	if err := chromedp.Run(pctx,
 		browser.SetDownloadBehavior(browser.SetDownloadBehaviorBehaviorAllowAndName).
+			WithBrowserContextID(chromedp.FromContext(s.Ctx).BrowserContextID).
 			WithDownloadPath(absDownloadPath).
 			WithEventsEnabled(true),
+		network.Enable(),
 	); err != nil {
-		return fmt.Errorf("failed to set download behavior: %w", err)
+		return phaseError(pctx, "initialize", fmt.Errorf("failed to set download behavior: %w", err))
 	}
 
 	// ブラウザレベルでダウンロードイベントを監視（新しいタブを含む）
@@ -115,8 +152,10 @@ func (s *ETCScraper) Initialize() error {
 		}
 	})
 
-	// ターゲットレベルのイベント（ダイアログ等）
+	// ターゲットレベルのイベント（ダイアログ・ネットワーク等）
 	chromedp.ListenTarget(s.Ctx, func(ev interface{}) {
+		s.net.handle(ev)
+		s.events.handle(ev)
 		switch e := ev.(type) {
 		case *page.EventJavascriptDialogOpening:
 			s.Logger.Printf("Dialog: %s", e.Message)
@@ -128,53 +167,136 @@ func (s *ETCScraper) Initialize() error {
 	return nil
 }
 
-// Login performs login to ETC meisai service
-func (s *ETCScraper) Login() error {
-	s.Logger.Println("Navigating to https://www.etc-meisai.jp/")
+// Login performs login to ETC meisai service. On failure the page is captured
+// into the artifacts folder of the session.
+func (s *ETCScraper) Login(ctx context.Context) error {
+	pctx, cancel := s.phaseContext(ctx)
+	defer cancel()
+	return s.captureFailure(ctx, phaseError(pctx, "login", s.login(pctx)))
+}
+
+func (s *ETCScraper) login(ctx context.Context) error {
+	baseURL := s.Config.BaseURL
+	if baseURL == "" {
+		baseURL = ETCBaseURL
+	}
+	s.Logger.Printf("Navigating to %s", baseURL)
+	s.Progress(StageLogin, "Logging in")
 
-	if err := chromedp.Run(s.Ctx,
-		chromedp.Navigate("https://www.etc-meisai.jp/"),
+	if err := chromedp.Run(ctx,
+		chromedp.Navigate(baseURL),
 		chromedp.WaitReady("body"),
 	); err != nil {
-		return fmt.Errorf("failed to navigate: %w", err)
+		return stepError("open top page", err)
+	}
+
+	// メンテナンス中はログインリンクが表示されない
+	page, err := s.inspectPage(ctx)
+	if err != nil {
+		return err
+	}
+	if !page.LoginLink {
+		if line, err := matchLoginError(page.Text); err != nil {
+			s.Logger.Printf("Site error detected: %s", line)
+			return fmt.Errorf("%w: %s", err, line)
+		}
 	}
 
 	s.Logger.Println("Clicking login link...")
-	if err := chromedp.Run(s.Ctx,
+	if err := chromedp.Run(ctx,
 		chromedp.WaitVisible(`a[href*='funccode=1013000000']`),
 		chromedp.Click(`a[href*='funccode=1013000000']`),
-		chromedp.Sleep(3*time.Second),
+		chromedp.WaitVisible(`input[name='risLoginId']`),
 	); err != nil {
-		return fmt.Errorf("failed to click login link: %w", err)
+		return stepError("open login form", err)
 	}
 
 	s.Logger.Printf("Filling credentials for user: %s", s.Config.UserID)
-	if err := chromedp.Run(s.Ctx,
-		chromedp.WaitVisible(`input[name='risLoginId']`),
+	if err := chromedp.Run(ctx,
 		chromedp.SendKeys(`input[name='risLoginId']`, s.Config.UserID),
 		chromedp.SendKeys(`input[name='risPassword']`, s.Config.Password),
 	); err != nil {
-		return fmt.Errorf("failed to fill credentials: %w", err)
+		return stepError("fill credentials", err)
 	}
 
 	s.Logger.Println("Clicking login button...")
-	if err := chromedp.Run(s.Ctx,
-		chromedp.Click(`input[type='button'][value='ログイン']`),
-		chromedp.Sleep(3*time.Second),
-	); err != nil {
-		return fmt.Errorf("failed to click login: %w", err)
+	if err := s.clickAndSettle(ctx, `input[type='button'][value='ログイン']`); err != nil {
+		return stepError("submit login", err)
+	}
+
+	// ログイン結果の確認（エラーメッセージ・ロック・パスワード変更要求）
+	page, err = s.inspectPage(ctx)
+	if err != nil {
+		return err
+	}
+	if reason, err := classifyLoginPage(page); err != nil {
+		s.Logger.Printf("Login failed: %s", reason)
+		return fmt.Errorf("%w: %s", err, reason)
 	}
 
 	s.Logger.Println("Login completed!")
 	return nil
 }
 
-// Download downloads ETC meisai CSV
-func (s *ETCScraper) Download() (string, error) {
+// clickAndSettle clicks the element and waits until the resulting page load
+// or requests have finished and the document is ready
+func (s *ETCScraper) clickAndSettle(ctx context.Context, sel string) error {
+	started := time.Now()
+	return chromedp.Run(ctx,
+		chromedp.Click(sel, chromedp.NodeVisible),
+		s.net.waitNetworkIdle(started),
+		waitCondition(`document.readyState === 'complete'`),
+	)
+}
+
+// inspectPage reads the state of the current page used to detect login failures
+func (s *ETCScraper) inspectPage(ctx context.Context) (loginPage, error) {
+	var page loginPage
+	if err := chromedp.Run(ctx,
+		chromedp.Evaluate(`({
+			title: document.title,
+			text: document.body ? document.body.innerText : "",
+			loginLink: document.querySelector("a[href*='funccode=1013000000']") !== null,
+			loginForm: document.querySelector("input[name='risPassword']") !== null,
+			passwordInput: document.querySelector("input[type='password']") !== null
+		})`, &page),
+	); err != nil {
+		return page, fmt.Errorf("failed to inspect page: %w", err)
+	}
+	return page, nil
+}
+
+// Download downloads ETC meisai CSV. Searching and waiting for the download
+// are separate phases, each bounded by the phase timeout. On failure the page
+// is captured into the artifacts folder of the session.
+func (s *ETCScraper) Download(ctx context.Context) (string, error) {
+	searchCtx, cancel := s.phaseContext(ctx)
+	err := phaseError(searchCtx, "search", s.search(searchCtx))
+	cancel()
+	if err != nil {
+		return "", s.captureFailure(ctx, err)
+	}
+	s.Progress(StageCSVClick, "CSV link clicked")
+
+	downloadCtx, cancel := s.phaseContext(ctx)
+	defer cancel()
+	path, err := s.waitForDownload(downloadCtx)
+	if err != nil {
+		return "", s.captureFailure(ctx, phaseError(downloadCtx, "download", err))
+	}
+	s.Progress(StageDownloadComplete, filepath.Base(path))
+	return path, nil
+}
+
+// search opens the search page, runs the search and clicks the CSV link
+func (s *ETCScraper) search(ctx context.Context) error {
 	s.Logger.Println("Starting download process...")
+	s.Progress(StageSearch, "Searching usage records")
 
 	s.Logger.Println("Navigating to search page...")
-	if err := chromedp.Run(s.Ctx,
+	var clicked bool
+	started := time.Now()
+	if err := chromedp.Run(ctx,
 		chromedp.Evaluate(`
 			(function() {
 				var links = document.querySelectorAll('a');
@@ -186,54 +308,57 @@ func (s *ETCScraper) Download() (string, error) {
 				}
 				return false;
 			})()
-		`, nil),
-		chromedp.Sleep(3*time.Second),
+		`, &clicked),
 	); err != nil {
-		s.Logger.Printf("Warning: %v", err)
+		return stepError("open search settings", err)
+	}
+	if !clicked {
+		s.Logger.Println("Search settings link not found, expecting the settings form on the current page")
+	}
+	if err := chromedp.Run(ctx,
+		s.net.waitNetworkIdle(started),
+		chromedp.WaitVisible(`input[name='sokoKbn'][value='0']`),
+	); err != nil {
+		return stepError("open search settings", err)
 	}
 
 	s.Logger.Println("Selecting '全て' option...")
-	chromedp.Run(s.Ctx,
+	if err := chromedp.Run(ctx,
 		chromedp.Click(`input[name='sokoKbn'][value='0']`, chromedp.NodeVisible),
-		chromedp.Sleep(1*time.Second),
-	)
+		waitCondition(`document.querySelector("input[name='sokoKbn'][value='0']").checked`),
+	); err != nil {
+		return stepError("select all usage types", err)
+	}
 
 	s.Logger.Println("Saving settings...")
-	chromedp.Run(s.Ctx,
-		chromedp.Click(`input[name='focusTarget_Save']`, chromedp.NodeVisible),
-		chromedp.Sleep(2*time.Second),
-	)
+	if err := s.clickAndSettle(ctx, `input[name='focusTarget_Save']`); err != nil {
+		return stepError("save search settings", err)
+	}
+
+	// 利用期間の指定（未指定ならサイトのデフォルト期間）
+	if from, to, ok := s.Config.SearchPeriod(time.Now()); ok {
+		if err := s.setSearchPeriod(ctx, from, to); err != nil {
+			return stepError("set search period", err)
+		}
+	}
 
 	s.Logger.Println("Clicking search button...")
-	if err := chromedp.Run(s.Ctx,
-		chromedp.Click(`input[name='focusTarget']`, chromedp.NodeVisible),
-		chromedp.Sleep(3*time.Second),
-		// ページが完全に読み込まれるまで待つ
-		chromedp.WaitReady("body", chromedp.ByQuery),
-	); err != nil {
-		return "", fmt.Errorf("failed to search: %w", err)
+	if err := s.clickAndSettle(ctx, `input[name='focusTarget']`); err != nil {
+		return stepError("search", err)
 	}
 
-	// JavaScriptが完全に読み込まれるまでポーリングで待つ
+	// 明細ページのCSV出力用スクリプトが使えるまで待つ
 	s.Logger.Println("Waiting for page scripts to load...")
-	for i := 0; i < 30; i++ { // 最大30秒待つ
-		var ready bool
-		chromedp.Run(s.Ctx,
-			chromedp.Evaluate(`
-				(typeof goOutput === 'function' && typeof submitOpenPage === 'function')
-			`, &ready),
-		)
-		if ready {
-			s.Logger.Println("All scripts loaded!")
-			break
-		}
-		s.Logger.Printf("Waiting for scripts... (%d/30)", i+1)
-		time.Sleep(1 * time.Second)
+	if err := chromedp.Run(ctx,
+		waitCondition(`typeof goOutput === 'function' && typeof submitOpenPage === 'function'`),
+	); err != nil {
+		return stepError("wait for result page scripts", err)
 	}
+	s.Logger.Println("All scripts loaded!")
 
 	// ページ上のリンクをデバッグ出力
 	var allLinks string
-	chromedp.Run(s.Ctx,
+	chromedp.Run(ctx,
 		chromedp.Evaluate(`
 			(function() {
 				var links = document.querySelectorAll('a');
@@ -251,7 +376,7 @@ func (s *ETCScraper) Download() (string, error) {
 
 	// CSVリンクをクリック
 	var found bool
-	chromedp.Run(s.Ctx,
+	if err := chromedp.Run(ctx,
 		chromedp.Evaluate(`
 			(function() {
 				var links = document.querySelectorAll('a');
@@ -266,12 +391,21 @@ func (s *ETCScraper) Download() (string, error) {
 				return false;
 			})()
 		`, &found),
-	)
-	s.Logger.Printf("CSV link clicked: %v", found)
+	); err != nil {
+		return stepError("click CSV link", err)
+	}
+	if !found {
+		return stepError("click CSV link", fmt.Errorf("CSV link not found (links on page: %s)", allLinks))
+	}
+	s.Logger.Println("CSV link clicked")
+	return nil
+}
 
-	// ダウンロード完了をポーリングで待つ（最大30秒）
+// waitForDownload waits for the CSV download to finish and returns its path
+func (s *ETCScraper) waitForDownload(ctx context.Context) (string, error) {
+	// ダウンロード完了イベントまたはファイルの出現を待つ
 	s.Logger.Println("Waiting for download...")
-	for i := 0; i < 30; i++ {
+	for {
 		select {
 		case path := <-s.DownloadDone:
 			s.Logger.Printf("Downloaded (event): %s", path)
@@ -301,10 +435,64 @@ func (s *ETCScraper) Download() (string, error) {
 			}
 		}
 
-		time.Sleep(1 * time.Second)
+		if err := sleep(ctx, pollInterval); err != nil {
+			return "", stepError("wait for download", fmt.Errorf("download timeout: %w", err))
+		}
+	}
+}
+
+// setSearchPeriod fills the usage date selects (fromYYYY/fromMM/fromDD, toYYYY/toMM/toDD) of the search form
+func (s *ETCScraper) setSearchPeriod(ctx context.Context, from, to time.Time) error {
+	s.Logger.Printf("Setting search period: %s - %s", from.Format(DateLayout), to.Format(DateLayout))
+
+	values := map[string]string{
+		"fromYYYY": from.Format("2006"),
+		"fromMM":   from.Format("01"),
+		"fromDD":   from.Format("02"),
+		"toYYYY":   to.Format("2006"),
+		"toMM":     to.Format("01"),
+		"toDD":     to.Format("02"),
+	}
+
+	valuesJSON, err := json.Marshal(values)
+	if err != nil {
+		return fmt.Errorf("failed to encode search period: %w", err)
 	}
 
-	return "", fmt.Errorf("download timeout")
+	var missing []string
+	if err := chromedp.Run(ctx,
+		chromedp.Evaluate(fmt.Sprintf(`
+			(function(values) {
+				var missing = [];
+				for (var name in values) {
+					var el = document.querySelector("[name='" + name + "']");
+					if (!el) {
+						missing.push(name);
+						continue;
+					}
+					// 先頭ゼロ有無の違いを吸収して選択肢を探す
+					var value = values[name];
+					if (el.options) {
+						for (var i = 0; i < el.options.length; i++) {
+							if (parseInt(el.options[i].value, 10) === parseInt(value, 10)) {
+								value = el.options[i].value;
+								break;
+							}
+						}
+					}
+					el.value = value;
+					el.dispatchEvent(new Event('change', { bubbles: true }));
+				}
+				return missing;
+			})(%s)
+		`, valuesJSON), &missing),
+	); err != nil {
+		return fmt.Errorf("failed to set search period: %w", err)
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("search period fields not found: %v", missing)
+	}
+	return nil
 }
 
 // Close cleans up resources
@@ -315,5 +503,9 @@ func (s *ETCScraper) Close() error {
 	if s.AllocCancel != nil {
 		s.AllocCancel()
 	}
+	// 作業フォルダは空なら削除（取り出されなかったファイルは残す）
+	if s.DownloadPath != "" {
+		os.Remove(s.DownloadPath)
+	}
 	return nil
 }
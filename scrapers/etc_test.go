@@ -0,0 +1,130 @@
+package scrapers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/chromedp/chromedp"
+	"github.com/scrape-vm/parser"
+	"github.com/scrape-vm/scrapers/testsite"
+)
+
+// requireChrome skips the test when no Chrome/Chromium can be started
+func requireChrome(t *testing.T) {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("skipping browser test in short mode")
+	}
+
+	allocCtx, allocCancel := chromedp.NewExecAllocator(context.Background(), browserOptions(true)...)
+	defer allocCancel()
+	ctx, cancel := chromedp.NewContext(allocCtx)
+	defer cancel()
+	if err := chromedp.Run(ctx); err != nil {
+		t.Skipf("Chrome is not available: %v", err)
+	}
+}
+
+// scrapeTestSite runs the whole Initialize/Login/Download flow against the site
+func scrapeTestSite(t *testing.T, site *testsite.Site, config *ScraperConfig) (string, error) {
+	t.Helper()
+	config.DownloadPath = t.TempDir()
+	config.Headless = true
+	config.BaseURL = site.URL
+	config.Timeout = 30 * time.Second
+	config.TotalTimeout = 2 * time.Minute
+
+	logger := log.New(io.Discard, "", 0)
+	if testing.Verbose() {
+		logger = log.New(log.Writer(), "[ETC-SCRAPER] ", log.LstdFlags)
+	}
+	scraper, err := NewETCScraper(config, logger)
+	if err != nil {
+		return "", err
+	}
+	defer scraper.Close()
+
+	ctx := context.Background()
+	if err := scraper.Initialize(ctx); err != nil {
+		return "", err
+	}
+	if err := scraper.Login(ctx); err != nil {
+		return "", err
+	}
+	return scraper.Download(ctx)
+}
+
+func TestETCScraperDownload(t *testing.T) {
+	requireChrome(t)
+	site := testsite.New()
+	defer site.Close()
+
+	config := &ScraperConfig{UserID: testsite.UserID, Password: testsite.Password}
+	if err := config.SetPeriod("", "", 1); err != nil {
+		t.Fatal(err)
+	}
+
+	path, err := scrapeTestSite(t, site, config)
+	if err != nil {
+		t.Fatalf("scrape failed: %v", err)
+	}
+	if site.Downloads() != 1 {
+		t.Errorf("downloads = %d, want 1", site.Downloads())
+	}
+
+	records, err := parser.ParseMeisaiFile(path)
+	if err != nil {
+		t.Fatalf("downloaded file %s: %v", path, err)
+	}
+	if len(records) != 2 {
+		t.Errorf("records = %d, want 2", len(records))
+	}
+
+	from, _, _ := config.SearchPeriod(time.Now())
+	search := site.LastSearch()
+	if got, want := search.Get("fromYYYY"), from.Format("2006"); got != want {
+		t.Errorf("fromYYYY = %q, want %q", got, want)
+	}
+	if got, want := search.Get("fromMM"), from.Format("01"); got != want {
+		t.Errorf("fromMM = %q, want %q", got, want)
+	}
+}
+
+func TestETCScraperLoginErrors(t *testing.T) {
+	requireChrome(t)
+
+	tests := []struct {
+		name     string
+		password string
+		setup    func(*testsite.Site)
+		want     error
+	}{
+		{"invalid credentials", "wrong", nil, ErrInvalidCredentials},
+		{"account locked", testsite.Password, func(s *testsite.Site) { s.SetLocked(true) }, ErrAccountLocked},
+		{"maintenance", testsite.Password, func(s *testsite.Site) { s.SetMaintenance(true) }, ErrSiteMaintenance},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			site := testsite.New()
+			defer site.Close()
+			if tt.setup != nil {
+				tt.setup(site)
+			}
+
+			_, err := scrapeTestSite(t, site, &ScraperConfig{UserID: testsite.UserID, Password: tt.password})
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("err = %v, want %v", err, tt.want)
+			}
+			if ArtifactsDir(err) == "" {
+				t.Errorf("no failure artifacts captured for %v", err)
+			}
+			if site.Downloads() != 0 {
+				t.Errorf("downloads = %d, want 0", site.Downloads())
+			}
+		})
+	}
+}
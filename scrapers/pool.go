@@ -0,0 +1,158 @@
+package scrapers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+
+	"github.com/chromedp/chromedp"
+)
+
+// ErrPoolClosed is returned when acquiring a browser from a closed pool
+var ErrPoolClosed = errors.New("browser pool closed")
+
+// BrowserPool shares a bounded number of Chrome processes between scrapers.
+// Each scraper gets its own browser context (separate cookies, storage and
+// download directory) on a browser that is not used by any other account at
+// the same time. A browser whose account was cut short is restarted on next use.
+type BrowserPool struct {
+	logger *log.Logger
+	opts   []chromedp.ExecAllocatorOption
+	slots  chan *pooledBrowser
+	ctx    context.Context
+	cancel context.CancelFunc
+	once   sync.Once
+	newTab func(browser context.Context) (context.Context, context.CancelFunc) // browserTab; replaced in tests
+}
+
+// pooledBrowser is a Chrome process of the pool, started lazily
+type pooledBrowser struct {
+	ctx         context.Context
+	cancel      context.CancelFunc
+	allocCancel context.CancelFunc
+}
+
+// NewBrowserPool creates a pool of up to size browsers
+func NewBrowserPool(size int, headless bool, logger *log.Logger) *BrowserPool {
+	if size < 1 {
+		size = 1
+	}
+	if logger == nil {
+		logger = log.New(os.Stdout, "[BROWSER-POOL] ", log.LstdFlags)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &BrowserPool{
+		logger: logger,
+		opts:   browserOptions(headless),
+		slots:  make(chan *pooledBrowser, size),
+		ctx:    ctx,
+		cancel: cancel,
+		newTab: browserTab,
+	}
+	for i := 0; i < size; i++ {
+		p.slots <- &pooledBrowser{}
+	}
+	return p
+}
+
+// Size returns the number of browsers in the pool
+func (p *BrowserPool) Size() int {
+	return cap(p.slots)
+}
+
+// NewContext waits for a free browser and returns a chromedp context for a new,
+// isolated browser context on it. The context is closed when ctx is done or the
+// returned cancel function is called, which also returns the browser to the pool.
+func (p *BrowserPool) NewContext(ctx context.Context) (context.Context, context.CancelFunc, error) {
+	var b *pooledBrowser
+	select {
+	case b = <-p.slots:
+	case <-ctx.Done():
+		return nil, nil, fmt.Errorf("waiting for a browser: %w", ctx.Err())
+	case <-p.ctx.Done():
+		return nil, nil, ErrPoolClosed
+	}
+
+	if b.ctx == nil || b.ctx.Err() != nil {
+		if err := p.start(b); err != nil {
+			p.slots <- b
+			return nil, nil, err
+		}
+	}
+
+	tabCtx, tabCancel := p.newTab(b.ctx)
+	stop := context.AfterFunc(ctx, tabCancel)
+
+	var once sync.Once
+	release := func() {
+		once.Do(func() {
+			stop()
+			tabCancel()
+			// 期限切れ・キャンセルで中断したブラウザは応答しない可能性があるので作り直す
+			if ctx.Err() != nil {
+				b.stop()
+			}
+			p.slots <- b
+		})
+	}
+	return tabCtx, release, nil
+}
+
+// Close stops all browsers. Scrapers still running are cancelled, and Close
+// waits until they have returned their browsers.
+func (p *BrowserPool) Close() {
+	p.once.Do(func() {
+		p.cancel()
+		for i := 0; i < cap(p.slots); i++ {
+			b := <-p.slots
+			b.stop()
+		}
+	})
+}
+
+// start launches Chrome for the pooled browser
+func (p *BrowserPool) start(b *pooledBrowser) error {
+	allocCtx, allocCancel := chromedp.NewExecAllocator(p.ctx, p.opts...)
+	ctx, cancel := chromedp.NewContext(allocCtx, chromedp.WithLogf(p.logger.Printf))
+
+	// 最初のRunでChromeが起動する（タイムアウト付きのcontextを使うとブラウザごと終了するため使わない）
+	if err := chromedp.Run(ctx); err != nil {
+		cancel()
+		allocCancel()
+		return fmt.Errorf("failed to start browser: %w", err)
+	}
+
+	b.ctx, b.cancel, b.allocCancel = ctx, cancel, allocCancel
+	p.logger.Println("Browser started")
+	return nil
+}
+
+// browserTab opens a new, isolated browser context on a started browser
+func browserTab(browser context.Context) (context.Context, context.CancelFunc) {
+	return chromedp.NewContext(browser, chromedp.WithNewBrowserContext())
+}
+
+// stop shuts down the Chrome process, if running
+func (b *pooledBrowser) stop() {
+	if b.cancel == nil {
+		return
+	}
+	b.cancel()
+	b.allocCancel()
+	b.ctx, b.cancel, b.allocCancel = nil, nil, nil
+}
+
+// browserOptions returns the Chrome flags used by all scrapers
+func browserOptions(headless bool) []chromedp.ExecAllocatorOption {
+	return append(chromedp.DefaultExecAllocatorOptions[:],
+		chromedp.Flag("headless", headless),
+		chromedp.Flag("disable-gpu", true),
+		chromedp.Flag("no-sandbox", true),
+		chromedp.Flag("disable-dev-shm-usage", true),
+		chromedp.WindowSize(1920, 1080),
+	)
+}
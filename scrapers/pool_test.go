@@ -0,0 +1,124 @@
+package scrapers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"testing"
+	"time"
+)
+
+// newTestPool returns a pool whose browsers count as started and whose tabs
+// are plain contexts, so that NewContext works without Chrome
+func newTestPool(t *testing.T, size int) *BrowserPool {
+	t.Helper()
+	p := NewBrowserPool(size, true, log.New(io.Discard, "", 0))
+	p.newTab = context.WithCancel
+	for i := 0; i < size; i++ {
+		b := <-p.slots
+		b.ctx, b.cancel = context.WithCancel(context.Background())
+		b.allocCancel = func() {}
+		p.slots <- b
+	}
+	return p
+}
+
+// acquire calls NewContext in the background and returns its result
+func acquire(p *BrowserPool, ctx context.Context) <-chan error {
+	done := make(chan error, 1)
+	go func() {
+		_, release, err := p.NewContext(ctx)
+		if err == nil {
+			release()
+		}
+		done <- err
+	}()
+	return done
+}
+
+func assertBlocked(t *testing.T, done <-chan error) {
+	t.Helper()
+	select {
+	case err := <-done:
+		t.Fatalf("NewContext returned (%v) while every browser is in use", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func assertDone(t *testing.T, done <-chan error) error {
+	t.Helper()
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(5 * time.Second):
+		t.Fatal("NewContext still waiting")
+		return nil
+	}
+}
+
+func TestBrowserPoolLimit(t *testing.T) {
+	p := newTestPool(t, 2)
+	if p.Size() != 2 {
+		t.Fatalf("Size = %d", p.Size())
+	}
+	ctx := context.Background()
+
+	var releases []context.CancelFunc
+	for i := 0; i < p.Size(); i++ {
+		_, release, err := p.NewContext(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		releases = append(releases, release)
+	}
+
+	// すべて使用中なら空くまで待つ
+	waiter := acquire(p, ctx)
+	assertBlocked(t, waiter)
+	releases[0]()
+	releases[0]() // 2回目の呼び出しは何もしない
+	if err := assertDone(t, waiter); err != nil {
+		t.Fatalf("NewContext after release: %v", err)
+	}
+
+	// 待機中にキャンセルされたら空きを待たずに戻る
+	cctx, cancel := context.WithCancel(ctx)
+	_, held, err := p.NewContext(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cancelled := acquire(p, cctx)
+	assertBlocked(t, cancelled)
+	cancel()
+	if err := assertDone(t, cancelled); !errors.Is(err, context.Canceled) {
+		t.Errorf("cancelled waiter: %v, want context.Canceled", err)
+	}
+
+	// 閉じたプールの待機者はErrPoolClosedで戻り、Closeは使用中のブラウザの返却を待つ
+	closing := acquire(p, ctx)
+	assertBlocked(t, closing)
+	closed := make(chan struct{})
+	go func() {
+		p.Close()
+		close(closed)
+	}()
+	if err := assertDone(t, closing); !errors.Is(err, ErrPoolClosed) {
+		t.Errorf("waiter on closed pool: %v, want ErrPoolClosed", err)
+	}
+	select {
+	case <-closed:
+		t.Fatal("Close returned while browsers are in use")
+	case <-time.After(50 * time.Millisecond):
+	}
+	held()
+	releases[1]()
+	select {
+	case <-closed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Close did not return after the browsers were released")
+	}
+	if _, _, err := p.NewContext(ctx); !errors.Is(err, ErrPoolClosed) {
+		t.Errorf("NewContext on closed pool: %v, want ErrPoolClosed", err)
+	}
+}
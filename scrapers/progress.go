@@ -0,0 +1,35 @@
+package scrapers
+
+import "time"
+
+// Stage is a step of a scrape run reported through ScraperConfig.OnProgress
+type Stage string
+
+const (
+	StageInitialize       Stage = "initialize"
+	StageLogin            Stage = "login"
+	StageSearch           Stage = "search"
+	StageCSVClick         Stage = "csv_click"
+	StageDownloadComplete Stage = "download_complete"
+)
+
+// ProgressEvent describes a stage reached by a scraper
+type ProgressEvent struct {
+	UserID  string
+	Stage   Stage
+	Message string
+	Time    time.Time
+}
+
+// Progress reports a stage to the OnProgress callback of the config, if set
+func (s *BaseScraper) Progress(stage Stage, message string) {
+	if s.Config.OnProgress == nil {
+		return
+	}
+	s.Config.OnProgress(ProgressEvent{
+		UserID:  s.Config.UserID,
+		Stage:   stage,
+		Message: message,
+		Time:    time.Now(),
+	})
+}
@@ -0,0 +1,78 @@
+package scrapers
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// DefaultType is the scraper used when no scraper type is given
+const DefaultType = "etc"
+
+// Factory creates a scraper for an account
+type Factory func(config *ScraperConfig, logger *log.Logger) (Scraper, error)
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]Factory)
+)
+
+// Register makes a scraper available under name. It panics if the name is
+// empty or already registered, so it is meant to be called from init.
+func Register(name string, factory Factory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" || factory == nil {
+		panic("scrapers: Register with empty name or nil factory")
+	}
+	if _, dup := registry[name]; dup {
+		panic("scrapers: Register called twice for " + name)
+	}
+	registry[name] = factory
+}
+
+// Lookup returns the factory registered under name (DefaultType if empty)
+func Lookup(name string) (Factory, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		name = DefaultType
+	}
+
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	factory, ok := registry[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown scraper type %q (available: %s)", name, strings.Join(typesLocked(), ", "))
+	}
+	return factory, nil
+}
+
+// Types returns the registered scraper names in order
+func Types() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	return typesLocked()
+}
+
+func typesLocked() []string {
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewScraper creates the scraper selected by config.ScraperType
+func NewScraper(config *ScraperConfig, logger *log.Logger) (Scraper, error) {
+	factory, err := Lookup(config.ScraperType)
+	if err != nil {
+		return nil, err
+	}
+	return factory(config, logger)
+}
@@ -0,0 +1,31 @@
+package scrapers
+
+import (
+	"log"
+	"testing"
+)
+
+func TestLookup(t *testing.T) {
+	for _, name := range []string{"", "etc", " ETC "} {
+		if _, err := Lookup(name); err != nil {
+			t.Errorf("Lookup(%q): %v", name, err)
+		}
+	}
+	if _, err := Lookup("no-such-portal"); err == nil {
+		t.Error("Lookup of an unknown type succeeded")
+	}
+}
+
+func TestNewScraper(t *testing.T) {
+	s, err := NewScraper(&ScraperConfig{UserID: "u"}, log.Default())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := s.(*ETCScraper); !ok {
+		t.Errorf("default scraper is %T, want *ETCScraper", s)
+	}
+
+	if _, err := NewScraper(&ScraperConfig{ScraperType: "no-such-portal"}, log.Default()); err == nil {
+		t.Error("NewScraper with an unknown type succeeded")
+	}
+}
@@ -0,0 +1,8 @@
+// 利用明細ページのスクリプト（本番サイトと同じく新しいタブでCSVを出力する）
+function goOutput() {
+  window.open('/etc/csv', '_blank');
+}
+
+function submitOpenPage(path) {
+  location.href = path;
+}
@@ -0,0 +1,254 @@
+// Package testsite is a local stand-in for etc-meisai.jp used to test scrapers
+// without network access. It reproduces the pages and selectors the ETC scraper
+// relies on: the login link, the login form, the search settings and period
+// forms, the result page scripts and the CSV download opened in a new tab.
+package testsite
+
+import (
+	"embed"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/scrape-vm/parser"
+)
+
+// Credentials accepted by a new site
+const (
+	UserID   = "testuser"
+	Password = "testpass"
+)
+
+// Messages shown for failed logins, matching the wording of the real site
+const (
+	InvalidCredentialsMessage = "ログインIDまたはパスワードが正しくありません。"
+	AccountLockedMessage      = "アカウントがロックされています。"
+)
+
+const sessionCookie = "RISSESSION"
+
+//go:embed pages
+var pages embed.FS
+
+var templates = template.Must(template.ParseFS(pages, "pages/*.html"))
+
+// Site is a running test site. Settings may be changed between scrapes.
+type Site struct {
+	*httptest.Server
+
+	mu          sync.Mutex
+	userID      string
+	password    string
+	locked      bool
+	maintenance bool
+	csv         []byte
+	sessions    map[string]bool
+	search      url.Values
+	downloads   int
+}
+
+// New starts a site accepting UserID and Password. Close it when done.
+func New() *Site {
+	csv, err := pages.ReadFile("pages/meisai.csv")
+	if err != nil {
+		panic(err)
+	}
+	// 本番サイトと同じくShift_JISで返す
+	sjis, err := parser.ToShiftJIS(csv)
+	if err != nil {
+		panic(err)
+	}
+
+	s := &Site{
+		userID:   UserID,
+		password: Password,
+		csv:      sjis,
+		sessions: make(map[string]bool),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", s.handleTop)
+	mux.HandleFunc("/etc/R", s.handleLoginForm)
+	mux.HandleFunc("/etc/login", s.handleLogin)
+	mux.HandleFunc("/etc/menu", s.loggedIn(s.handleMenu))
+	mux.HandleFunc("/etc/search/settings", s.loggedIn(s.handleSettings))
+	mux.HandleFunc("/etc/search", s.loggedIn(s.handleSearch))
+	mux.HandleFunc("/etc/csv", s.loggedIn(s.handleCSV))
+	mux.HandleFunc("/js/meisai.js", s.handleScript)
+	s.Server = httptest.NewServer(mux)
+	return s
+}
+
+// SetLocked makes logins fail with the account locked message
+func (s *Site) SetLocked(locked bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.locked = locked
+}
+
+// SetMaintenance replaces the top page with a maintenance notice
+func (s *Site) SetMaintenance(maintenance bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.maintenance = maintenance
+}
+
+// LastSearch returns the form values of the last search (period fields)
+func (s *Site) LastSearch() url.Values {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.search
+}
+
+// Downloads returns the number of CSV downloads served
+func (s *Site) Downloads() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.downloads
+}
+
+// page holds the data of a rendered template
+type page struct {
+	Title       string
+	Error       string
+	Script      bool
+	Maintenance bool
+	Count       int
+	Years       []string
+	Months      []string
+	Days        []string
+}
+
+func (s *Site) render(w http.ResponseWriter, name string, p page) {
+	w.Header().Set("Content-Type", "text/html; charset=UTF-8")
+	if err := templates.ExecuteTemplate(w, name, p); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (s *Site) handleTop(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	s.mu.Lock()
+	maintenance := s.maintenance
+	s.mu.Unlock()
+
+	s.render(w, "top.html", page{Title: "ETC利用照会サービス", Maintenance: maintenance})
+}
+
+func (s *Site) handleLoginForm(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Query().Get("funccode") != "1013000000" {
+		http.NotFound(w, r)
+		return
+	}
+	s.render(w, "login.html", page{Title: "ログイン"})
+}
+
+func (s *Site) handleLogin(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Redirect(w, r, "/etc/R?funccode=1013000000", http.StatusFound)
+		return
+	}
+
+	s.mu.Lock()
+	ok := r.PostFormValue("risLoginId") == s.userID && r.PostFormValue("risPassword") == s.password
+	locked := s.locked
+	var id string
+	if ok && !locked {
+		id = fmt.Sprintf("%d", time.Now().UnixNano())
+		s.sessions[id] = true
+	}
+	s.mu.Unlock()
+
+	switch {
+	case locked:
+		s.render(w, "login.html", page{Title: "ログイン", Error: AccountLockedMessage})
+	case !ok:
+		s.render(w, "login.html", page{Title: "ログイン", Error: InvalidCredentialsMessage})
+	default:
+		http.SetCookie(w, &http.Cookie{Name: sessionCookie, Value: id, Path: "/"})
+		http.Redirect(w, r, "/etc/menu", http.StatusFound)
+	}
+}
+
+// loggedIn redirects requests without a session to the top page
+func (s *Site) loggedIn(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		c, err := r.Cookie(sessionCookie)
+		s.mu.Lock()
+		ok := err == nil && s.sessions[c.Value]
+		s.mu.Unlock()
+		if !ok {
+			http.Redirect(w, r, "/", http.StatusFound)
+			return
+		}
+		h(w, r)
+	}
+}
+
+func (s *Site) handleMenu(w http.ResponseWriter, r *http.Request) {
+	s.render(w, "menu.html", page{Title: "メニュー"})
+}
+
+func (s *Site) handleSettings(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		s.render(w, "settings.html", page{Title: "検索条件の指定"})
+		return
+	}
+	if r.PostFormValue("sokoKbn") != "0" {
+		s.render(w, "settings.html", page{Title: "検索条件の指定", Error: "利用区分は「全て」を選択してください。"})
+		return
+	}
+
+	p := page{Title: "利用明細検索"}
+	for y := time.Now().Year() - 2; y <= time.Now().Year(); y++ {
+		p.Years = append(p.Years, fmt.Sprintf("%d", y))
+	}
+	for m := 1; m <= 12; m++ {
+		p.Months = append(p.Months, fmt.Sprintf("%02d", m))
+	}
+	for d := 1; d <= 31; d++ {
+		p.Days = append(p.Days, fmt.Sprintf("%02d", d))
+	}
+	s.render(w, "search.html", p)
+}
+
+func (s *Site) handleSearch(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	s.mu.Lock()
+	s.search = r.PostForm
+	s.mu.Unlock()
+
+	count := strings.Count(string(s.csv), "\n") - 1
+	s.render(w, "result.html", page{Title: "利用明細", Script: true, Count: count})
+}
+
+func (s *Site) handleScript(w http.ResponseWriter, r *http.Request) {
+	js, err := pages.ReadFile("pages/meisai.js")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/javascript; charset=UTF-8")
+	w.Write(js)
+}
+
+func (s *Site) handleCSV(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.downloads++
+	s.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Disposition", `attachment; filename="meisai.csv"`)
+	w.Write(s.csv)
+}
@@ -0,0 +1,100 @@
+package scrapers
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/chromedp"
+)
+
+const (
+	pollInterval = 200 * time.Millisecond
+	// networkQuiet is how long the page must have no requests in flight to count as idle
+	networkQuiet = 500 * time.Millisecond
+	// staleRequest is the age after which a pending request (long polling etc.) is ignored
+	staleRequest = 10 * time.Second
+)
+
+// netTracker follows the requests of a page to detect when its network is idle
+type netTracker struct {
+	mu       sync.Mutex
+	inflight map[network.RequestID]time.Time
+	last     time.Time
+}
+
+func newNetTracker() *netTracker {
+	return &netTracker{inflight: make(map[network.RequestID]time.Time)}
+}
+
+// handle updates the tracker from a chromedp target event
+func (t *netTracker) handle(ev interface{}) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	switch e := ev.(type) {
+	case *network.EventRequestWillBeSent:
+		t.inflight[e.RequestID] = time.Now()
+	case *network.EventLoadingFinished:
+		delete(t.inflight, e.RequestID)
+	case *network.EventLoadingFailed:
+		delete(t.inflight, e.RequestID)
+	default:
+		return
+	}
+	t.last = time.Now()
+}
+
+// idleSince reports whether no request has been in flight for networkQuiet,
+// counting quiet time from since at the earliest
+func (t *netTracker) idleSince(since time.Time) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	for id, started := range t.inflight {
+		if now.Sub(started) < staleRequest {
+			return false
+		}
+		delete(t.inflight, id)
+	}
+	quietFrom := t.last
+	if since.After(quietFrom) {
+		quietFrom = since
+	}
+	return now.Sub(quietFrom) >= networkQuiet
+}
+
+// waitNetworkIdle waits until the page has had no requests in flight for networkQuiet
+// after since, so that both page loads and script-driven requests triggered by an
+// action have finished
+func (t *netTracker) waitNetworkIdle(since time.Time) chromedp.Action {
+	return chromedp.ActionFunc(func(ctx context.Context) error {
+		for !t.idleSince(since) {
+			if err := sleep(ctx, pollInterval); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// waitCondition waits until the JavaScript expression evaluates to true
+func waitCondition(expr string) chromedp.Action {
+	return chromedp.ActionFunc(func(ctx context.Context) error {
+		for {
+			// ページ遷移中は評価に失敗するので、失敗は未成立として扱う
+			var ok bool
+			evalCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+			err := chromedp.Evaluate(expr, &ok).Do(evalCtx)
+			cancel()
+			if err == nil && ok {
+				return nil
+			}
+			if err := sleep(ctx, pollInterval); err != nil {
+				return err
+			}
+		}
+	})
+}
@@ -0,0 +1,36 @@
+package server
+
+import (
+	"errors"
+
+	"github.com/scrape-vm/engine"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/scrape-vm/proto"
+)
+
+// EngineAccounts converts the accounts of a request to engine accounts
+func EngineAccounts(accounts []*pb.Account) []engine.Account {
+	list := make([]engine.Account, len(accounts))
+	for i, acc := range accounts {
+		list[i] = engine.Account{
+			ScraperType: acc.ScraperType,
+			UserID:      acc.UserId,
+			Password:    acc.Password,
+			FromDate:    acc.FromDate,
+			ToDate:      acc.ToDate,
+			LastMonths:  int(acc.LastMonths),
+			AccountRef:  acc.AccountRef,
+		}
+	}
+	return list
+}
+
+// EngineError converts an error from starting a run to a gRPC status error
+func EngineError(err error) error {
+	if errors.Is(err, engine.ErrInvalidRequest) {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	return status.Error(codes.Internal, err.Error())
+}
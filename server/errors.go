@@ -0,0 +1,48 @@
+package server
+
+import (
+	"github.com/scrape-vm/scrapers"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/scrape-vm/proto"
+)
+
+// ErrorCode maps a scraper error to the ScrapeResult error code
+func ErrorCode(err error) pb.ErrorCode {
+	return ErrorCodeFromKind(scrapers.ErrorKind(err))
+}
+
+// ErrorCodeFromKind maps a persisted error kind (see scrapers.ErrorKind) to the error code
+func ErrorCodeFromKind(kind string) pb.ErrorCode {
+	switch kind {
+	case "invalid_credentials":
+		return pb.ErrorCode_ERROR_CODE_INVALID_CREDENTIALS
+	case "account_locked":
+		return pb.ErrorCode_ERROR_CODE_ACCOUNT_LOCKED
+	case "password_expired":
+		return pb.ErrorCode_ERROR_CODE_PASSWORD_EXPIRED
+	case "site_maintenance":
+		return pb.ErrorCode_ERROR_CODE_SITE_MAINTENANCE
+	}
+	return pb.ErrorCode_ERROR_CODE_UNSPECIFIED
+}
+
+// StatusError converts a typed login error to a gRPC status error.
+// It returns nil for errors that are reported in the response message instead.
+func StatusError(err error) error {
+	var code codes.Code
+	switch ErrorCode(err) {
+	case pb.ErrorCode_ERROR_CODE_INVALID_CREDENTIALS:
+		code = codes.Unauthenticated
+	case pb.ErrorCode_ERROR_CODE_ACCOUNT_LOCKED:
+		code = codes.PermissionDenied
+	case pb.ErrorCode_ERROR_CODE_PASSWORD_EXPIRED:
+		code = codes.FailedPrecondition
+	case pb.ErrorCode_ERROR_CODE_SITE_MAINTENANCE:
+		code = codes.Unavailable
+	default:
+		return nil
+	}
+	return status.Error(code, err.Error())
+}
@@ -0,0 +1,15 @@
+package server
+
+import (
+	"github.com/scrape-vm/parser"
+
+	pb "github.com/scrape-vm/proto"
+)
+
+// FileEncoding maps the protobuf file encoding to the parser encoding
+func FileEncoding(e pb.FileEncoding) parser.Encoding {
+	if e == pb.FileEncoding_FILE_ENCODING_SHIFT_JIS {
+		return parser.EncodingShiftJIS
+	}
+	return parser.EncodingUTF8
+}
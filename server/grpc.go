@@ -2,14 +2,24 @@ package server
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io/fs"
 	"log"
 	"net"
 	"os"
+	"os/signal"
 	"path/filepath"
-	"time"
-
+	"syscall"
+
+	"github.com/scrape-vm/dedup"
+	"github.com/scrape-vm/engine"
+	"github.com/scrape-vm/history"
+	"github.com/scrape-vm/jobs"
+	"github.com/scrape-vm/parser"
+	"github.com/scrape-vm/schedule"
 	"github.com/scrape-vm/scrapers"
+	"github.com/scrape-vm/vault"
 
 	pb "github.com/scrape-vm/proto"
 	"google.golang.org/grpc"
@@ -19,45 +29,90 @@ import (
 // Version is the current server version (can be overridden at build time)
 var Version = "1.2.0"
 
-// GRPCServer implements the gRPC service
+// GRPCServer implements the gRPC service. The same implementation is served
+// over TCP and P2P, from the CLI and from the Windows service.
 type GRPCServer struct {
 	pb.UnimplementedETCScraperServer
 	Logger       *log.Logger
 	DownloadPath string
-	Headless     bool
+	Version      string // reported by Health; defaults to Version
+	Jobs         *jobs.Manager
+	Engine       *engine.Engine
+	Scheduler    *schedule.Scheduler // nil outside service mode
+	History      *history.DB         // nil if the database could not be opened
 }
 
-// RunGRPCServer starts the gRPC server
-func RunGRPCServer(logger *log.Logger, port, downloadPath string, headless bool) {
-	lis, err := net.Listen("tcp", ":"+port)
+// RunGRPCServer starts the gRPC server on the address, TLS and authentication of
+// listen. account_ref in requests is resolved from accountVault.
+func RunGRPCServer(logger *log.Logger, port string, listen ListenConfig, accountVault *vault.Vault, downloadPath string, headless, keepOriginal bool, parallel int) {
+	opts, err := listen.ServerOptions(port, logger)
+	if err != nil {
+		log.Fatalf("Failed to configure gRPC server: %v", err)
+	}
+	lis, err := net.Listen("tcp", listen.Address(port))
 	if err != nil {
 		log.Fatalf("Failed to listen: %v", err)
 	}
 
-	s := grpc.NewServer()
+	jobManager := jobs.NewManager(filepath.Join(downloadPath, jobs.StoreFile), logger)
+	jobManager.Parallel = parallel
+	hist := OpenHistory(downloadPath, jobManager, logger)
+
+	s := grpc.NewServer(opts...)
 	server := &GRPCServer{
 		Logger:       logger,
 		DownloadPath: downloadPath,
-		Headless:     headless,
+		Jobs:         jobManager,
+		History:      hist,
+		Engine: &engine.Engine{
+			Jobs:         jobManager,
+			Pool:         scrapers.NewBrowserPool(parallel, headless, logger),
+			Vault:        accountVault,
+			Records:      dedup.NewStore(filepath.Join(downloadPath, dedup.StoreFile), logger),
+			DownloadPath: downloadPath,
+			Headless:     headless,
+			KeepOriginal: keepOriginal,
+			Logger:       logger,
+		},
 	}
 	pb.RegisterETCScraperServer(s, server)
 	reflection.Register(s)
 
-	logger.Printf("gRPC server listening on port %s", port)
+	logger.Printf("gRPC server listening on %s", lis.Addr())
 	logger.Printf("Download path: %s", downloadPath)
 	logger.Printf("Headless mode: %v", headless)
+	logger.Printf("Parallel accounts: %d", server.Engine.Pool.Size())
+
+	// 終了時は実行中のジョブをキャンセルしてから履歴データベースを閉じる
+	go func() {
+		sigChan := make(chan os.Signal, 1)
+		signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+		<-sigChan
+		logger.Println("Shutting down...")
+		jobManager.Shutdown()
+		s.GracefulStop()
+	}()
 
 	if err := s.Serve(lis); err != nil {
 		log.Fatalf("Failed to serve: %v", err)
 	}
+	server.Engine.Pool.Close()
+	if hist != nil {
+		hist.Close()
+	}
 }
 
 // Health implements the Health RPC
 func (s *GRPCServer) Health(ctx context.Context, req *pb.HealthRequest) (*pb.HealthResponse, error) {
 	s.Logger.Println("Health check requested")
+	version := s.Version
+	if version == "" {
+		version = Version
+	}
 	return &pb.HealthResponse{
-		Healthy: true,
-		Version: Version,
+		Healthy:      true,
+		Version:      version,
+		ScraperTypes: scrapers.Types(),
 	}, nil
 }
 
@@ -65,50 +120,42 @@ func (s *GRPCServer) Health(ctx context.Context, req *pb.HealthRequest) (*pb.Hea
 func (s *GRPCServer) GetDownloadedFiles(ctx context.Context, req *pb.GetDownloadedFilesRequest) (*pb.GetDownloadedFilesResponse, error) {
 	s.Logger.Println("GetDownloadedFiles requested")
 
-	// ダウンロードディレクトリ内の最新セッションフォルダを探す
-	entries, err := os.ReadDir(s.DownloadPath)
-	if err != nil {
-		return &pb.GetDownloadedFilesResponse{}, nil
-	}
-
-	// 最新のフォルダを探す（YYYYMMDD_HHMMSS形式でソート）
-	var latestFolder string
-	for i := len(entries) - 1; i >= 0; i-- {
-		if entries[i].IsDir() {
-			latestFolder = entries[i].Name()
-			break
-		}
-	}
-
+	// 実行中のジョブのセッションを除いた最新のセッション
+	latestFolder := latestFinishedSession(s.DownloadPath, s.Jobs)
 	if latestFolder == "" {
 		s.Logger.Println("No session folder found")
 		return &pb.GetDownloadedFilesResponse{}, nil
 	}
+	s.Logger.Printf("Reading files from session %s", latestFolder)
 
-	sessionPath := filepath.Join(s.DownloadPath, latestFolder)
-	s.Logger.Printf("Reading files from: %s", sessionPath)
-
-	// セッションフォルダ内のCSVファイルを読み込む
-	files, err := os.ReadDir(sessionPath)
+	sess, err := openSession(s.DownloadPath, latestFolder)
+	if err != nil {
+		return &pb.GetDownloadedFilesResponse{SessionFolder: latestFolder}, nil
+	}
+	defer sess.Close()
+	files, err := fs.ReadDir(sess, ".")
 	if err != nil {
 		return &pb.GetDownloadedFilesResponse{SessionFolder: latestFolder}, nil
 	}
 
+	encoding := FileEncoding(req.Encoding)
 	var downloadedFiles []*pb.DownloadedFile
 	for _, f := range files {
 		if f.IsDir() {
 			continue
 		}
-		filePath := filepath.Join(sessionPath, f.Name())
-		content, err := os.ReadFile(filePath)
+		content, err := parser.ReadFileFS(sess, f.Name(), encoding)
 		if err != nil {
 			s.Logger.Printf("Warning: could not read file %s: %v", f.Name(), err)
 			continue
 		}
-		downloadedFiles = append(downloadedFiles, &pb.DownloadedFile{
-			Filename: f.Name(),
-			Content:  content,
-		})
+		file := &pb.DownloadedFile{Filename: f.Name(), Content: content}
+		if isCSV(f.Name()) {
+			if data, err := fs.ReadFile(sess, f.Name()); err == nil {
+				file.Records = ToProtoRecords(parseRecords(f.Name(), data, s.Logger))
+			}
+		}
+		downloadedFiles = append(downloadedFiles, file)
 		s.Logger.Printf("Added file: %s (%d bytes)", f.Name(), len(content))
 	}
 
@@ -121,31 +168,48 @@ func (s *GRPCServer) GetDownloadedFiles(ctx context.Context, req *pb.GetDownload
 
 // Scrape implements the Scrape RPC
 func (s *GRPCServer) Scrape(ctx context.Context, req *pb.ScrapeRequest) (*pb.ScrapeResponse, error) {
-	s.Logger.Printf("Scrape requested for user: %s", req.UserId)
+	if req.AccountRef != "" {
+		s.Logger.Printf("Scrape requested for vault account: %s", req.AccountRef)
+	} else {
+		s.Logger.Printf("Scrape requested for user: %s", req.UserId)
+	}
 
-	sessionFolder := filepath.Join(s.DownloadPath, time.Now().Format("20060102_150405"))
-	if err := os.MkdirAll(sessionFolder, 0755); err != nil {
+	var scrapeErr error
+	job, err := s.Engine.Run(ctx, []engine.Account{{
+		ScraperType: req.ScraperType,
+		UserID:      req.UserId,
+		Password:    req.Password,
+		FromDate:    req.FromDate,
+		ToDate:      req.ToDate,
+		LastMonths:  int(req.LastMonths),
+		AccountRef:  req.AccountRef,
+	}}, engine.Hooks{
+		OnAccountFinished: func(ctx context.Context, r engine.Result) { scrapeErr = r.Err },
+	})
+	if err != nil {
 		return &pb.ScrapeResponse{
 			Success: false,
-			Message: fmt.Sprintf("Failed to create session folder: %v", err),
+			Message: err.Error(),
 		}, nil
 	}
 
-	config := &scrapers.ScraperConfig{
-		UserID:       req.UserId,
-		Password:     req.Password,
-		DownloadPath: sessionFolder,
-		Headless:     s.Headless,
-		Timeout:      60 * time.Second,
-	}
-
-	csvPath, err := processETCAccountWithResult(config, s.Logger)
-	if err != nil {
+	result := job.Accounts[0]
+	if result.State != jobs.StateSucceeded {
+		// ログイン失敗はgRPCステータスで返す
+		if st := StatusError(scrapeErr); st != nil {
+			return nil, st
+		}
+		message := result.Error
+		if message == "" {
+			message = fmt.Sprintf("Scrape %s", result.State)
+		}
 		return &pb.ScrapeResponse{
 			Success: false,
-			Message: err.Error(),
+			Message: message,
+			JobId:   job.ID,
 		}, nil
 	}
+	csvPath := result.FilePath
 
 	// CSVの内容を読み込む
 	csvContent, _ := os.ReadFile(csvPath)
@@ -155,89 +219,154 @@ func (s *GRPCServer) Scrape(ctx context.Context, req *pb.ScrapeRequest) (*pb.Scr
 		Message:    "Scrape completed successfully",
 		CsvPath:    csvPath,
 		CsvContent: string(csvContent),
+		Records:    ToProtoRecords(ParseRecords(csvPath, s.Logger)),
+		JobId:      job.ID,
 	}, nil
 }
 
-// ScrapeMultiple implements the ScrapeMultiple RPC (非同期版)
+// ScrapeMultiple implements the ScrapeMultiple RPC (非同期版、結果はGetJobで取得)
 func (s *GRPCServer) ScrapeMultiple(ctx context.Context, req *pb.ScrapeMultipleRequest) (*pb.ScrapeMultipleResponse, error) {
 	s.Logger.Printf("ScrapeMultiple requested for %d accounts (async)", len(req.Accounts))
 
-	sessionFolder := filepath.Join(s.DownloadPath, time.Now().Format("20060102_150405"))
-	if err := os.MkdirAll(sessionFolder, 0755); err != nil {
-		return &pb.ScrapeMultipleResponse{
-			Results:      nil,
-			SuccessCount: 0,
-			TotalCount:   int32(len(req.Accounts)),
-		}, nil
-	}
-
 	// バックグラウンドでスクレイピング実行
-	go func() {
-		for i, acc := range req.Accounts {
-			s.Logger.Printf("Processing account %d/%d: %s", i+1, len(req.Accounts), acc.UserId)
-
-			config := &scrapers.ScraperConfig{
-				UserID:       acc.UserId,
-				Password:     acc.Password,
-				DownloadPath: sessionFolder,
-				Headless:     s.Headless,
-				Timeout:      60 * time.Second,
-			}
-
-			csvPath, err := processETCAccountWithResult(config, s.Logger)
-			if err != nil {
-				s.Logger.Printf("ERROR: Account %s failed: %v", acc.UserId, err)
-				continue
-			}
-			s.Logger.Printf("SUCCESS: Account %s -> %s", acc.UserId, csvPath)
-
-			// アカウント間で待機
-			if i < len(req.Accounts)-1 {
-				time.Sleep(2 * time.Second)
-			}
-		}
-		s.Logger.Printf("ScrapeMultiple completed for session: %s", sessionFolder)
-	}()
+	job, err := s.Engine.Start(EngineAccounts(req.Accounts), engine.Hooks{})
+	if err != nil {
+		return nil, EngineError(err)
+	}
 
-	// 即座にレスポンスを返す
+	// 即座にジョブIDを返す
 	return &pb.ScrapeMultipleResponse{
 		Results:      nil,
 		SuccessCount: 0,
 		TotalCount:   int32(len(req.Accounts)),
+		JobId:        job.ID,
 	}, nil
 }
 
-// processETCAccountWithResult processes a single ETC account and returns the CSV path
-func processETCAccountWithResult(config *scrapers.ScraperConfig, logger *log.Logger) (string, error) {
-	scraper, err := scrapers.NewETCScraper(config, logger)
+// ScrapeStream implements the ScrapeStream RPC, sending progress events until all accounts finish
+func (s *GRPCServer) ScrapeStream(req *pb.ScrapeMultipleRequest, stream pb.ETCScraper_ScrapeStreamServer) error {
+	s.Logger.Printf("ScrapeStream requested for %d accounts", len(req.Accounts))
+
+	events := NewEventStream(stream, s.Logger)
+	job, run, err := s.Engine.Prepare(EngineAccounts(req.Accounts), events.Hooks())
 	if err != nil {
-		return "", fmt.Errorf("failed to create scraper: %w", err)
+		return EngineError(err)
 	}
-	defer scraper.Close()
+	events.SetJob(job.ID)
+
+	// クライアントが切断したらジョブもキャンセル
+	job = s.Jobs.Run(stream.Context(), job.ID, run)
+	return events.JobFinished(job)
+}
 
-	if err := scraper.Initialize(); err != nil {
-		return "", fmt.Errorf("failed to initialize: %w", err)
+// GetJob implements the GetJob RPC
+func (s *GRPCServer) GetJob(ctx context.Context, req *pb.GetJobRequest) (*pb.Job, error) {
+	job, err := s.Jobs.Get(req.JobId)
+	if err != nil {
+		return nil, JobStatusError(err)
 	}
+	return ToProtoJob(job, true, s.Logger), nil
+}
 
-	if err := scraper.Login(); err != nil {
-		return "", fmt.Errorf("failed to login: %w", err)
+// ListJobs implements the ListJobs RPC
+func (s *GRPCServer) ListJobs(ctx context.Context, req *pb.ListJobsRequest) (*pb.ListJobsResponse, error) {
+	list := s.Jobs.List(int(req.Limit))
+	resp := &pb.ListJobsResponse{Jobs: make([]*pb.Job, 0, len(list))}
+	for _, job := range list {
+		resp.Jobs = append(resp.Jobs, ToProtoJob(job, false, s.Logger))
 	}
+	return resp, nil
+}
+
+// CancelJob implements the CancelJob RPC
+func (s *GRPCServer) CancelJob(ctx context.Context, req *pb.CancelJobRequest) (*pb.CancelJobResponse, error) {
+	s.Logger.Printf("CancelJob requested for job: %s", req.JobId)
+	if err := s.Jobs.Cancel(req.JobId); err != nil {
+		if errors.Is(err, jobs.ErrNotFound) {
+			return nil, JobStatusError(err)
+		}
+		return &pb.CancelJobResponse{Success: false, Message: err.Error()}, nil
+	}
+	return &pb.CancelJobResponse{Success: true, Message: "Cancellation requested"}, nil
+}
 
-	csvPath, err := scraper.Download()
+// GetArtifacts implements the GetArtifacts RPC
+func (s *GRPCServer) GetArtifacts(ctx context.Context, req *pb.GetArtifactsRequest) (*pb.GetArtifactsResponse, error) {
+	s.Logger.Printf("GetArtifacts requested for job: %s", req.JobId)
+	job, err := s.Jobs.Get(req.JobId)
 	if err != nil {
-		return "", fmt.Errorf("failed to download: %w", err)
+		return nil, JobStatusError(err)
 	}
+	return ToProtoArtifacts(job, req.UserId, req.IncludeData)
+}
 
-	// ファイル名にアカウント名を付与
-	newPath := filepath.Join(config.DownloadPath, config.UserID+"_"+filepath.Base(csvPath))
-	if csvPath != newPath {
-		if err := os.Rename(csvPath, newPath); err != nil {
-			logger.Printf("Warning: could not rename file: %v", err)
-		} else {
-			csvPath = newPath
-		}
+// ListSchedules implements the ListSchedules RPC
+func (s *GRPCServer) ListSchedules(ctx context.Context, req *pb.ListSchedulesRequest) (*pb.ListSchedulesResponse, error) {
+	if s.Scheduler == nil {
+		return nil, ErrNoScheduler
+	}
+	list := s.Scheduler.List()
+	resp := &pb.ListSchedulesResponse{Schedules: make([]*pb.Schedule, 0, len(list))}
+	for _, st := range list {
+		resp.Schedules = append(resp.Schedules, ToProtoSchedule(st))
+	}
+	return resp, nil
+}
+
+// PutSchedule implements the PutSchedule RPC
+func (s *GRPCServer) PutSchedule(ctx context.Context, req *pb.Schedule) (*pb.Schedule, error) {
+	s.Logger.Printf("PutSchedule requested for schedule: %s", req.Id)
+	if s.Scheduler == nil {
+		return nil, ErrNoScheduler
+	}
+	st, err := s.Scheduler.Put(FromProtoSchedule(req))
+	if err != nil {
+		return nil, ScheduleStatusError(err)
+	}
+	return ToProtoSchedule(st), nil
+}
+
+// DeleteSchedule implements the DeleteSchedule RPC
+func (s *GRPCServer) DeleteSchedule(ctx context.Context, req *pb.DeleteScheduleRequest) (*pb.DeleteScheduleResponse, error) {
+	s.Logger.Printf("DeleteSchedule requested for schedule: %s", req.Id)
+	if s.Scheduler == nil {
+		return nil, ErrNoScheduler
+	}
+	if err := s.Scheduler.Delete(req.Id); err != nil {
+		return nil, ScheduleStatusError(err)
 	}
+	return &pb.DeleteScheduleResponse{Success: true}, nil
+}
+
+// GetNewRecords implements the GetNewRecords RPC
+func (s *GRPCServer) GetNewRecords(ctx context.Context, req *pb.GetNewRecordsRequest) (*pb.GetNewRecordsResponse, error) {
+	s.Logger.Printf("GetNewRecords requested for account: %q, job: %q", req.UserId, req.JobId)
+	return GetNewRecords(s.Engine.Records, s.Jobs, req.UserId, req.JobId, s.Logger)
+}
+
+// QueryRecords implements the QueryRecords RPC
+func (s *GRPCServer) QueryRecords(ctx context.Context, req *pb.QueryRecordsRequest) (*pb.QueryRecordsResponse, error) {
+	return QueryRecords(s.History, req)
+}
+
+// ListRuns implements the ListRuns RPC
+func (s *GRPCServer) ListRuns(ctx context.Context, req *pb.ListRunsRequest) (*pb.ListRunsResponse, error) {
+	return ListRuns(s.History, req, s.Logger)
+}
+
+// ListSessions implements the ListSessions RPC
+func (s *GRPCServer) ListSessions(ctx context.Context, req *pb.ListSessionsRequest) (*pb.ListSessionsResponse, error) {
+	return ListSessions(s.DownloadPath, s.Jobs, req)
+}
+
+// GetSession implements the GetSession RPC
+func (s *GRPCServer) GetSession(ctx context.Context, req *pb.GetSessionRequest) (*pb.Session, error) {
+	s.Logger.Printf("GetSession requested for session: %s", req.SessionId)
+	return GetSession(s.DownloadPath, s.Jobs, req)
+}
 
-	logger.Printf("Downloaded: %s", csvPath)
-	return csvPath, nil
+// GetFile implements the GetFile RPC
+func (s *GRPCServer) GetFile(ctx context.Context, req *pb.GetFileRequest) (*pb.DownloadedFile, error) {
+	s.Logger.Printf("GetFile requested: %s/%s", req.SessionId, req.Filename)
+	return GetFile(s.DownloadPath, req, s.Logger)
 }
@@ -0,0 +1,113 @@
+package server
+
+import (
+	"context"
+	"log"
+
+	"github.com/anthropics/cf-wbrtc-auth/go/grpcweb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/scrape-vm/access"
+	"github.com/scrape-vm/p2p"
+	pb "github.com/scrape-vm/proto"
+)
+
+var (
+	jsonUnmarshal = protojson.UnmarshalOptions{DiscardUnknown: true}
+	jsonMarshal   = protojson.MarshalOptions{EmitUnpopulated: true}
+)
+
+// RegisterGRPCWeb serves the unary RPCs of srv on a gRPC-Web transport, under the
+// same method paths as the TCP server ("/scraper.ETCScraper/Scrape", ...).
+// Requests are protobuf binary or protojson (a body starting with '{'); the
+// response uses the encoding of the request. Server streaming RPCs
+// (ScrapeStream) are not available on the transport.
+//
+// Each call is checked against policy for the browser user of the transport's
+// peer and fails with PermissionDenied if the user's role is too low. A nil
+// policy allows all calls.
+func RegisterGRPCWeb(t *grpcweb.Transport, srv pb.ETCScraperServer, policy *access.Policy, browser p2p.BrowserIdentity, logger *log.Logger) {
+	for path, h := range grpcWebHandlers(srv, policy, browser, logger) {
+		t.RegisterHandler(path, h)
+	}
+}
+
+// grpcWebHandlers returns the handlers of RegisterGRPCWeb by method path
+func grpcWebHandlers(srv pb.ETCScraperServer, policy *access.Policy, browser p2p.BrowserIdentity, logger *log.Logger) map[string]grpcweb.Handler {
+	user := BrowserUser(browser)
+	desc := pb.ETCScraper_ServiceDesc
+	handlers := make(map[string]grpcweb.Handler, len(desc.Methods))
+	for _, m := range desc.Methods {
+		path := "/" + desc.ServiceName + "/" + m.MethodName
+		handlers[path] = authorized(policy, user, path, logger, unaryHandler(srv, m))
+	}
+	for _, s := range desc.Streams {
+		logger.Printf("gRPC-Web: /%s/%s is not available (streaming)", desc.ServiceName, s.StreamName)
+	}
+	return handlers
+}
+
+// BrowserUser converts the browser identity from the signaling server for the access policy
+func BrowserUser(id p2p.BrowserIdentity) access.User {
+	return access.User{ID: id.UserID, Email: id.Email}
+}
+
+// authorized checks the policy before calling next
+func authorized(policy *access.Policy, user access.User, method string, logger *log.Logger, next grpcweb.Handler) grpcweb.Handler {
+	return func(ctx context.Context, data []byte) ([]byte, error) {
+		if err := policy.Authorize(user, method); err != nil {
+			logger.Printf("gRPC-Web: %v", err)
+			return nil, status.Error(codes.PermissionDenied, err.Error())
+		}
+		return next(ctx, data)
+	}
+}
+
+// unaryHandler adapts a generated method handler to the gRPC-Web transport
+func unaryHandler(srv pb.ETCScraperServer, m grpc.MethodDesc) grpcweb.Handler {
+	return func(ctx context.Context, data []byte) ([]byte, error) {
+		isJSON := isJSONMessage(data)
+
+		dec := func(v interface{}) error {
+			msg, ok := v.(proto.Message)
+			if !ok {
+				return status.Errorf(codes.Internal, "%T is not a protobuf message", v)
+			}
+			var err error
+			if isJSON {
+				err = jsonUnmarshal.Unmarshal(data, msg)
+			} else {
+				err = proto.Unmarshal(data, msg)
+			}
+			if err != nil {
+				return status.Errorf(codes.InvalidArgument, "invalid %s request: %v", m.MethodName, err)
+			}
+			return nil
+		}
+
+		resp, err := m.Handler(srv, ctx, dec, nil)
+		if err != nil {
+			return nil, err
+		}
+		msg, ok := resp.(proto.Message)
+		if !ok {
+			return nil, status.Errorf(codes.Internal, "%T is not a protobuf message", resp)
+		}
+		if isJSON {
+			return jsonMarshal.Marshal(msg)
+		}
+		return proto.Marshal(msg)
+	}
+}
+
+// isJSONMessage reports whether a request body is protojson. A protobuf message
+// never starts with '{' (field 15, start group), so the first byte decides.
+// Leading whitespace is not skipped: in binary messages '\t', '\n' and ' ' are
+// field tags and lengths (a first field of 10 bytes starts "\x0a\x0a").
+func isJSONMessage(data []byte) bool {
+	return len(data) > 0 && data[0] == '{'
+}
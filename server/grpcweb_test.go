@@ -0,0 +1,121 @@
+package server
+
+import (
+	"context"
+	"io"
+	"log"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/scrape-vm/access"
+	"github.com/scrape-vm/p2p"
+	pb "github.com/scrape-vm/proto"
+)
+
+// webServer answers Health and records the ScrapeMultiple request
+type webServer struct {
+	healthServer
+	scrapeReq *pb.ScrapeMultipleRequest
+}
+
+func (s *webServer) ScrapeMultiple(_ context.Context, req *pb.ScrapeMultipleRequest) (*pb.ScrapeMultipleResponse, error) {
+	s.scrapeReq = req
+	return &pb.ScrapeMultipleResponse{JobId: "job-1"}, nil
+}
+
+func TestGRPCWebEncoding(t *testing.T) {
+	srv := &webServer{}
+	handlers := grpcWebHandlers(srv, nil, p2p.BrowserIdentity{}, log.New(io.Discard, "", 0))
+	scrape := handlers[pb.ETCScraper_ScrapeMultiple_FullMethodName]
+
+	// 最初のアカウントが123バイト: 0x0A 0x7B（'{'）で始まるバイナリ
+	req := &pb.ScrapeMultipleRequest{Accounts: []*pb.Account{{UserId: strings.Repeat("u", 121)}}}
+	data, err := proto.Marshal(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data[0] != 0x0a || data[1] != '{' {
+		t.Fatalf("request starts with % x", data[:2])
+	}
+	out, err := scrape(context.Background(), data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !proto.Equal(srv.scrapeReq, req) {
+		t.Errorf("request = %v", srv.scrapeReq)
+	}
+	var resp pb.ScrapeMultipleResponse
+	if err := proto.Unmarshal(out, &resp); err != nil || resp.JobId != "job-1" {
+		t.Errorf("binary response = %q, %v", out, err)
+	}
+
+	// 長さが改行・タブ・空白と同じ値のアカウント
+	for _, n := range []int{7, 8, 11, 30} {
+		req := &pb.ScrapeMultipleRequest{Accounts: []*pb.Account{{UserId: strings.Repeat("u", n)}}}
+		data, _ := proto.Marshal(req)
+		if _, err := scrape(context.Background(), data); err != nil || !proto.Equal(srv.scrapeReq, req) {
+			t.Errorf("account of %d bytes: %v", n+2, err)
+		}
+	}
+
+	out, err = scrape(context.Background(), []byte(`{"accounts":[{"userId":"user1"}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(srv.scrapeReq.Accounts) != 1 || srv.scrapeReq.Accounts[0].UserId != "user1" {
+		t.Errorf("JSON request = %v", srv.scrapeReq)
+	}
+	resp.Reset()
+	if err := protojson.Unmarshal(out, &resp); err != nil || resp.JobId != "job-1" {
+		t.Errorf("JSON response = %s, %v", out, err)
+	}
+
+	if _, err := scrape(context.Background(), []byte(`{"accounts":`)); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("invalid JSON: %v, want InvalidArgument", err)
+	}
+}
+
+func TestGRPCWebAuthorization(t *testing.T) {
+	policy := &access.Policy{
+		DefaultRole: access.RoleNone,
+		Users:       map[string]access.Role{"viewer@example.com": access.RoleViewer},
+	}
+	logger := log.New(io.Discard, "", 0)
+
+	for _, tt := range []struct {
+		name   string
+		policy *access.Policy
+		user   p2p.BrowserIdentity
+		method string
+		want   codes.Code
+	}{
+		{"no policy", nil, p2p.BrowserIdentity{}, pb.ETCScraper_ScrapeMultiple_FullMethodName, codes.OK},
+		{"viewer reads", policy, p2p.BrowserIdentity{UserID: "u1", Email: "viewer@example.com"}, pb.ETCScraper_Health_FullMethodName, codes.OK},
+		{"viewer scrapes", policy, p2p.BrowserIdentity{UserID: "u1", Email: "viewer@example.com"}, pb.ETCScraper_ScrapeMultiple_FullMethodName, codes.PermissionDenied},
+		{"unknown user", policy, p2p.BrowserIdentity{UserID: "u2"}, pb.ETCScraper_Health_FullMethodName, codes.PermissionDenied},
+		{"anonymous", policy, p2p.BrowserIdentity{}, pb.ETCScraper_Health_FullMethodName, codes.PermissionDenied},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := &webServer{}
+			h := grpcWebHandlers(srv, tt.policy, tt.user, logger)[tt.method]
+			_, err := h(context.Background(), []byte("{}"))
+			if status.Code(err) != tt.want {
+				t.Errorf("%s: %v, want %v", tt.method, err, tt.want)
+			}
+			// 拒否された呼び出しはサーバーに届かない
+			if tt.want != codes.OK && srv.scrapeReq != nil {
+				t.Error("denied call reached the server")
+			}
+		})
+	}
+
+	// ストリーミングRPCは登録しない
+	if _, ok := grpcWebHandlers(&webServer{}, nil, p2p.BrowserIdentity{}, logger)[pb.ETCScraper_ScrapeStream_FullMethodName]; ok {
+		t.Error("ScrapeStream registered on gRPC-Web")
+	}
+}
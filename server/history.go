@@ -0,0 +1,96 @@
+package server
+
+import (
+	"errors"
+	"log"
+	"path/filepath"
+
+	"github.com/scrape-vm/history"
+	"github.com/scrape-vm/jobs"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/scrape-vm/proto"
+)
+
+// ErrNoHistory is returned by the history RPCs when the database could not be opened
+var ErrNoHistory = status.Error(codes.FailedPrecondition, "history database is not available")
+
+// OpenHistory opens the history database in the download directory and records
+// every job finished by jobManager in it. It returns nil if the database
+// cannot be opened; scraping works without it.
+func OpenHistory(downloadPath string, jobManager *jobs.Manager, logger *log.Logger) *history.DB {
+	path := filepath.Join(downloadPath, history.DBFile)
+	db, err := history.Open(path, logger)
+	if err != nil {
+		logger.Printf("History database disabled: %v", err)
+		return nil
+	}
+	jobManager.OnFinished = db.Record
+	logger.Printf("History database: %s", path)
+	return db
+}
+
+// QueryRecords runs the QueryRecords RPC on the history database
+func QueryRecords(db *history.DB, req *pb.QueryRecordsRequest) (*pb.QueryRecordsResponse, error) {
+	if db == nil {
+		return nil, ErrNoHistory
+	}
+	records, total, err := db.QueryRecords(history.RecordQuery{
+		UserID:        req.UserId,
+		FromDate:      req.FromDate,
+		ToDate:        req.ToDate,
+		CardNumber:    req.CardNumber,
+		VehicleNumber: req.VehicleNumber,
+		Interchange:   req.Interchange,
+		AllRuns:       req.AllRuns,
+		Limit:         int(req.Limit),
+		Offset:        int(req.Offset),
+	})
+	if err != nil {
+		return nil, HistoryStatusError(err)
+	}
+
+	resp := &pb.QueryRecordsResponse{
+		Records: make([]*pb.HistoryRecord, 0, len(records)),
+		Total:   int32(total),
+	}
+	for _, r := range records {
+		resp.Records = append(resp.Records, &pb.HistoryRecord{
+			UserId:  r.UserID,
+			JobId:   r.JobID,
+			CsvPath: r.FilePath,
+			Record:  ToProtoRecord(r.UsageRecord),
+		})
+	}
+	return resp, nil
+}
+
+// ListRuns runs the ListRuns RPC on the history database
+func ListRuns(db *history.DB, req *pb.ListRunsRequest, logger *log.Logger) (*pb.ListRunsResponse, error) {
+	if db == nil {
+		return nil, ErrNoHistory
+	}
+	runs, err := db.Runs(history.RunQuery{
+		UserID:   req.UserId,
+		FromDate: req.FromDate,
+		ToDate:   req.ToDate,
+		Limit:    int(req.Limit),
+	})
+	if err != nil {
+		return nil, HistoryStatusError(err)
+	}
+	resp := &pb.ListRunsResponse{Runs: make([]*pb.Job, 0, len(runs))}
+	for _, job := range runs {
+		resp.Runs = append(resp.Runs, ToProtoJob(job, false, logger))
+	}
+	return resp, nil
+}
+
+// HistoryStatusError converts a history database error to a gRPC status error
+func HistoryStatusError(err error) error {
+	if errors.Is(err, history.ErrInvalidQuery) {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	return status.Error(codes.Internal, err.Error())
+}
@@ -0,0 +1,107 @@
+package server
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"github.com/scrape-vm/jobs"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/scrape-vm/proto"
+)
+
+// ToProtoJob converts a job to its protobuf message.
+// Usage records are parsed from the downloaded files only if withRecords is set.
+func ToProtoJob(job *jobs.Job, withRecords bool, logger *log.Logger) *pb.Job {
+	results := make([]*pb.ScrapeResult, 0, len(job.Accounts))
+	for _, acc := range job.Accounts {
+		r := &pb.ScrapeResult{
+			UserId:       acc.UserID,
+			Success:      acc.State == jobs.StateSucceeded,
+			Message:      acc.Error,
+			CsvPath:      acc.FilePath,
+			ErrorCode:    ErrorCodeFromKind(acc.ErrorKind),
+			State:        JobState(acc.State),
+			StartedAt:    formatTime(acc.StartedAt),
+			FinishedAt:   formatTime(acc.FinishedAt),
+			HasArtifacts: acc.Artifacts != "",
+		}
+		if withRecords && acc.FilePath != "" {
+			r.Records = ToProtoRecords(ParseRecords(acc.FilePath, logger))
+		}
+		results = append(results, r)
+	}
+
+	return &pb.Job{
+		JobId:         job.ID,
+		State:         JobState(job.State),
+		SessionFolder: job.SessionFolder,
+		CreatedAt:     formatTime(job.CreatedAt),
+		StartedAt:     formatTime(job.StartedAt),
+		FinishedAt:    formatTime(job.FinishedAt),
+		Results:       results,
+		SuccessCount:  int32(job.SuccessCount()),
+		TotalCount:    int32(len(job.Accounts)),
+	}
+}
+
+// ToProtoArtifacts reads the failure artifacts of the job for the GetArtifacts RPC
+func ToProtoArtifacts(job *jobs.Job, userID string, withData bool) (*pb.GetArtifactsResponse, error) {
+	list, err := job.Artifacts(userID, withData)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to read artifacts: %v", err)
+	}
+
+	resp := &pb.GetArtifactsResponse{JobId: job.ID}
+	for _, a := range list {
+		files := make([]*pb.ArtifactFile, 0, len(a.Files))
+		for _, f := range a.Files {
+			files = append(files, &pb.ArtifactFile{Name: f.Name, Size: f.Size, Data: f.Data})
+		}
+		resp.Accounts = append(resp.Accounts, &pb.AccountArtifacts{
+			UserId:    a.UserID,
+			Error:     a.Error,
+			Directory: a.Directory,
+			Files:     files,
+		})
+	}
+	return resp, nil
+}
+
+// JobState maps a job state to the protobuf enum
+func JobState(s jobs.State) pb.JobState {
+	switch s {
+	case jobs.StateQueued:
+		return pb.JobState_JOB_STATE_QUEUED
+	case jobs.StateRunning:
+		return pb.JobState_JOB_STATE_RUNNING
+	case jobs.StateSucceeded:
+		return pb.JobState_JOB_STATE_SUCCEEDED
+	case jobs.StateFailed:
+		return pb.JobState_JOB_STATE_FAILED
+	case jobs.StateCancelled:
+		return pb.JobState_JOB_STATE_CANCELLED
+	}
+	return pb.JobState_JOB_STATE_UNSPECIFIED
+}
+
+// JobStatusError converts a job manager error to a gRPC status error
+func JobStatusError(err error) error {
+	switch {
+	case errors.Is(err, jobs.ErrNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, jobs.ErrFinished):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	}
+	return status.Error(codes.Internal, err.Error())
+}
+
+// formatTime formats t as RFC3339, or "" for the zero time
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
@@ -0,0 +1,144 @@
+package server
+
+import (
+	"bytes"
+	"errors"
+	"io/fs"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+
+	"github.com/scrape-vm/dedup"
+	"github.com/scrape-vm/jobs"
+	"github.com/scrape-vm/parser"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/scrape-vm/proto"
+)
+
+// ToProtoRecords converts parsed usage records to protobuf messages
+func ToProtoRecords(records []parser.UsageRecord) []*pb.UsageRecord {
+	result := make([]*pb.UsageRecord, 0, len(records))
+	for _, r := range records {
+		result = append(result, ToProtoRecord(r))
+	}
+	return result
+}
+
+// ToProtoRecord converts a parsed usage record to its protobuf message
+func ToProtoRecord(r parser.UsageRecord) *pb.UsageRecord {
+	rec := &pb.UsageRecord{
+		ExitDate:      r.ExitTime.Format("2006-01-02"),
+		ExitTime:      r.ExitTime.Format("15:04"),
+		EntryIc:       r.EntryIC,
+		ExitIc:        r.ExitIC,
+		VehicleClass:  r.VehicleClass,
+		CardNumber:    r.CardNumber,
+		OriginalToll:  int32(r.OriginalToll),
+		Discount:      int32(r.Discount),
+		Toll:          int32(r.Toll),
+		VehicleNumber: r.VehicleNumber,
+		Note:          r.Note,
+	}
+	if !r.EntryTime.IsZero() {
+		rec.EntryDate = r.EntryTime.Format("2006-01-02")
+		rec.EntryTime = r.EntryTime.Format("15:04")
+	}
+	return rec
+}
+
+// ParseRecords parses a downloaded CSV file, logging and returning nil on
+// failure. Files of sessions compressed by the retention policy are read from
+// the archive.
+func ParseRecords(path string, logger *log.Logger) []parser.UsageRecord {
+	if !isCSV(path) {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		// <root>/<session>/<file> → <root>/<session>.zip
+		dir := filepath.Dir(path)
+		if sess, serr := openSession(filepath.Dir(dir), filepath.Base(dir)); serr == nil {
+			data, err = fs.ReadFile(sess, filepath.Base(path))
+			sess.Close()
+		}
+	}
+	if err != nil {
+		logger.Printf("Warning: could not parse %s: %v", filepath.Base(path), err)
+		return nil
+	}
+	return parseRecords(path, data, logger)
+}
+
+// parseRecords parses the content of a downloaded CSV file
+func parseRecords(name string, data []byte, logger *log.Logger) []parser.UsageRecord {
+	records, err := parser.ParseMeisai(bytes.NewReader(data))
+	if err != nil {
+		logger.Printf("Warning: could not parse %s: %v", path.Base(filepath.ToSlash(name)), err)
+		return nil
+	}
+	return records
+}
+
+// GetNewRecords returns the records first downloaded by the job, or by the last
+// run of each account if jobID is empty, for one account or all of them.
+// Accounts whose job has been pruned from the job store are left out.
+func GetNewRecords(store *dedup.Store, jobManager *jobs.Manager, userID, jobID string, logger *log.Logger) (*pb.GetNewRecordsResponse, error) {
+	if store == nil {
+		return nil, status.Error(codes.FailedPrecondition, "record store is not enabled")
+	}
+
+	// アカウントごとの対象ジョブ
+	targets := make(map[string]string)
+	if jobID != "" {
+		job, err := jobManager.Get(jobID)
+		if err != nil {
+			return nil, JobStatusError(err)
+		}
+		for _, acc := range job.Accounts {
+			if userID == "" || acc.UserID == userID {
+				targets[acc.UserID] = job.ID
+			}
+		}
+	} else {
+		for _, id := range store.Accounts() {
+			if userID == "" || id == userID {
+				targets[id] = store.LastJob(id)
+			}
+		}
+	}
+
+	resp := &pb.GetNewRecordsResponse{}
+	for _, id := range sortedKeys(targets) {
+		job, err := jobManager.Get(targets[id])
+		if err != nil {
+			continue
+		}
+		for _, acc := range job.Accounts {
+			if acc.UserID != id || acc.FilePath == "" {
+				continue
+			}
+			records := store.Filter(id, job.ID, ParseRecords(acc.FilePath, logger))
+			resp.Accounts = append(resp.Accounts, &pb.NewRecords{
+				UserId:  id,
+				JobId:   job.ID,
+				CsvPath: acc.FilePath,
+				Records: ToProtoRecords(records),
+			})
+			break
+		}
+	}
+	return resp, nil
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
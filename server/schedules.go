@@ -0,0 +1,67 @@
+package server
+
+import (
+	"errors"
+
+	"github.com/scrape-vm/schedule"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/scrape-vm/proto"
+)
+
+// ErrNoScheduler is returned by the schedule RPCs when the scheduler is not running
+var ErrNoScheduler = status.Error(codes.FailedPrecondition, "scheduler is not enabled (service mode only)")
+
+// ToProtoSchedule converts a schedule to its protobuf message. Passwords are
+// never returned.
+func ToProtoSchedule(st schedule.Status) *pb.Schedule {
+	accounts := make([]*pb.Account, 0, len(st.Accounts))
+	for _, acc := range st.Accounts {
+		accounts = append(accounts, &pb.Account{
+			UserId:      acc.UserID,
+			FromDate:    acc.FromDate,
+			ToDate:      acc.ToDate,
+			LastMonths:  int32(acc.LastMonths),
+			ScraperType: acc.ScraperType,
+			AccountRef:  acc.AccountRef,
+		})
+	}
+	return &pb.Schedule{
+		Id:        st.ID,
+		Cron:      st.Cron,
+		Timezone:  st.Timezone,
+		Jitter:    st.Jitter,
+		CatchUp:   st.CatchUp,
+		Disabled:  st.Disabled,
+		Accounts:  accounts,
+		LastRun:   formatTime(st.LastRun),
+		LastJobId: st.LastJobID,
+		NextRun:   formatTime(st.NextRun),
+		Running:   st.Running,
+	}
+}
+
+// FromProtoSchedule converts a PutSchedule request; the read-only fields are ignored
+func FromProtoSchedule(req *pb.Schedule) schedule.Schedule {
+	return schedule.Schedule{
+		ID:       req.Id,
+		Cron:     req.Cron,
+		Timezone: req.Timezone,
+		Jitter:   req.Jitter,
+		CatchUp:  req.CatchUp,
+		Disabled: req.Disabled,
+		Accounts: EngineAccounts(req.Accounts),
+	}
+}
+
+// ScheduleStatusError converts a scheduler error to a gRPC status error
+func ScheduleStatusError(err error) error {
+	switch {
+	case errors.Is(err, schedule.ErrNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, schedule.ErrInvalid):
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	return status.Error(codes.Internal, err.Error())
+}
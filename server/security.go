@@ -0,0 +1,212 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// ListenConfig configures where the TCP gRPC server listens and how clients
+// are authenticated. The zero value listens on all interfaces in plaintext
+// without authentication.
+type ListenConfig struct {
+	// Listen is the address to bind: "host:port", a host alone (the port is
+	// added) or "" for all interfaces
+	Listen string
+	// CertFile and KeyFile enable TLS with a PEM certificate and key
+	CertFile string
+	KeyFile  string
+	// ClientCAFile requires client certificates signed by these CAs (mTLS)
+	ClientCAFile string
+	// TokenFile lists the accepted bearer tokens / API keys, one per line
+	TokenFile string
+}
+
+// Address returns the address to listen on for port
+func (c ListenConfig) Address(port string) string {
+	if c.Listen == "" {
+		return ":" + port
+	}
+	if _, _, err := net.SplitHostPort(c.Listen); err == nil {
+		return c.Listen
+	}
+	return net.JoinHostPort(strings.Trim(c.Listen, "[]"), port)
+}
+
+// ServerOptions returns the gRPC server options for TLS and token
+// authentication, and warns when passwords would travel in plaintext over
+// the network
+func (c ListenConfig) ServerOptions(port string, logger *log.Logger) ([]grpc.ServerOption, error) {
+	var opts []grpc.ServerOption
+
+	tlsConfig, err := c.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+	if tlsConfig != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(tlsConfig)))
+		if c.ClientCAFile != "" {
+			logger.Printf("gRPC TLS enabled with client certificate verification (mTLS)")
+		} else {
+			logger.Printf("gRPC TLS enabled")
+		}
+	} else if !isLoopback(c.Address(port)) {
+		logger.Printf("WARNING: gRPC server listens on %s without TLS, passwords are sent in plaintext (use -tls-cert/-tls-key or -listen=127.0.0.1)", c.Address(port))
+	}
+
+	if c.TokenFile != "" {
+		tokens, err := LoadTokens(c.TokenFile)
+		if err != nil {
+			return nil, err
+		}
+		auth := &tokenAuth{tokens: tokens, logger: logger}
+		opts = append(opts,
+			grpc.ChainUnaryInterceptor(auth.unary),
+			grpc.ChainStreamInterceptor(auth.stream),
+		)
+		logger.Printf("gRPC token authentication enabled (%d tokens)", len(tokens))
+	} else if !isLoopback(c.Address(port)) {
+		logger.Printf("WARNING: gRPC server listens on %s without authentication (use -auth-tokens)", c.Address(port))
+	}
+	return opts, nil
+}
+
+// tlsConfig returns the TLS configuration, or nil if TLS is not configured
+func (c ListenConfig) tlsConfig() (*tls.Config, error) {
+	if c.CertFile == "" && c.KeyFile == "" {
+		if c.ClientCAFile != "" {
+			return nil, errors.New("client CA requires a server certificate (-tls-cert and -tls-key)")
+		}
+		return nil, nil
+	}
+	if c.CertFile == "" || c.KeyFile == "" {
+		return nil, errors.New("TLS requires both a certificate and a key")
+	}
+
+	cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load TLS certificate: %w", err)
+	}
+	config := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	if c.ClientCAFile != "" {
+		pem, err := os.ReadFile(c.ClientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read client CA: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", c.ClientCAFile)
+		}
+		config.ClientCAs = pool
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return config, nil
+}
+
+// LoadTokens reads a token file: one token per line, empty lines and lines
+// starting with '#' are ignored
+func LoadTokens(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read token file: %w", err)
+	}
+	defer f.Close()
+
+	var tokens []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		tokens = append(tokens, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read token file: %w", err)
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("no tokens in %s", path)
+	}
+	return tokens, nil
+}
+
+// tokenAuth accepts calls carrying one of the tokens in the "authorization"
+// ("Bearer <token>") or "x-api-key" metadata
+type tokenAuth struct {
+	tokens []string
+	logger *log.Logger
+}
+
+func (a *tokenAuth) unary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if err := a.check(ctx, info.FullMethod); err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (a *tokenAuth) stream(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if err := a.check(ss.Context(), info.FullMethod); err != nil {
+		return err
+	}
+	return handler(srv, ss)
+}
+
+func (a *tokenAuth) check(ctx context.Context, method string) error {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	var presented []string
+	for _, v := range md.Get("authorization") {
+		if token, ok := strings.CutPrefix(v, "Bearer "); ok {
+			presented = append(presented, strings.TrimSpace(token))
+		}
+	}
+	presented = append(presented, md.Get("x-api-key")...)
+
+	for _, p := range presented {
+		for _, t := range a.tokens {
+			// タイミング攻撃を避けるため定数時間で比較する
+			if subtle.ConstantTimeCompare([]byte(p), []byte(t)) == 1 {
+				return nil
+			}
+		}
+	}
+
+	addr := "unknown"
+	if p, ok := peer.FromContext(ctx); ok {
+		addr = p.Addr.String()
+	}
+	a.logger.Printf("Unauthenticated gRPC call to %s from %s", method, addr)
+	return status.Error(codes.Unauthenticated, "missing or invalid token")
+}
+
+// isLoopback reports whether addr only accepts connections from this machine
+func isLoopback(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
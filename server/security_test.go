@@ -0,0 +1,230 @@
+package server
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"log"
+	"math/big"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	pb "github.com/scrape-vm/proto"
+)
+
+func TestListenConfigAddress(t *testing.T) {
+	for _, tt := range []struct {
+		listen, want string
+	}{
+		{"", ":50051"},
+		{"127.0.0.1", "127.0.0.1:50051"},
+		{"localhost", "localhost:50051"},
+		{"0.0.0.0:6000", "0.0.0.0:6000"},
+		{"::1", "[::1]:50051"},
+		{"[::1]", "[::1]:50051"},
+		{"[::1]:6000", "[::1]:6000"},
+	} {
+		if got := (ListenConfig{Listen: tt.listen}).Address("50051"); got != tt.want {
+			t.Errorf("Address(%q) = %q, want %q", tt.listen, got, tt.want)
+		}
+	}
+}
+
+func TestIsLoopback(t *testing.T) {
+	for addr, want := range map[string]bool{
+		"127.0.0.1:50051": true,
+		"127.0.0.2:50051": true,
+		"[::1]:50051":     true,
+		"localhost:50051": true,
+		":50051":          false,
+		"0.0.0.0:50051":   false,
+		"[::]:50051":      false,
+		"10.0.0.5:50051":  false,
+		"example.com:80":  false,
+		"127.0.0.1":       false,
+	} {
+		if got := isLoopback(addr); got != want {
+			t.Errorf("isLoopback(%q) = %v, want %v", addr, got, want)
+		}
+	}
+}
+
+func TestTLSConfig(t *testing.T) {
+	dir := t.TempDir()
+	cert, key := writeCert(t, dir)
+	empty := filepath.Join(dir, "empty.pem")
+	writeFile(t, empty, "")
+	missing := filepath.Join(dir, "missing.pem")
+
+	for _, tt := range []struct {
+		name    string
+		config  ListenConfig
+		wantErr string
+	}{
+		{"plaintext", ListenConfig{}, ""},
+		{"client CA without certificate", ListenConfig{ClientCAFile: cert}, "client CA requires a server certificate"},
+		{"certificate without key", ListenConfig{CertFile: cert}, "both a certificate and a key"},
+		{"key without certificate", ListenConfig{KeyFile: key}, "both a certificate and a key"},
+		{"missing certificate", ListenConfig{CertFile: missing, KeyFile: key}, "failed to load TLS certificate"},
+		{"key mismatch", ListenConfig{CertFile: key, KeyFile: cert}, "failed to load TLS certificate"},
+		{"missing client CA", ListenConfig{CertFile: cert, KeyFile: key, ClientCAFile: missing}, "failed to read client CA"},
+		{"empty client CA", ListenConfig{CertFile: cert, KeyFile: key, ClientCAFile: empty}, "no certificates found"},
+		{"TLS", ListenConfig{CertFile: cert, KeyFile: key}, ""},
+		{"mTLS", ListenConfig{CertFile: cert, KeyFile: key, ClientCAFile: cert}, ""},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			config, err := tt.config.tlsConfig()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if (config == nil) != (tt.config.CertFile == "") {
+				t.Fatalf("config = %v", config)
+			}
+			if config != nil && (config.ClientAuth == tls.RequireAndVerifyClientCert) != (tt.config.ClientCAFile != "") {
+				t.Errorf("ClientAuth = %v", config.ClientAuth)
+			}
+		})
+	}
+}
+
+func TestLoadTokens(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "tokens.txt")
+	writeFile(t, path, "# operators\ntoken-a\n\n  token-b  \n")
+	if tokens, err := LoadTokens(path); err != nil || len(tokens) != 2 || tokens[1] != "token-b" {
+		t.Errorf("LoadTokens = %v, %v", tokens, err)
+	}
+
+	writeFile(t, path, "# no tokens\n")
+	if _, err := LoadTokens(path); err == nil {
+		t.Error("LoadTokens(no tokens) succeeded")
+	}
+	if _, err := LoadTokens(filepath.Join(dir, "missing.txt")); err == nil {
+		t.Error("LoadTokens(missing) succeeded")
+	}
+}
+
+// healthServer answers Health; ScrapeStream is left unimplemented, so an
+// authenticated stream fails with Unimplemented after the interceptors
+type healthServer struct {
+	pb.UnimplementedETCScraperServer
+}
+
+func (healthServer) Health(context.Context, *pb.HealthRequest) (*pb.HealthResponse, error) {
+	return &pb.HealthResponse{Healthy: true}, nil
+}
+
+func TestTokenAuth(t *testing.T) {
+	tokens := filepath.Join(t.TempDir(), "tokens.txt")
+	writeFile(t, tokens, "secret-token\n")
+	opts, err := ListenConfig{Listen: "127.0.0.1", TokenFile: tokens}.ServerOptions("50051", log.New(io.Discard, "", 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer(opts...)
+	pb.RegisterETCScraperServer(srv, healthServer{})
+	go srv.Serve(lis)
+	defer srv.Stop()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	client := pb.NewETCScraperClient(conn)
+
+	for _, tt := range []struct {
+		name       string
+		md         []string
+		wantUnary  codes.Code
+		wantStream codes.Code
+	}{
+		{"no token", nil, codes.Unauthenticated, codes.Unauthenticated},
+		{"wrong bearer", []string{"authorization", "Bearer wrong"}, codes.Unauthenticated, codes.Unauthenticated},
+		{"token without scheme", []string{"authorization", "secret-token"}, codes.Unauthenticated, codes.Unauthenticated},
+		{"wrong api key", []string{"x-api-key", "secret"}, codes.Unauthenticated, codes.Unauthenticated},
+		{"bearer", []string{"authorization", "Bearer secret-token"}, codes.OK, codes.Unimplemented},
+		{"api key", []string{"x-api-key", "secret-token"}, codes.OK, codes.Unimplemented},
+		{"one of several", []string{"x-api-key", "wrong", "x-api-key", "secret-token"}, codes.OK, codes.Unimplemented},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if tt.md != nil {
+				ctx = metadata.AppendToOutgoingContext(ctx, tt.md...)
+			}
+
+			resp, err := client.Health(ctx, &pb.HealthRequest{})
+			if status.Code(err) != tt.wantUnary || (err == nil && !resp.Healthy) {
+				t.Errorf("Health: %v, want %v", err, tt.wantUnary)
+			}
+
+			stream, err := client.ScrapeStream(ctx, &pb.ScrapeMultipleRequest{})
+			if err == nil {
+				_, err = stream.Recv()
+			}
+			if status.Code(err) != tt.wantStream {
+				t.Errorf("ScrapeStream: %v, want %v", err, tt.wantStream)
+			}
+		})
+	}
+}
+
+// writeCert writes a self-signed certificate for localhost and its key, and
+// returns their paths
+func writeCert(t *testing.T, dir string) (string, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "localhost"},
+		DNSNames:              []string{"localhost"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certPath, keyPath := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	writeFile(t, certPath, string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})))
+	writeFile(t, keyPath, string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})))
+	return certPath, keyPath
+}
@@ -0,0 +1,274 @@
+package server
+
+import (
+	"archive/zip"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/scrape-vm/jobs"
+	"github.com/scrape-vm/parser"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/scrape-vm/proto"
+)
+
+// DefaultSessionPageSize is the page size of ListSessions without page_size
+const DefaultSessionPageSize = 50
+
+// sessionLayout is the name of the session folders created by the engine
+const sessionLayout = "20060102_150405"
+
+var sessionPattern = regexp.MustCompile(`^\d{8}_\d{6}$`)
+
+// ErrSessionNotFound is returned for a well-formed session ID without a folder
+var ErrSessionNotFound = errors.New("session not found")
+
+// Sessions returns the session IDs (YYYYMMDD_HHMMSS) in root, newest first:
+// the session folders and the <id>.zip archives of the retention policy
+func Sessions(root string) []string {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil
+	}
+	seen := make(map[string]bool)
+	var list []string
+	for _, e := range entries {
+		id := e.Name()
+		if !e.IsDir() {
+			if id = strings.TrimSuffix(id, ".zip"); id == e.Name() {
+				continue
+			}
+		}
+		if sessionPattern.MatchString(id) && !seen[id] {
+			seen[id] = true
+			list = append(list, id)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(list)))
+	return list
+}
+
+// latestFinishedSession returns the ID of the newest session in root that no
+// queued or running job of jobManager (optional) is downloading into, or ""
+func latestFinishedSession(root string, jobManager *jobs.Manager) string {
+	busy := make(map[string]bool)
+	if jobManager != nil {
+		for _, folder := range jobManager.ActiveSessions() {
+			busy[filepath.Base(folder)] = true
+		}
+	}
+	for _, id := range Sessions(root) {
+		if !busy[id] {
+			return id
+		}
+	}
+	return ""
+}
+
+// sessionFS is the files of a session: its folder, or the zip archive the
+// retention policy compressed it into
+type sessionFS struct {
+	fs.FS
+	archived bool
+	zip      *zip.ReadCloser
+}
+
+// openSession opens a session ID from a request. Close the result when done.
+func openSession(root, id string) (*sessionFS, error) {
+	dir, err := SessionDir(root, id)
+	if err == nil {
+		return &sessionFS{FS: os.DirFS(dir)}, nil
+	}
+	if !errors.Is(err, ErrSessionNotFound) {
+		return nil, err
+	}
+	zr, zerr := zip.OpenReader(filepath.Join(root, id+".zip"))
+	if zerr != nil {
+		return nil, err
+	}
+	// アーカイブにはセッションIDのフォルダごと格納されている
+	sub, zerr := fs.Sub(zr, id)
+	if zerr != nil {
+		zr.Close()
+		return nil, zerr
+	}
+	return &sessionFS{FS: sub, archived: true, zip: zr}, nil
+}
+
+// Close releases the archive of a compressed session
+func (s *sessionFS) Close() error {
+	if s.zip == nil {
+		return nil
+	}
+	return s.zip.Close()
+}
+
+// SessionDir returns the folder of a session ID from a request. Only session
+// folder names are accepted, so the ID cannot point outside root.
+func SessionDir(root, id string) (string, error) {
+	if !sessionPattern.MatchString(id) {
+		return "", fmt.Errorf("%w: session %q", ErrInvalidPath, id)
+	}
+	dir := filepath.Join(root, id)
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return "", fmt.Errorf("%w: %s", ErrSessionNotFound, id)
+	}
+	return dir, nil
+}
+
+// ListSessions runs the ListSessions RPC on the session folders of root
+func ListSessions(root string, jobManager *jobs.Manager, req *pb.ListSessionsRequest) (*pb.ListSessionsResponse, error) {
+	if req.PageToken != "" && !sessionPattern.MatchString(req.PageToken) {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid page_token %q", req.PageToken)
+	}
+	size := int(req.PageSize)
+	if size <= 0 {
+		size = DefaultSessionPageSize
+	}
+
+	all := Sessions(root)
+	start := 0
+	if req.PageToken != "" {
+		// トークンは前ページの最後のセッションID。それより古いものから返す
+		start = sort.Search(len(all), func(i int) bool { return all[i] < req.PageToken })
+	}
+	end := min(start+size, len(all))
+
+	jobIDs := sessionJobs(jobManager)
+	resp := &pb.ListSessionsResponse{Total: int32(len(all))}
+	for _, id := range all[start:end] {
+		sess, err := openSession(root, id)
+		if err != nil {
+			// 一覧の取得後に削除された
+			resp.Sessions = append(resp.Sessions, &pb.Session{SessionId: id, JobId: jobIDs[id]})
+			continue
+		}
+		session, _ := sessionSummary(sess, id, jobIDs[id])
+		sess.Close()
+		resp.Sessions = append(resp.Sessions, session)
+	}
+	if end < len(all) {
+		resp.NextPageToken = all[end-1]
+	}
+	return resp, nil
+}
+
+// GetSession runs the GetSession RPC
+func GetSession(root string, jobManager *jobs.Manager, req *pb.GetSessionRequest) (*pb.Session, error) {
+	sess, err := openSession(root, req.SessionId)
+	if err != nil {
+		return nil, SessionStatusError(err)
+	}
+	defer sess.Close()
+	session, files := sessionSummary(sess, req.SessionId, sessionJobs(jobManager)[req.SessionId])
+	session.Files = files
+	return session, nil
+}
+
+// GetFile runs the GetFile RPC. CSV files are returned in the requested
+// encoding with their parsed records, other files as they are.
+func GetFile(root string, req *pb.GetFileRequest, logger *log.Logger) (*pb.DownloadedFile, error) {
+	name, err := cleanPath(req.Filename)
+	if err != nil {
+		return nil, SessionStatusError(err)
+	}
+	sess, err := openSession(root, req.SessionId)
+	if err != nil {
+		return nil, SessionStatusError(err)
+	}
+	defer sess.Close()
+	info, err := fs.Stat(sess, name)
+	if err != nil || info.IsDir() {
+		return nil, status.Errorf(codes.NotFound, "file %s not found in session %s", req.Filename, req.SessionId)
+	}
+
+	file := &pb.DownloadedFile{Filename: req.Filename}
+	if isCSV(name) {
+		file.Content, err = parser.ReadFileFS(sess, name, FileEncoding(req.Encoding))
+		if data, rerr := fs.ReadFile(sess, name); rerr == nil {
+			file.Records = ToProtoRecords(parseRecords(name, data, logger))
+		}
+	} else {
+		file.Content, err = fs.ReadFile(sess, name)
+	}
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return file, nil
+}
+
+// SessionStatusError converts a session lookup error to a gRPC status error
+func SessionStatusError(err error) error {
+	switch {
+	case errors.Is(err, ErrInvalidPath):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, ErrSessionNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	}
+	return status.Error(codes.Internal, err.Error())
+}
+
+// sessionSummary walks a session. Temporary download folders (".download-*")
+// are skipped.
+func sessionSummary(sess *sessionFS, id, jobID string) (*pb.Session, []*pb.SessionFile) {
+	session := &pb.Session{SessionId: id, JobId: jobID, Archived: sess.archived}
+	if t, err := time.ParseInLocation(sessionLayout, id, time.Local); err == nil {
+		session.CreatedAt = formatTime(t)
+	}
+
+	var files []*pb.SessionFile
+	fs.WalkDir(sess, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			if name != "." && strings.HasPrefix(d.Name(), ".") {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil || !info.Mode().IsRegular() {
+			return nil
+		}
+		files = append(files, &pb.SessionFile{
+			Name:       name,
+			Size:       info.Size(),
+			ModifiedAt: formatTime(info.ModTime()),
+		})
+		session.FileCount++
+		session.TotalBytes += info.Size()
+		// アカウントごとのCSVはセッション直下に1つ
+		if path.Dir(name) == "." && isCSV(name) {
+			session.AccountCount++
+		}
+		return nil
+	})
+	return session, files
+}
+
+// sessionJobs maps session IDs to the jobs still in the job store
+func sessionJobs(jobManager *jobs.Manager) map[string]string {
+	ids := make(map[string]string)
+	if jobManager == nil {
+		return ids
+	}
+	// 新しい順なので、同じセッションのジョブが複数あれば最初のものを使う
+	for _, job := range jobManager.List(0) {
+		name := filepath.Base(job.SessionFolder)
+		if _, ok := ids[name]; !ok {
+			ids[name] = job.ID
+		}
+	}
+	return ids
+}
@@ -0,0 +1,183 @@
+package server
+
+import (
+	"archive/zip"
+	"context"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/scrape-vm/jobs"
+	pb "github.com/scrape-vm/proto"
+)
+
+func TestSessions(t *testing.T) {
+	root := t.TempDir()
+	for _, name := range []string{
+		"20250101_090000/user1_meisai.csv",
+		"20250102_090000/user1_meisai.csv",
+		"20250102_090000/user2_meisai.csv",
+		"20250102_090000/original/user1_meisai.csv",
+		"20250102_090000/.download-user3/partial.csv",
+		"20250103_090000/artifacts/user1_090000/error.txt",
+		"not-a-session/secret.txt",
+	} {
+		writeFile(t, filepath.Join(root, filepath.FromSlash(name)), "利用年月日(至),時刻(至),利用IC(至),通行料金,ETCカード番号\n")
+	}
+
+	first, err := ListSessions(root, nil, &pb.ListSessionsRequest{PageSize: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.Total != 3 || len(first.Sessions) != 2 || first.Sessions[0].SessionId != "20250103_090000" || first.NextPageToken != "20250102_090000" {
+		t.Fatalf("first page = %v", first)
+	}
+	second, err := ListSessions(root, nil, &pb.ListSessionsRequest{PageSize: 2, PageToken: first.NextPageToken})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(second.Sessions) != 1 || second.Sessions[0].SessionId != "20250101_090000" || second.NextPageToken != "" {
+		t.Fatalf("second page = %v", second)
+	}
+
+	session, err := GetSession(root, nil, &pb.GetSessionRequest{SessionId: "20250102_090000"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if session.AccountCount != 2 || session.FileCount != 3 || len(session.Files) != 3 {
+		t.Errorf("session = %v, want 2 accounts and 3 files", session)
+	}
+
+	file, err := GetFile(root, &pb.GetFileRequest{SessionId: "20250102_090000", Filename: "original/user1_meisai.csv"}, log.Default())
+	if err != nil || len(file.Content) == 0 {
+		t.Errorf("GetFile = %v, %v", file, err)
+	}
+}
+
+func TestArchivedSessions(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "20250102_090000", "user1_meisai.csv"), meisaiFixture)
+	// 保持ポリシーで圧縮されたセッション
+	writeZip(t, filepath.Join(root, "20250101_090000.zip"), map[string]string{
+		"20250101_090000/user1_meisai.csv":          meisaiFixture,
+		"20250101_090000/original/user1_meisai.csv": meisaiFixture,
+	})
+	writeFile(t, filepath.Join(root, "20250103_090000.zip.tmp"), "partial")
+
+	list, err := ListSessions(root, nil, &pb.ListSessionsRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if list.Total != 2 || list.Sessions[0].Archived || !list.Sessions[1].Archived || list.Sessions[1].AccountCount != 1 || list.Sessions[1].FileCount != 2 {
+		t.Fatalf("sessions = %v", list.Sessions)
+	}
+
+	session, err := GetSession(root, nil, &pb.GetSessionRequest{SessionId: "20250101_090000"})
+	if err != nil || !session.Archived || len(session.Files) != 2 || session.Files[0].Name != "original/user1_meisai.csv" {
+		t.Fatalf("GetSession = %v, %v", session, err)
+	}
+
+	file, err := GetFile(root, &pb.GetFileRequest{SessionId: "20250101_090000", Filename: "user1_meisai.csv"}, log.Default())
+	if err != nil || string(file.Content) != meisaiFixture || len(file.Records) != 1 {
+		t.Errorf("GetFile = %v, %v", file, err)
+	}
+	if _, err := GetFile(root, &pb.GetFileRequest{SessionId: "20250101_090000", Filename: "missing.csv"}, log.Default()); status.Code(err) != codes.NotFound {
+		t.Errorf("GetFile(missing): %v, want NotFound", err)
+	}
+
+	// ジョブに記録された圧縮前のパスからも読める
+	if records := ParseRecords(filepath.Join(root, "20250101_090000", "user1_meisai.csv"), log.Default()); len(records) != 1 || records[0].ExitIC != "厚木" {
+		t.Errorf("ParseRecords(archived) = %v", records)
+	}
+
+	files := &SessionFiles{Root: root}
+	if _, list, err := files.List("20250101_090000"); err != nil || len(list) != 1 || list[0].Path != "20250101_090000/user1_meisai.csv" {
+		t.Errorf("SessionFiles.List = %v, %v", list, err)
+	}
+	if data, err := files.ReadFile("20250101_090000/original/user1_meisai.csv", ""); err != nil || string(data) != meisaiFixture {
+		t.Errorf("SessionFiles.ReadFile = %q, %v", data, err)
+	}
+}
+
+func TestGetDownloadedFiles(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "20250101_090000", "user1_meisai.csv"), meisaiFixture)
+	writeFile(t, filepath.Join(root, "20250102_090000", ".download-user1", "partial.csv"), "x")
+	// セッション以外のフォルダ（名前順では最後）
+	writeFile(t, filepath.Join(root, "zz-backup", "user1_meisai.csv"), meisaiFixture)
+
+	jobManager := jobs.NewManager("", log.New(io.Discard, "", 0))
+	jobManager.Create([]string{"user1"}, filepath.Join(root, "20250102_090000"))
+	srv := &GRPCServer{Logger: log.New(io.Discard, "", 0), DownloadPath: root, Jobs: jobManager}
+
+	resp, err := srv.GetDownloadedFiles(context.Background(), &pb.GetDownloadedFilesRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.SessionFolder != "20250101_090000" || len(resp.Files) != 1 || len(resp.Files[0].Records) != 1 {
+		t.Errorf("GetDownloadedFiles = %v, want the finished session 20250101_090000", resp)
+	}
+}
+
+func TestSessionsRejectTraversal(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "20250101_090000", "user1_meisai.csv"), "x")
+	writeFile(t, filepath.Join(root, "jobs.json"), "{}")
+
+	for _, req := range []*pb.GetFileRequest{
+		{SessionId: "..", Filename: "jobs.json"},
+		{SessionId: "20250101_090000/..", Filename: "jobs.json"},
+		{SessionId: "20250101_090000", Filename: "../jobs.json"},
+		{SessionId: "20250101_090000", Filename: `..\jobs.json`},
+		{SessionId: "20250101_090000", Filename: "/etc/passwd"},
+		{SessionId: "20250101_090000", Filename: ""},
+	} {
+		_, err := GetFile(root, req, log.Default())
+		if status.Code(err) != codes.InvalidArgument {
+			t.Errorf("GetFile(%q, %q): %v, want InvalidArgument", req.SessionId, req.Filename, err)
+		}
+	}
+	if _, err := GetSession(root, nil, &pb.GetSessionRequest{SessionId: "20991231_000000"}); status.Code(err) != codes.NotFound {
+		t.Errorf("GetSession(missing): %v, want NotFound", err)
+	}
+	if _, err := ListSessions(root, nil, &pb.ListSessionsRequest{PageToken: "../x"}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("ListSessions(bad token): %v, want InvalidArgument", err)
+	}
+}
+
+const meisaiFixture = "利用年月日(至),時刻(至),利用IC(至),通行料金,ETCカード番号\n25/01/19,07:00,厚木,800,****1234\n"
+
+func writeZip(t *testing.T, path string, files map[string]string) {
+	t.Helper()
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	zw := zip.NewWriter(f)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(content))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,133 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/scrape-vm/engine"
+	"github.com/scrape-vm/jobs"
+	"github.com/scrape-vm/scrapers"
+
+	pb "github.com/scrape-vm/proto"
+)
+
+// EventStream sends ScrapeStream events for a job. It is safe for concurrent use.
+// The job ID is set with SetJob once the job is created; events are sent only
+// after that, when the engine starts running the accounts.
+type EventStream struct {
+	mu     sync.Mutex
+	stream pb.ETCScraper_ScrapeStreamServer
+	jobID  string
+	logger *log.Logger
+}
+
+// NewEventStream creates an event stream on the server stream
+func NewEventStream(stream pb.ETCScraper_ScrapeStreamServer, logger *log.Logger) *EventStream {
+	return &EventStream{stream: stream, logger: logger}
+}
+
+// SetJob sets the job ID attached to the events
+func (e *EventStream) SetJob(jobID string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.jobID = jobID
+}
+
+// Hooks returns engine hooks sending the progress and results of the run
+func (e *EventStream) Hooks() engine.Hooks {
+	return engine.Hooks{
+		OnProgress:        e.Progress,
+		OnAccountFinished: e.AccountFinished,
+	}
+}
+
+// Progress sends a stage reached by the account at index
+func (e *EventStream) Progress(index int, ev scrapers.ProgressEvent) {
+	e.Send(&pb.ScrapeEvent{
+		AccountIndex: int32(index),
+		UserId:       ev.UserID,
+		Stage:        ScrapeStage(ev.Stage),
+		Message:      ev.Message,
+		Timestamp:    ev.Time.Format(time.RFC3339),
+	})
+}
+
+// AccountFinished sends the result of an account
+func (e *EventStream) AccountFinished(ctx context.Context, r engine.Result) {
+	result := &pb.ScrapeResult{
+		UserId:  r.UserID,
+		Success: r.Err == nil,
+		CsvPath: r.FilePath,
+	}
+	switch {
+	case r.Err == nil:
+		result.Message = "Scrape completed successfully"
+		result.State = pb.JobState_JOB_STATE_SUCCEEDED
+		result.Records = ToProtoRecords(ParseRecords(r.FilePath, e.logger))
+	case ctx.Err() != nil:
+		result.Message = r.Err.Error()
+		result.State = pb.JobState_JOB_STATE_CANCELLED
+	default:
+		result.Message = r.Err.Error()
+		result.State = pb.JobState_JOB_STATE_FAILED
+		result.ErrorCode = ErrorCode(r.Err)
+		result.HasArtifacts = scrapers.ArtifactsDir(r.Err) != ""
+	}
+
+	e.Send(&pb.ScrapeEvent{
+		AccountIndex: int32(r.Index),
+		UserId:       r.UserID,
+		Stage:        pb.ScrapeStage_SCRAPE_STAGE_ACCOUNT_FINISHED,
+		Message:      result.Message,
+		Timestamp:    time.Now().Format(time.RFC3339),
+		Result:       result,
+	})
+}
+
+// JobFinished sends the final event with the job summary
+func (e *EventStream) JobFinished(job *jobs.Job) error {
+	if job == nil {
+		return errors.New("job not found")
+	}
+	return e.Send(&pb.ScrapeEvent{
+		Stage:     pb.ScrapeStage_SCRAPE_STAGE_JOB_FINISHED,
+		Message:   string(job.State),
+		Timestamp: time.Now().Format(time.RFC3339),
+		Job:       ToProtoJob(job, false, e.logger),
+	})
+}
+
+// Send sends an event tagged with the job ID. Send failures (the client went away)
+// are logged; the job is stopped through the stream context.
+func (e *EventStream) Send(ev *pb.ScrapeEvent) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	ev.JobId = e.jobID
+
+	if err := e.stream.Send(ev); err != nil {
+		e.logger.Printf("Warning: could not send progress event: %v", err)
+		return err
+	}
+	return nil
+}
+
+// ScrapeStage maps a scraper stage to the protobuf enum
+func ScrapeStage(s scrapers.Stage) pb.ScrapeStage {
+	switch s {
+	case scrapers.StageInitialize:
+		return pb.ScrapeStage_SCRAPE_STAGE_INITIALIZE
+	case scrapers.StageLogin:
+		return pb.ScrapeStage_SCRAPE_STAGE_LOGIN
+	case scrapers.StageSearch:
+		return pb.ScrapeStage_SCRAPE_STAGE_SEARCH
+	case scrapers.StageCSVClick:
+		return pb.ScrapeStage_SCRAPE_STAGE_CSV_CLICK
+	case scrapers.StageDownloadComplete:
+		return pb.ScrapeStage_SCRAPE_STAGE_DOWNLOAD_COMPLETE
+	}
+	return pb.ScrapeStage_SCRAPE_STAGE_UNSPECIFIED
+}
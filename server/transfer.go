@@ -0,0 +1,141 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/pion/webrtc/v4"
+	"github.com/scrape-vm/access"
+	"github.com/scrape-vm/p2p"
+	"github.com/scrape-vm/parser"
+)
+
+// ErrInvalidPath is returned for file paths outside the download directory
+var ErrInvalidPath = errors.New("invalid path")
+
+// SessionFiles serves the sessions of the download directory, including the
+// archives of compressed sessions, on the P2P file transfer channel. Paths are
+// "<session>/<file>", relative to Root.
+type SessionFiles struct {
+	Root string
+}
+
+// FileChannel returns the P2P channel handler serving the session folders of
+// downloadPath with chunked transfers. Requests are checked against policy
+// (access.MethodFileList, access.MethodFileGet); a nil policy allows all.
+func FileChannel(downloadPath string, policy *access.Policy, logger *log.Logger) p2p.ChannelHandler {
+	return func(dc *webrtc.DataChannel, browser p2p.BrowserIdentity) {
+		user := BrowserUser(browser)
+		logger.Printf("File transfer channel opened (user: %s)", user)
+		p2p.ServeFiles(dc, p2p.FileServerConfig{
+			Source: &SessionFiles{Root: downloadPath},
+			Logger: logger,
+			Authorize: func(requestType string) error {
+				method := access.MethodFileGet
+				if requestType == "list" {
+					method = access.MethodFileList
+				}
+				return policy.Authorize(user, method)
+			},
+			OnProgress: func(p p2p.TransferProgress) {
+				if !p.Done && p.Err == nil {
+					logger.Printf("File transfer %s: %d/%d bytes", p.TransferID, p.Sent, p.Size)
+				}
+			},
+		})
+	}
+}
+
+// List returns the files of a session folder, the latest one if session is empty
+func (f *SessionFiles) List(session string) (string, []p2p.FileInfo, error) {
+	if session == "" {
+		session = LatestSession(f.Root)
+		if session == "" {
+			return "", nil, nil
+		}
+	}
+	sess, err := openSession(f.Root, session)
+	if err != nil {
+		return "", nil, err
+	}
+	defer sess.Close()
+
+	entries, err := fs.ReadDir(sess, ".")
+	if err != nil {
+		return "", nil, fmt.Errorf("session %s: %w", session, err)
+	}
+	var files []p2p.FileInfo
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, p2p.FileInfo{Path: path.Join(session, e.Name()), Size: info.Size()})
+	}
+	return session, files, nil
+}
+
+// ReadFile reads a file of a session, from its folder or its archive. CSV
+// files are converted to the given encoding; other files, and any file with an
+// empty encoding, are sent as stored. Other files of the download directory
+// (jobs.json, the history database...) are not served.
+func (f *SessionFiles) ReadFile(name, encoding string) ([]byte, error) {
+	session, rest, ok := strings.Cut(name, "/")
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidPath, name)
+	}
+	rest, err := cleanPath(rest)
+	if err != nil {
+		return nil, err
+	}
+	sess, err := openSession(f.Root, session)
+	if err != nil {
+		return nil, err
+	}
+	defer sess.Close()
+	if encoding == "" || !isCSV(rest) {
+		return fs.ReadFile(sess, rest)
+	}
+	enc, err := parser.ParseEncoding(encoding)
+	if err != nil {
+		return nil, err
+	}
+	return parser.ReadFileFS(sess, rest, enc)
+}
+
+// LatestSession returns the ID of the newest session (YYYYMMDD_HHMMSS) in root
+func LatestSession(root string) string {
+	if list := Sessions(root); len(list) > 0 {
+		return list[0]
+	}
+	return ""
+}
+
+// cleanPath validates a slash-separated path of a session file from a request,
+// rejecting absolute paths, paths leaving the session and dot-prefixed (hidden
+// or temporary, like ".download-*") segments
+func cleanPath(name string) (string, error) {
+	if name == "" || strings.Contains(name, "\\") || path.IsAbs(name) || filepath.IsAbs(name) || filepath.VolumeName(name) != "" {
+		return "", fmt.Errorf("%w: %q", ErrInvalidPath, name)
+	}
+	clean := path.Clean(name)
+	for _, segment := range strings.Split(clean, "/") {
+		if strings.HasPrefix(segment, ".") {
+			return "", fmt.Errorf("%w: %q", ErrInvalidPath, name)
+		}
+	}
+	return clean, nil
+}
+
+// isCSV reports whether a session file is a downloaded CSV
+func isCSV(name string) bool {
+	return strings.EqualFold(path.Ext(name), ".csv")
+}
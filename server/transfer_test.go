@@ -0,0 +1,57 @@
+package server
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/scrape-vm/parser"
+)
+
+func TestSessionFilesReadFile(t *testing.T) {
+	root := t.TempDir()
+	csv := "利用年月日(至),時刻(至)\n"
+	png := "\x89PNG\r\n\x1a\n\x00\x81\x9f\xe0"
+	writeFile(t, filepath.Join(root, "20250101_090000", "user1_meisai.csv"), csv)
+	writeFile(t, filepath.Join(root, "20250101_090000", "artifacts", "user1_090000", "screenshot.png"), png)
+	writeFile(t, filepath.Join(root, "20250101_090000", ".download-user2", "partial.csv"), csv)
+	writeFile(t, filepath.Join(root, "jobs.json"), "{}")
+	writeFile(t, filepath.Join(root, "history.db"), "db")
+	files := &SessionFiles{Root: root}
+
+	for _, name := range []string{
+		"jobs.json",
+		"history.db",
+		"not-a-session/x.csv",
+		"20250101_090000",
+		"20250101_090000/.download-user2/partial.csv",
+		"20250101_090000/../jobs.json",
+		"20991231_000000/user1_meisai.csv",
+	} {
+		if _, err := files.ReadFile(name, ""); !errors.Is(err, ErrInvalidPath) && !errors.Is(err, ErrSessionNotFound) {
+			t.Errorf("ReadFile(%q) = %v, want rejection", name, err)
+		}
+	}
+
+	// バイナリと encoding 未指定のCSVは保存されたまま返す
+	for name, want := range map[string]string{
+		"20250101_090000/artifacts/user1_090000/screenshot.png": png,
+		"20250101_090000/user1_meisai.csv":                      csv,
+	} {
+		got, err := files.ReadFile(name, "")
+		if err != nil || string(got) != want {
+			t.Errorf("ReadFile(%q) = %q, %v, want %q", name, got, err, want)
+		}
+	}
+	got, err := files.ReadFile("20250101_090000/artifacts/user1_090000/screenshot.png", "shift_jis")
+	if err != nil || string(got) != png {
+		t.Errorf("ReadFile(png, shift_jis) = %q, %v, want the stored bytes", got, err)
+	}
+
+	sjis, _ := parser.ToShiftJIS([]byte(csv))
+	got, err = files.ReadFile("20250101_090000/user1_meisai.csv", "shift_jis")
+	if err != nil || !bytes.Equal(got, sjis) {
+		t.Errorf("ReadFile(csv, shift_jis) = %q, %v, want %q", got, err, sjis)
+	}
+}
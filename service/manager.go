@@ -5,8 +5,10 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"time"
 
 	svc "github.com/kardianos/service"
+	"github.com/scrape-vm/access"
 )
 
 // Manager handles service management operations
@@ -70,8 +72,38 @@ func buildServiceArgs(prg *Program) []string {
 			}
 		}
 		args = append(args, "-p2p-creds="+credsFile)
+		if prg.P2PMaxPeers > 0 {
+			args = append(args, fmt.Sprintf("-p2p-max-peers=%d", prg.P2PMaxPeers))
+		}
+		if prg.P2PPolicy != "" {
+			policyFile := prg.P2PPolicy
+			// allow-all はファイル名ではない
+			if absPath, err := filepath.Abs(policyFile); err == nil && policyFile != access.AllowAll {
+				policyFile = absPath
+			}
+			args = append(args, "-p2p-policy="+policyFile)
+		}
 	} else {
 		args = append(args, "-grpc", "-port="+prg.GRPCPort)
+		if prg.GRPCListen.Listen != "" {
+			args = append(args, "-listen="+prg.GRPCListen.Listen)
+		}
+		// 証明書・トークンファイルは絶対パスで渡す
+		for _, f := range []struct{ flag, path string }{
+			{"-tls-cert", prg.GRPCListen.CertFile},
+			{"-tls-key", prg.GRPCListen.KeyFile},
+			{"-tls-client-ca", prg.GRPCListen.ClientCAFile},
+			{"-auth-tokens", prg.GRPCListen.TokenFile},
+		} {
+			if f.path == "" {
+				continue
+			}
+			path := f.path
+			if absPath, err := filepath.Abs(path); err == nil {
+				path = absPath
+			}
+			args = append(args, f.flag+"="+path)
+		}
 	}
 
 	// Use absolute path for download directory
@@ -83,12 +115,54 @@ func buildServiceArgs(prg *Program) []string {
 	}
 	args = append(args, "-download="+downloadPath)
 
+	// 保持ポリシー（日数はフラグと同じ単位に戻す）
+	if r := prg.Retention; r.Enabled() {
+		if r.KeepSessions > 0 {
+			args = append(args, fmt.Sprintf("-keep-sessions=%d", r.KeepSessions))
+		}
+		if r.MaxAge > 0 {
+			args = append(args, fmt.Sprintf("-keep-days=%d", r.MaxAge/(24*time.Hour)))
+		}
+		if r.MaxBytes > 0 {
+			args = append(args, fmt.Sprintf("-max-download-mb=%d", r.MaxBytes>>20))
+		}
+		if r.CompressAfter > 0 {
+			args = append(args, fmt.Sprintf("-compress-after-days=%d", r.CompressAfter/(24*time.Hour)))
+		}
+	}
+
+	if prg.SchedulesFile != "" {
+		if absPath, err := filepath.Abs(prg.SchedulesFile); err == nil {
+			args = append(args, "-schedules="+absPath)
+		}
+	}
+
+	// 保管庫と鍵ファイルは絶対パスで渡す
+	if prg.VaultPath != "" {
+		if absPath, err := filepath.Abs(prg.VaultPath); err == nil {
+			args = append(args, "-vault="+absPath)
+		}
+	}
+	if prg.VaultKeyFile != "" {
+		if absPath, err := filepath.Abs(prg.VaultKeyFile); err == nil {
+			args = append(args, "-vault-key="+absPath)
+		}
+	}
+
 	if prg.Headless {
 		args = append(args, "-headless=true")
 	} else {
 		args = append(args, "-headless=false")
 	}
 
+	if prg.KeepOriginal {
+		args = append(args, "-keep-original=true")
+	}
+
+	if prg.Parallel > 1 {
+		args = append(args, fmt.Sprintf("-parallel=%d", prg.Parallel))
+	}
+
 	if prg.AutoUpdate {
 		args = append(args, "-auto-update=true")
 	} else {
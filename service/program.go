@@ -2,7 +2,6 @@ package service
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"io"
 	"log"
@@ -10,16 +9,23 @@ import (
 	"os"
 	"path/filepath"
 	"sync"
-	"time"
 
 	"github.com/anthropics/cf-wbrtc-auth/go/grpcweb"
 	"github.com/kardianos/service"
 	"github.com/pion/webrtc/v4"
+	"github.com/scrape-vm/access"
+	"github.com/scrape-vm/dedup"
+	"github.com/scrape-vm/engine"
+	"github.com/scrape-vm/history"
+	"github.com/scrape-vm/jobs"
 	"github.com/scrape-vm/p2p"
 	pb "github.com/scrape-vm/proto"
+	"github.com/scrape-vm/retention"
+	"github.com/scrape-vm/schedule"
 	"github.com/scrape-vm/scrapers"
 	"github.com/scrape-vm/server"
 	"github.com/scrape-vm/updater"
+	"github.com/scrape-vm/vault"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/reflection"
 )
@@ -28,8 +34,11 @@ import (
 type Program struct {
 	Logger       *log.Logger
 	GRPCPort     string
+	GRPCListen   server.ListenConfig // listen address, TLS and token authentication of the TCP server
 	DownloadPath string
 	Headless     bool
+	KeepOriginal bool // keep original Shift_JIS files next to the UTF-8 copies
+	Parallel     int  // accounts scraped concurrently, each with its own browser
 	Version      string
 
 	// Auto-update settings
@@ -42,6 +51,18 @@ type Program struct {
 	P2PAPIKey    string
 	P2PAppName   string
 	P2PCredsFile string
+	P2PMaxPeers  int    // browsers connected at the same time (default p2p.DefaultMaxPeers)
+	P2PPolicy    string // access policy file for browser users (empty: viewers only, access.AllowAll: every user allowed)
+
+	// Recurring scrapes (default schedule.DefaultFile next to the executable)
+	SchedulesFile string
+
+	// Cleanup of old sessions in DownloadPath, applied every retention.DefaultInterval
+	Retention retention.Policy
+
+	// Account vault resolving account_ref (key file defaults to vault.key next to P2PCredsFile)
+	VaultPath    string
+	VaultKeyFile string
 
 	ctx        context.Context
 	cancel     context.CancelFunc
@@ -49,7 +70,12 @@ type Program struct {
 	grpcServer *grpc.Server
 	p2pClient  *p2p.Client
 	updater    *updater.Updater
-	logFile    *os.File // ログファイルハンドル（サービス終了時にクローズ）
+	jobs       *jobs.Manager
+	pool       *scrapers.BrowserPool
+	engine     *engine.Engine
+	scheduler  *schedule.Scheduler
+	history    *history.DB
+	logFile    *rotatingFile // ログファイル（サイズでローテーション、サービス終了時にクローズ）
 }
 
 // Start is called when the service starts
@@ -91,6 +117,14 @@ func (p *Program) Stop(s service.Service) error {
 	}
 	p.cancel()
 
+	// Cancel running scrape jobs
+	if p.jobs != nil {
+		p.jobs.Shutdown()
+	}
+	if p.pool != nil {
+		p.pool.Close()
+	}
+
 	// Stop P2P client
 	if p.p2pClient != nil {
 		p.p2pClient.Close()
@@ -102,6 +136,11 @@ func (p *Program) Stop(s service.Service) error {
 	}
 
 	p.wg.Wait()
+
+	// ジョブのキャンセル後に履歴データベースを閉じる
+	if p.history != nil {
+		p.history.Close()
+	}
 	p.Logger.Println("Service stopped")
 
 	// ログファイルをクローズ
@@ -125,7 +164,7 @@ func (p *Program) setupFileLogger() error {
 	}
 
 	logFile := filepath.Join(logDir, "etc-scraper.log")
-	f, err := os.OpenFile(logFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	f, err := openRotatingFile(logFile, DefaultLogMaxSize, DefaultLogBackups)
 	if err != nil {
 		return fmt.Errorf("failed to open log file %s: %w", logFile, err)
 	}
@@ -172,11 +211,31 @@ func (p *Program) run() {
 		p.Logger.Printf("Failed to create download directory: %v", err)
 	}
 
+	// Job store lives next to the session folders
+	p.jobs = jobs.NewManager(filepath.Join(p.DownloadPath, jobs.StoreFile), p.Logger)
+	p.jobs.Parallel = p.Parallel
+	p.history = server.OpenHistory(p.DownloadPath, p.jobs, p.Logger)
+	p.pool = scrapers.NewBrowserPool(p.Parallel, p.Headless, p.Logger)
+	p.engine = &engine.Engine{
+		Jobs:         p.jobs,
+		Pool:         p.pool,
+		Vault:        p.openVault(),
+		Records:      dedup.NewStore(filepath.Join(p.DownloadPath, dedup.StoreFile), p.Logger),
+		DownloadPath: p.DownloadPath,
+		Headless:     p.Headless,
+		KeepOriginal: p.KeepOriginal,
+		Logger:       p.Logger,
+	}
+
 	// Start auto-update if enabled
 	if p.AutoUpdate {
 		p.startAutoUpdate()
 	}
 
+	// 定期実行スケジューラ（P2P・gRPCのどちらでも動かす）
+	p.startScheduler()
+	p.startRetention()
+
 	// Start P2P or gRPC server
 	if p.P2PMode {
 		p.runP2PClient()
@@ -185,6 +244,56 @@ func (p *Program) run() {
 	}
 }
 
+// startScheduler loads the schedule file and runs the scheduler until the service stops
+func (p *Program) startScheduler() {
+	path := p.SchedulesFile
+	if path == "" {
+		path = schedule.DefaultFile
+	}
+	if !filepath.IsAbs(path) {
+		exePath, _ := os.Executable()
+		path = filepath.Join(filepath.Dir(exePath), path)
+	}
+
+	sch, err := schedule.New(path, p.engine, p.Logger)
+	if err != nil {
+		// 壊れたファイルを上書きしないよう、スケジューラは起動しない
+		p.Logger.Printf("Scheduler disabled: %v", err)
+		return
+	}
+	p.scheduler = sch
+
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		defer func() {
+			if r := recover(); r != nil {
+				p.Logger.Printf("Scheduler panic recovered: %v", r)
+			}
+		}()
+		sch.Run(p.ctx)
+	}()
+}
+
+// startRetention cleans up old sessions periodically if a retention policy is set
+func (p *Program) startRetention() {
+	if !p.Retention.Enabled() {
+		return
+	}
+
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		defer func() {
+			if r := recover(); r != nil {
+				p.Logger.Printf("Retention panic recovered: %v", r)
+			}
+		}()
+		// 実行中のジョブのセッションフォルダは対象外
+		retention.Run(p.ctx, p.DownloadPath, p.Retention, retention.DefaultInterval, p.jobs.ActiveSessions, p.Logger)
+	}()
+}
+
 // startAutoUpdate initializes and starts the auto-updater
 func (p *Program) startAutoUpdate() {
 	cfg := updater.DefaultConfig(p.Version)
@@ -234,23 +343,22 @@ func (p *Program) startAutoUpdate() {
 
 // runGRPCServer starts the gRPC server
 func (p *Program) runGRPCServer() {
-	lis, err := net.Listen("tcp", ":"+p.GRPCPort)
+	opts, err := p.GRPCListen.ServerOptions(p.GRPCPort, p.Logger)
+	if err != nil {
+		p.Logger.Printf("Failed to configure gRPC server: %v", err)
+		return
+	}
+	lis, err := net.Listen("tcp", p.GRPCListen.Address(p.GRPCPort))
 	if err != nil {
 		p.Logger.Printf("Failed to listen: %v", err)
 		return
 	}
 
-	p.grpcServer = grpc.NewServer()
-	server := &GRPCServerImpl{
-		Logger:       p.Logger,
-		DownloadPath: p.DownloadPath,
-		Headless:     p.Headless,
-		Version:      p.Version,
-	}
-	pb.RegisterETCScraperServer(p.grpcServer, server)
+	p.grpcServer = grpc.NewServer(opts...)
+	pb.RegisterETCScraperServer(p.grpcServer, p.newGRPCServer())
 	reflection.Register(p.grpcServer)
 
-	p.Logger.Printf("gRPC server listening on port %s", p.GRPCPort)
+	p.Logger.Printf("gRPC server listening on %s", lis.Addr())
 	p.Logger.Printf("Download path: %s", p.DownloadPath)
 	p.Logger.Printf("Headless mode: %v", p.Headless)
 	p.Logger.Printf("Version: %s", p.Version)
@@ -266,6 +374,49 @@ func (p *Program) runGRPCServer() {
 	}
 }
 
+// openVault opens the account vault; relative paths are resolved against the executable directory
+func (p *Program) openVault() *vault.Vault {
+	if p.VaultPath == "" {
+		return nil
+	}
+	exePath, _ := os.Executable()
+	exeDir := filepath.Dir(exePath)
+	abs := func(path string) string {
+		if path == "" || filepath.IsAbs(path) {
+			return path
+		}
+		return filepath.Join(exeDir, path)
+	}
+
+	credsFile := p.P2PCredsFile
+	if credsFile == "" {
+		credsFile = "p2p_credentials.env"
+	}
+	config := vault.DefaultConfig(abs(p.VaultPath), abs(p.VaultKeyFile), abs(credsFile))
+	v, err := vault.Open(config)
+	if err != nil {
+		p.Logger.Printf("Account vault disabled: %v", err)
+		return nil
+	}
+	if v.Exists() {
+		p.Logger.Printf("Account vault: %s", config.Path)
+	}
+	return v
+}
+
+// newGRPCServer returns the gRPC API implementation shared by TCP and P2P
+func (p *Program) newGRPCServer() *server.GRPCServer {
+	return &server.GRPCServer{
+		Logger:       p.Logger,
+		DownloadPath: p.DownloadPath,
+		Version:      p.Version,
+		Jobs:         p.jobs,
+		Engine:       p.engine,
+		Scheduler:    p.scheduler,
+		History:      p.history,
+	}
+}
+
 // runP2PClient starts the P2P client for WebRTC communication
 func (p *Program) runP2PClient() {
 	// Loggerがnilの場合の安全対策
@@ -308,6 +459,15 @@ func (p *Program) runP2PClient() {
 		}
 	}
 
+	policy, err := p.loadP2PPolicy()
+	if err != nil {
+		// ポリシーが読めない場合は全員に公開せず、P2Pを開始しない
+		p.Logger.Printf("Failed to load P2P access policy: %v", err)
+		p.Logger.Println("P2P is disabled until the policy file is fixed and the service restarted")
+		<-p.ctx.Done()
+		return
+	}
+
 	// Create event handler
 	handler := &serviceP2PEventHandler{
 		program: p,
@@ -320,86 +480,36 @@ func (p *Program) runP2PClient() {
 		Capabilities: []string{"scrape", "etc"},
 		Logger:       p.Logger,
 		Handler:      handler,
-		OnDataChannelReady: func(dc *webrtc.DataChannel) {
+		MaxPeers:     p.P2PMaxPeers,
+		Channels: map[string]p2p.ChannelHandler{
+			p2p.FileChannelLabel: server.FileChannel(p.DownloadPath, policy, p.Logger),
+		},
+		OnDataChannelReady: func(dc *webrtc.DataChannel, user p2p.BrowserIdentity) {
 			p.Logger.Println("DataChannel ready, setting up gRPC-Web transport...")
-			p.setupGRPCWebTransport(dc)
+			p.setupGRPCWebTransport(dc, policy, user)
 		},
 	})
 
-	// Retry loop - never return unless context is cancelled
-	retryDelay := 5 * time.Second
-	maxRetryDelay := 60 * time.Second
-
-	for {
-		// Connect to signaling server in a goroutine
-		connectDone := make(chan error, 1)
-		go func() {
-			defer func() {
-				if r := recover(); r != nil {
-					p.Logger.Printf("P2P connect panic recovered: %v", r)
-					connectDone <- fmt.Errorf("panic: %v", r)
-				}
-			}()
-			err := p.p2pClient.Connect(p.ctx)
-			connectDone <- err
-		}()
+	states, unsubscribe := p.p2pClient.Subscribe()
+	defer unsubscribe()
+	go p.logP2PStates(states)
 
-		// Wait for connection with timeout
-		var connectErr error
-		select {
-		case connectErr = <-connectDone:
-			// Got result (success or error)
-		case <-time.After(30 * time.Second):
-			connectErr = fmt.Errorf("connection timeout (30s)")
-		case <-p.ctx.Done():
-			p.Logger.Println("P2P client shutting down...")
-			return
-		}
-
-		if connectErr != nil {
-			p.Logger.Printf("P2P connection failed: %v, retrying in %v...", connectErr, retryDelay)
-
-			// Wait before retry, but check for context cancellation
-			select {
-			case <-time.After(retryDelay):
-				// Increase retry delay (exponential backoff with cap)
-				retryDelay = retryDelay * 2
-				if retryDelay > maxRetryDelay {
-					retryDelay = maxRetryDelay
-				}
-				// Recreate P2P client for retry
-				p.p2pClient = p2p.NewClient(&p2p.ClientConfig{
-					SignalingURL: p.P2PURL,
-					APIKey:       apiKey,
-					AppName:      p.P2PAppName,
-					Capabilities: []string{"scrape", "etc"},
-					Logger:       p.Logger,
-					Handler:      handler,
-					OnDataChannelReady: func(dc *webrtc.DataChannel) {
-						p.Logger.Println("DataChannel ready, setting up gRPC-Web transport...")
-						p.setupGRPCWebTransport(dc)
-					},
-				})
-				continue
-			case <-p.ctx.Done():
-				p.Logger.Println("P2P client shutting down...")
-				return
-			}
-		}
+	// 切断時はクライアントが再接続する（サービス停止まで戻らない）
+	p.p2pClient.Run(p.ctx)
+	p.Logger.Println("P2P client shutting down...")
+}
 
-		// Connected successfully
-		appID := ""
-		if p.p2pClient != nil {
-			appID = p.p2pClient.GetAppID()
+// logP2PStates logs the signaling connection state changes of the P2P client
+func (p *Program) logP2PStates(states <-chan p2p.StateEvent) {
+	for ev := range states {
+		switch {
+		case ev.State == p2p.StateConnected:
+			p.Logger.Printf("P2P state: %s, appID: %s - waiting for browser connection", ev.State, ev.AppID)
+		case ev.Err != nil:
+			p.Logger.Printf("P2P state: %s (%v)", ev.State, ev.Err)
+		default:
+			p.Logger.Printf("P2P state: %s", ev.State)
 		}
-
-		p.Logger.Printf("Connected to signaling server, appID: %s", appID)
-		p.Logger.Println("Waiting for browser connection...")
-
-		// Wait for context cancellation (this keeps the service alive)
-		<-p.ctx.Done()
-		p.Logger.Println("P2P client shutting down...")
-		return
 	}
 }
 
@@ -408,12 +518,12 @@ type serviceP2PEventHandler struct {
 	program *Program
 }
 
-func (h *serviceP2PEventHandler) OnP2PConnected() {
-	h.program.Logger.Println("Browser connected via WebRTC!")
+func (h *serviceP2PEventHandler) OnP2PConnected(peerID string) {
+	h.program.Logger.Printf("Browser connected via WebRTC! (peer %s, %d active)", peerID, len(h.program.p2pClient.Peers()))
 }
 
-func (h *serviceP2PEventHandler) OnP2PDisconnected() {
-	h.program.Logger.Println("Browser disconnected")
+func (h *serviceP2PEventHandler) OnP2PDisconnected(peerID string) {
+	h.program.Logger.Printf("Browser disconnected (peer %s)", peerID)
 }
 
 func (h *serviceP2PEventHandler) OnP2PMessage(data []byte) {
@@ -424,201 +534,36 @@ func (h *serviceP2PEventHandler) OnP2PError(err error) {
 	h.program.Logger.Printf("P2P error: %v", err)
 }
 
-// setupGRPCWebTransport sets up gRPC-Web handlers on the DataChannel
-func (p *Program) setupGRPCWebTransport(dc *webrtc.DataChannel) {
+// loadP2PPolicy loads the access policy file; without one every browser user
+// is a viewer, and access.AllowAll gives every user full access
+func (p *Program) loadP2PPolicy() (*access.Policy, error) {
+	switch p.P2PPolicy {
+	case "":
+		p.Logger.Printf("No P2P access policy (-p2p-policy), browser users are limited to %s", access.RoleViewer)
+		return access.Default(), nil
+	case access.AllowAll:
+		p.Logger.Println("WARNING: P2P access control disabled (-p2p-policy=" + access.AllowAll + "), every browser user has full access")
+		return nil, nil
+	}
+	policy, err := access.Load(p.P2PPolicy)
+	if err != nil {
+		return nil, err
+	}
+	p.Logger.Printf("Loaded P2P access policy from %s (%d users, default role: %s)", p.P2PPolicy, len(policy.Users), policy.DefaultRole)
+	return policy, nil
+}
+
+// setupGRPCWebTransport serves the gRPC API on the DataChannel to the browser of user
+func (p *Program) setupGRPCWebTransport(dc *webrtc.DataChannel, policy *access.Policy, user p2p.BrowserIdentity) {
 	transport := grpcweb.NewTransport(dc, nil)
 
 	// Register Server Reflection
 	grpcweb.RegisterReflection(transport)
 
-	// Register scraper.ETCScraper/Health handler
-	transport.RegisterHandler("/scraper.ETCScraper/Health", grpcweb.MakeHandler(
-		func(data []byte) (json.RawMessage, error) {
-			return data, nil
-		},
-		func(resp map[string]interface{}) ([]byte, error) {
-			return json.Marshal(resp)
-		},
-		func(ctx context.Context, req json.RawMessage) (map[string]interface{}, error) {
-			return map[string]interface{}{
-				"status":  "ok",
-				"version": server.Version,
-			}, nil
-		},
-	))
-
-	// Register scraper.ETCScraper/ScrapeMultiple handler
-	transport.RegisterHandler("/scraper.ETCScraper/ScrapeMultiple", grpcweb.MakeHandler(
-		func(data []byte) (*p2pScrapeRequest, error) {
-			var req p2pScrapeRequest
-			if err := json.Unmarshal(data, &req); err != nil {
-				return nil, err
-			}
-			return &req, nil
-		},
-		func(resp *p2pScrapeResponse) ([]byte, error) {
-			return json.Marshal(resp)
-		},
-		func(ctx context.Context, req *p2pScrapeRequest) (*p2pScrapeResponse, error) {
-			p.Logger.Printf("Received ScrapeMultiple request with %d accounts", len(req.Accounts))
-
-			// Run scraping in background
-			go p.runScrapeJob(req.Accounts)
-
-			return &p2pScrapeResponse{
-				Message:      "Scraping started",
-				AccountCount: len(req.Accounts),
-			}, nil
-		},
-	))
-
-	// Register scraper.ETCScraper/GetDownloadedFiles handler
-	transport.RegisterHandler("/scraper.ETCScraper/GetDownloadedFiles", grpcweb.MakeHandler(
-		func(data []byte) (json.RawMessage, error) {
-			return data, nil
-		},
-		func(resp *p2pFilesResponse) ([]byte, error) {
-			return json.Marshal(resp)
-		},
-		func(ctx context.Context, req json.RawMessage) (*p2pFilesResponse, error) {
-			files, sessionFolder := p.getDownloadedFiles()
-			return &p2pFilesResponse{
-				SessionFolder: sessionFolder,
-				Files:         files,
-			}, nil
-		},
-	))
+	// TCPのgRPCサーバーと同じ実装をそのまま公開する
+	server.RegisterGRPCWeb(transport, p.newGRPCServer(), policy, user, p.Logger)
 
 	// Start the transport
 	transport.Start()
 	p.Logger.Println("gRPC-Web transport started")
 }
-
-// P2P request/response types
-type p2pScrapeRequest struct {
-	Accounts []struct {
-		UserID   string `json:"userId"`
-		Password string `json:"password"`
-	} `json:"accounts"`
-}
-
-type p2pScrapeResponse struct {
-	Message      string `json:"message"`
-	AccountCount int    `json:"accountCount"`
-}
-
-type p2pFilesResponse struct {
-	SessionFolder string                   `json:"sessionFolder"`
-	Files         []map[string]interface{} `json:"files"`
-}
-
-// runScrapeJob runs scraping in background
-func (p *Program) runScrapeJob(accounts []struct {
-	UserID   string `json:"userId"`
-	Password string `json:"password"`
-}) {
-	sessionFolder := filepath.Join(p.DownloadPath, time.Now().Format("20060102_150405"))
-	if err := os.MkdirAll(sessionFolder, 0755); err != nil {
-		p.Logger.Printf("Failed to create session folder: %v", err)
-		return
-	}
-
-	successCount := 0
-	for i, acc := range accounts {
-		p.Logger.Printf("Processing account %d/%d: %s", i+1, len(accounts), acc.UserID)
-
-		config := &scrapers.ScraperConfig{
-			UserID:       acc.UserID,
-			Password:     acc.Password,
-			DownloadPath: sessionFolder,
-			Headless:     p.Headless,
-			Timeout:      60 * time.Second,
-		}
-
-		scraper, err := scrapers.NewETCScraper(config, p.Logger)
-		if err != nil {
-			p.Logger.Printf("ERROR: %s: %v", acc.UserID, err)
-			continue
-		}
-
-		if err := scraper.Initialize(); err != nil {
-			scraper.Close()
-			p.Logger.Printf("ERROR: %s: %v", acc.UserID, err)
-			continue
-		}
-
-		if err := scraper.Login(); err != nil {
-			scraper.Close()
-			p.Logger.Printf("ERROR: %s: %v", acc.UserID, err)
-			continue
-		}
-
-		csvPath, err := scraper.Download()
-		scraper.Close()
-		if err != nil {
-			p.Logger.Printf("ERROR: %s: %v", acc.UserID, err)
-			continue
-		}
-
-		// Rename file with account name
-		newPath := filepath.Join(sessionFolder, acc.UserID+"_"+filepath.Base(csvPath))
-		if csvPath != newPath {
-			if err := os.Rename(csvPath, newPath); err != nil {
-				p.Logger.Printf("Warning: could not rename file: %v", err)
-			}
-		}
-
-		successCount++
-
-		if i < len(accounts)-1 {
-			time.Sleep(2 * time.Second)
-		}
-	}
-
-	p.Logger.Printf("Scraping completed: %d/%d accounts succeeded", successCount, len(accounts))
-}
-
-// getDownloadedFiles returns files from the latest session folder
-func (p *Program) getDownloadedFiles() ([]map[string]interface{}, string) {
-	entries, err := os.ReadDir(p.DownloadPath)
-	if err != nil {
-		return nil, ""
-	}
-
-	var latestFolder string
-	for i := len(entries) - 1; i >= 0; i-- {
-		if entries[i].IsDir() {
-			latestFolder = entries[i].Name()
-			break
-		}
-	}
-
-	if latestFolder == "" {
-		return nil, ""
-	}
-
-	sessionPath := filepath.Join(p.DownloadPath, latestFolder)
-	files, err := os.ReadDir(sessionPath)
-	if err != nil {
-		return nil, latestFolder
-	}
-
-	var result []map[string]interface{}
-	for _, f := range files {
-		if f.IsDir() {
-			continue
-		}
-		filePath := filepath.Join(sessionPath, f.Name())
-		content, err := os.ReadFile(filePath)
-		if err != nil {
-			continue
-		}
-		result = append(result, map[string]interface{}{
-			"filename": f.Name(),
-			"content":  string(content),
-			"size":     len(content),
-		})
-	}
-
-	return result, latestFolder
-}
@@ -0,0 +1,355 @@
+// Package vault stores ETC account credentials in an encrypted file, so that
+// clients can start scrapes by account ID without sending passwords.
+//
+// The file holds the accounts as JSON sealed with AES-256-GCM. The key is
+// either derived from a passphrase (PBKDF2-SHA256, salt and iteration count
+// stored in the file) or read from a key file of 32 random bytes created with
+// 0600 permissions.
+package vault
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// PassphraseEnv is the environment variable holding the vault passphrase
+const PassphraseEnv = "ETC_VAULT_PASSPHRASE"
+
+// DefaultKeyFile is the name of the key file, next to p2p_credentials.env
+const DefaultKeyFile = "vault.key"
+
+const (
+	fileVersion = 1
+	keySize     = 32 // AES-256
+	saltSize    = 16
+	// pbkdf2Iterations follows the OWASP recommendation for PBKDF2-HMAC-SHA256
+	pbkdf2Iterations = 600000
+
+	kdfPBKDF2  = "pbkdf2-sha256"
+	kdfKeyFile = "keyfile"
+)
+
+var (
+	// ErrNotFound is returned for an unknown account ID
+	ErrNotFound = errors.New("account not found in vault")
+	// ErrDecrypt is returned when the vault cannot be decrypted with the key
+	ErrDecrypt = errors.New("failed to decrypt vault (wrong passphrase or key file)")
+)
+
+// Entry is an account stored in the vault
+type Entry struct {
+	ID          string    `json:"id"`                    // account ID used as account_ref
+	ScraperType string    `json:"scraperType,omitempty"` // default "etc"
+	UserID      string    `json:"userId"`
+	Password    string    `json:"password"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+// Config locates a vault and its key
+type Config struct {
+	Path       string // vault file
+	KeyFile    string // key file, used when Passphrase is empty
+	Passphrase string // derive the key from a passphrase instead of the key file
+}
+
+// file is the on-disk format of the vault
+type file struct {
+	Version    int    `json:"version"`
+	KDF        string `json:"kdf"` // kdfPBKDF2 or kdfKeyFile
+	Salt       []byte `json:"salt,omitempty"`
+	Iterations int    `json:"iterations,omitempty"`
+	Nonce      []byte `json:"nonce"`
+	Data       []byte `json:"data"` // sealed JSON []Entry
+}
+
+// Vault is an encrypted account store. Every read loads the file again, so
+// accounts added by the CLI are seen by a running server.
+type Vault struct {
+	config Config
+
+	mu      sync.Mutex
+	key     []byte // derived key of keySalt
+	keySalt []byte
+}
+
+// Open returns the vault of config. The file is created by the first Add;
+// with a key file and no passphrase, the key file is created then as well.
+func Open(config Config) (*Vault, error) {
+	if config.Path == "" {
+		return nil, errors.New("vault path is empty")
+	}
+	if config.Passphrase == "" && config.KeyFile == "" {
+		return nil, fmt.Errorf("vault needs a passphrase (%s) or a key file", PassphraseEnv)
+	}
+	return &Vault{config: config}, nil
+}
+
+// Exists reports whether the vault file exists
+func (v *Vault) Exists() bool {
+	_, err := os.Stat(v.config.Path)
+	return err == nil
+}
+
+// Get returns the account with the given ID
+func (v *Vault) Get(id string) (Entry, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	entries, _, err := v.load()
+	if err != nil {
+		return Entry{}, err
+	}
+	for _, e := range entries {
+		if e.ID == id {
+			return e, nil
+		}
+	}
+	return Entry{}, fmt.Errorf("%w: %s", ErrNotFound, id)
+}
+
+// List returns all accounts, sorted by ID
+func (v *Vault) List() ([]Entry, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	entries, _, err := v.load()
+	return entries, err
+}
+
+// Add stores an account, replacing the account with the same ID
+func (v *Vault) Add(e Entry) error {
+	e.ID = strings.TrimSpace(e.ID)
+	if e.ID == "" || e.UserID == "" || e.Password == "" {
+		return errors.New("account ID, user ID and password are required")
+	}
+	e.UpdatedAt = time.Now()
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	entries, f, err := v.load()
+	if err != nil {
+		return err
+	}
+	replaced := false
+	for i := range entries {
+		if entries[i].ID == e.ID {
+			entries[i] = e
+			replaced = true
+		}
+	}
+	if !replaced {
+		entries = append(entries, e)
+	}
+	return v.save(entries, f)
+}
+
+// Remove deletes the account with the given ID
+func (v *Vault) Remove(id string) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	entries, f, err := v.load()
+	if err != nil {
+		return err
+	}
+	for i := range entries {
+		if entries[i].ID == id {
+			return v.save(append(entries[:i], entries[i+1:]...), f)
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrNotFound, id)
+}
+
+// load reads and decrypts the vault; a missing file is an empty vault
+func (v *Vault) load() ([]Entry, *file, error) {
+	data, err := os.ReadFile(v.config.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read vault: %w", err)
+	}
+
+	var f file
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, nil, fmt.Errorf("invalid vault file %s: %w", v.config.Path, err)
+	}
+	if f.Version != fileVersion {
+		return nil, nil, fmt.Errorf("unsupported vault version %d", f.Version)
+	}
+
+	key, err := v.fileKey(&f, false)
+	if err != nil {
+		return nil, nil, err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	plain, err := gcm.Open(nil, f.Nonce, f.Data, []byte(f.KDF))
+	if err != nil {
+		return nil, nil, ErrDecrypt
+	}
+
+	var entries []Entry
+	if err := json.Unmarshal(plain, &entries); err != nil {
+		return nil, nil, fmt.Errorf("invalid vault contents: %w", err)
+	}
+	return entries, &f, nil
+}
+
+// save encrypts the entries with a new nonce and replaces the file atomically.
+// prev is the loaded file (nil for a new vault); its key settings are kept.
+func (v *Vault) save(entries []Entry, prev *file) error {
+	sort.Slice(entries, func(i, k int) bool { return entries[i].ID < entries[k].ID })
+	plain, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+
+	f := file{Version: fileVersion}
+	if prev != nil {
+		f.KDF, f.Salt, f.Iterations = prev.KDF, prev.Salt, prev.Iterations
+	} else if v.config.Passphrase != "" {
+		f.KDF, f.Iterations = kdfPBKDF2, pbkdf2Iterations
+		f.Salt = make([]byte, saltSize)
+		if _, err := rand.Read(f.Salt); err != nil {
+			return err
+		}
+	} else {
+		f.KDF = kdfKeyFile
+	}
+
+	key, err := v.fileKey(&f, prev == nil)
+	if err != nil {
+		return err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return err
+	}
+	f.Nonce = make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(f.Nonce); err != nil {
+		return err
+	}
+	f.Data = gcm.Seal(nil, f.Nonce, plain, []byte(f.KDF))
+
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFile(v.config.Path, data)
+}
+
+// fileKey returns the key of a vault file. create allows creating the key
+// file for a new vault.
+func (v *Vault) fileKey(f *file, create bool) ([]byte, error) {
+	switch f.KDF {
+	case kdfPBKDF2:
+		if v.config.Passphrase == "" {
+			return nil, fmt.Errorf("vault is protected by a passphrase, set %s", PassphraseEnv)
+		}
+		// 導出は重いのでソルトが同じ間は再利用する
+		if v.key != nil && string(v.keySalt) == string(f.Salt) {
+			return v.key, nil
+		}
+		key, err := pbkdf2.Key(sha256.New, v.config.Passphrase, f.Salt, f.Iterations, keySize)
+		if err != nil {
+			return nil, err
+		}
+		v.key, v.keySalt = key, f.Salt
+		return key, nil
+	case kdfKeyFile:
+		return readKeyFile(v.config.KeyFile, create)
+	default:
+		return nil, fmt.Errorf("unsupported vault key derivation %q", f.KDF)
+	}
+}
+
+// readKeyFile reads the key file, creating it with a random key if allowed
+func readKeyFile(path string, create bool) ([]byte, error) {
+	if path == "" {
+		return nil, errors.New("vault uses a key file but none is configured")
+	}
+	key, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) && create {
+		key = make([]byte, keySize)
+		if _, err := rand.Read(key); err != nil {
+			return nil, err
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(path, key, 0600); err != nil {
+			return nil, fmt.Errorf("failed to create vault key file: %w", err)
+		}
+		return key, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read vault key file: %w", err)
+	}
+	if len(key) != keySize {
+		return nil, fmt.Errorf("vault key file %s must contain %d bytes", path, keySize)
+	}
+	return key, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// writeFile writes data to a temporary file (created with 0600 permissions)
+// and renames it over path
+func writeFile(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".vault-*")
+	if err != nil {
+		return fmt.Errorf("failed to write vault: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write vault: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write vault: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write vault: %w", err)
+	}
+	return nil
+}
+
+// DefaultConfig returns the config of the vault file at path. The passphrase
+// comes from PassphraseEnv if set; otherwise keyFile is used, or DefaultKeyFile
+// in the directory of credsFile (p2p_credentials.env) if keyFile is empty.
+func DefaultConfig(path, keyFile, credsFile string) Config {
+	if keyFile == "" {
+		keyFile = filepath.Join(filepath.Dir(credsFile), DefaultKeyFile)
+	}
+	return Config{
+		Path:       path,
+		KeyFile:    keyFile,
+		Passphrase: os.Getenv(PassphraseEnv),
+	}
+}
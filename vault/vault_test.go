@@ -0,0 +1,79 @@
+package vault
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestKeyFileVault(t *testing.T) {
+	dir := t.TempDir()
+	config := Config{Path: filepath.Join(dir, "accounts.vault"), KeyFile: filepath.Join(dir, DefaultKeyFile)}
+	v, err := Open(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := v.Add(Entry{ID: "corp1", UserID: "user1", Password: "secret-pass"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := v.Add(Entry{ID: "corp2", UserID: "user2", Password: "pass2"}); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(config.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "secret-pass") || strings.Contains(string(data), "user1") {
+		t.Error("vault file contains plaintext credentials")
+	}
+	if key := mustRead(t, config.KeyFile); len(key) != keySize {
+		t.Errorf("key file has %d bytes, want %d", len(key), keySize)
+	}
+
+	// 別インスタンス（CLIとサーバー）からも読める
+	other, _ := Open(config)
+	e, err := other.Get("corp1")
+	if err != nil || e.UserID != "user1" || e.Password != "secret-pass" {
+		t.Errorf("Get(corp1) = %+v, %v", e, err)
+	}
+
+	if err := v.Remove("corp1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := other.Get("corp1"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get after Remove: %v, want ErrNotFound", err)
+	}
+	if list, err := other.List(); err != nil || len(list) != 1 || list[0].ID != "corp2" {
+		t.Errorf("List = %+v, %v", list, err)
+	}
+}
+
+func TestPassphraseVault(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "accounts.vault")
+	v, _ := Open(Config{Path: path, Passphrase: "correct horse"})
+	if err := v.Add(Entry{ID: "corp1", UserID: "user1", Password: "pass1"}); err != nil {
+		t.Fatal(err)
+	}
+
+	wrong, _ := Open(Config{Path: path, Passphrase: "wrong"})
+	if _, err := wrong.Get("corp1"); !errors.Is(err, ErrDecrypt) {
+		t.Errorf("Get with wrong passphrase: %v, want ErrDecrypt", err)
+	}
+	noPass, _ := Open(Config{Path: path, KeyFile: filepath.Join(t.TempDir(), DefaultKeyFile)})
+	if _, err := noPass.Get("corp1"); err == nil {
+		t.Error("Get without passphrase succeeded")
+	}
+}
+
+func mustRead(t *testing.T, path string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
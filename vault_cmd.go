@@ -0,0 +1,102 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/scrape-vm/scrapers"
+	"github.com/scrape-vm/vault"
+	"golang.org/x/term"
+)
+
+// accountPasswordEnv holds the password for -vault-cmd=add (read from stdin if unset)
+const accountPasswordEnv = "ETC_ACCOUNT_PASSWORD"
+
+// runVaultCommand runs a vault command: add, remove or list
+func runVaultCommand(config vault.Config, cmd, accountID, userID, scraperType string) {
+	v, err := vault.Open(config)
+	if err != nil {
+		log.Fatalf("Failed to open vault: %v", err)
+	}
+
+	switch cmd {
+	case "add":
+		if accountID == "" || userID == "" {
+			log.Fatal("Usage: etc-scraper -vault-cmd=add -account-id=<id> -account-user=<ETC user ID> [-scraper=etc]")
+		}
+		password := os.Getenv(accountPasswordEnv)
+		if password == "" {
+			password = readPassword()
+		}
+		if err := v.Add(vault.Entry{ID: accountID, ScraperType: scraperType, UserID: userID, Password: password}); err != nil {
+			log.Fatalf("Failed to add account: %v", err)
+		}
+		fmt.Printf("Account %s saved to %s\n", accountID, config.Path)
+
+	case "remove":
+		if accountID == "" {
+			log.Fatal("Usage: etc-scraper -vault-cmd=remove -account-id=<id>")
+		}
+		if err := v.Remove(accountID); err != nil {
+			log.Fatalf("Failed to remove account: %v", err)
+		}
+		fmt.Printf("Account %s removed from %s\n", accountID, config.Path)
+
+	case "list":
+		entries, err := v.List()
+		if err != nil {
+			log.Fatalf("Failed to read vault: %v", err)
+		}
+		if len(entries) == 0 {
+			fmt.Printf("No accounts in %s\n", config.Path)
+			return
+		}
+		// パスワードは表示しない
+		fmt.Printf("%-20s %-8s %-24s %s\n", "ID", "SCRAPER", "USER", "UPDATED")
+		for _, e := range entries {
+			scraper := e.ScraperType
+			if scraper == "" {
+				scraper = scrapers.DefaultType
+			}
+			fmt.Printf("%-20s %-8s %-24s %s\n", e.ID, scraper, e.UserID, e.UpdatedAt.Format("2006-01-02 15:04"))
+		}
+
+	default:
+		log.Fatalf("Unknown vault command %q (add|remove|list)", cmd)
+	}
+}
+
+// readPassword reads the account password from stdin, without echo when stdin
+// is a terminal; piped input is read as a line
+func readPassword() string {
+	fmt.Fprintf(os.Stderr, "Password (or set %s): ", accountPasswordEnv)
+	if fd := int(os.Stdin.Fd()); term.IsTerminal(fd) {
+		password, err := term.ReadPassword(fd)
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			log.Fatalf("Failed to read password: %v", err)
+		}
+		return string(password)
+	}
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		log.Fatalf("Failed to read password: %v", err)
+	}
+	return strings.TrimRight(line, "\r\n")
+}
+
+// openVault opens the account vault used to resolve account_ref in requests
+func openVault(config vault.Config, logger *log.Logger) *vault.Vault {
+	v, err := vault.Open(config)
+	if err != nil {
+		logger.Printf("Account vault disabled: %v", err)
+		return nil
+	}
+	if v.Exists() {
+		logger.Printf("Account vault: %s", config.Path)
+	}
+	return v
+}